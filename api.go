@@ -4,21 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strconv"
 	"time"
 )
 
-const baseURL = "https://api.todoist.com/api/v1"
+// baseURL is the Todoist API root; tests point it at a local server.
+var baseURL = "https://api.todoist.com/api/v1"
 
 // Client is the Todoist API client
 type Client struct {
-	token string
-	http  *http.Client
+	token   string
+	http    *http.Client
+	limiter *rateLimiter
 }
 
 type directoryUser struct {
@@ -33,9 +35,61 @@ func NewClient(token string) *Client {
 		http: &http.Client{
 			Timeout: 15 * time.Second,
 		},
+		limiter: apiLimiter,
 	}
 }
 
+// APIError is a non-2xx response from the Todoist API.
+type APIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // the server's Retry-After hint, 0 if none
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
+}
+
+// Retriable reports whether the request may succeed if sent again unchanged.
+func (e *APIError) Retriable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusRequestTimeout || e.StatusCode >= 500
+}
+
+// newAPIError builds the error for a failed response and tells the limiter
+// about any rate limiting.
+func newAPIError(limiter *rateLimiter, resp *http.Response, body []byte) *APIError {
+	err := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	limiter.throttled(err)
+	return err
+}
+
+// apiErrorStatus returns the HTTP status of an API error, or 0 for any other error.
+func apiErrorStatus(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// retryHint returns how long the server asked us to wait before retrying err.
+func retryHint(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// Budget reports the remaining request budget shared by the API clients.
+func (c *Client) Budget() rateBudget {
+	return c.limiter.budget()
+}
+
 func (c *Client) doRequest(ctx context.Context, method, path string, body any) ([]byte, error) {
 	var bodyReader io.Reader
 	if body != nil {
@@ -56,6 +110,9 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body any) (
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
 	resp, err := c.http.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -68,7 +125,7 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body any) (
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(c.limiter, resp, respBody)
 	}
 
 	return respBody, nil
@@ -106,73 +163,8 @@ func (c *Client) GetProjects(ctx context.Context) ([]Project, error) {
 	return all, nil
 }
 
-// --- Sections ---
-
-func (c *Client) GetSections(ctx context.Context, projectID string) ([]Section, error) {
-	var all []Section
-	var cursor *string
-
-	for {
-		path := "/sections?limit=200&project_id=" + url.QueryEscape(projectID)
-		if cursor != nil {
-			path += "&cursor=" + url.QueryEscape(*cursor)
-		}
-
-		data, err := c.doRequest(ctx, "GET", path, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		var resp PaginatedResponse[Section]
-		if err := json.Unmarshal(data, &resp); err != nil {
-			return nil, fmt.Errorf("decode sections: %w", err)
-		}
-
-		all = append(all, resp.Results...)
-		if resp.NextCursor == nil || *resp.NextCursor == "" {
-			break
-		}
-		cursor = resp.NextCursor
-	}
-
-	return all, nil
-}
-
 // --- Tasks ---
 
-func (c *Client) GetTasks(ctx context.Context, projectID string) ([]Task, error) {
-	var all []Task
-	var cursor *string
-
-	for {
-		path := "/tasks?limit=200"
-		if projectID != "" {
-			path += "&project_id=" + url.QueryEscape(projectID)
-		}
-		if cursor != nil {
-			path += "&cursor=" + url.QueryEscape(*cursor)
-		}
-
-		data, err := c.doRequest(ctx, "GET", path, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		var resp PaginatedResponse[Task]
-		if err := json.Unmarshal(data, &resp); err != nil {
-			return nil, fmt.Errorf("decode tasks: %w", err)
-		}
-
-		all = append(all, resp.Results...)
-		if resp.NextCursor == nil || *resp.NextCursor == "" {
-			break
-		}
-		cursor = resp.NextCursor
-	}
-
-	return all, nil
-}
-
 type createTaskRequest struct {
 	Content      string   `json:"content"`
 	Description  string   `json:"description,omitempty"`
@@ -184,19 +176,6 @@ type createTaskRequest struct {
 	Labels       []string `json:"labels,omitempty"`
 }
 
-func (c *Client) CreateTask(ctx context.Context, req createTaskRequest) (Task, error) {
-	data, err := c.doRequest(ctx, "POST", "/tasks", req)
-	if err != nil {
-		return Task{}, err
-	}
-
-	var task Task
-	if err := json.Unmarshal(data, &task); err != nil {
-		return Task{}, fmt.Errorf("decode task: %w", err)
-	}
-	return task, nil
-}
-
 type updateTaskRequest struct {
 	Content       *string  `json:"content,omitempty"`
 	Description   *string  `json:"description,omitempty"`
@@ -292,79 +271,22 @@ func (r *updateTaskRequest) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (c *Client) UpdateTask(ctx context.Context, taskID string, req updateTaskRequest) (Task, error) {
-	data, err := c.doRequest(ctx, "POST", "/tasks/"+taskID, req)
-	if err != nil {
-		return Task{}, err
-	}
-
-	var task Task
-	if err := json.Unmarshal(data, &task); err != nil {
-		return Task{}, fmt.Errorf("decode task: %w", err)
-	}
-	return task, nil
-}
-
-func (c *Client) GetTask(ctx context.Context, taskID string) (Task, error) {
-	data, err := c.doRequest(ctx, "GET", "/tasks/"+taskID, nil)
-	if err != nil {
-		return Task{}, err
-	}
-	var task Task
-	if err := json.Unmarshal(data, &task); err != nil {
-		return Task{}, fmt.Errorf("decode task: %w", err)
-	}
-	return task, nil
-}
-
-func isNotFoundError(err error) bool {
-	code, ok := apiErrorStatusCode(err)
-	return ok && code == http.StatusNotFound
-}
-
-var apiErrorPattern = regexp.MustCompile(`API error ([0-9]{3}):`)
-
-func apiErrorStatusCode(err error) (int, bool) {
-	if err == nil {
-		return 0, false
-	}
-	matches := apiErrorPattern.FindStringSubmatch(err.Error())
-	if len(matches) < 2 {
-		return 0, false
-	}
-	code, convErr := strconv.Atoi(matches[1])
-	if convErr != nil {
-		return 0, false
-	}
-	return code, true
-}
-
 func isRetriableMutationError(err error) bool {
 	if err == nil {
 		return false
 	}
-	if code, ok := apiErrorStatusCode(err); ok {
-		return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= 500
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retriable()
+	}
+	var cmdErr *SyncCommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Retriable()
 	}
 	// Non-HTTP failures (timeouts/network) are generally retriable.
 	return true
 }
 
-func (c *Client) CloseTask(ctx context.Context, taskID string) error {
-	_, err := c.doRequest(ctx, "POST", "/tasks/"+taskID+"/close", nil)
-	return err
-}
-
-func (c *Client) ReopenTask(ctx context.Context, taskID string) error {
-	_, err := c.doRequest(ctx, "POST", "/tasks/"+taskID+"/reopen", nil)
-	return err
-}
-
-func (c *Client) DeleteTask(ctx context.Context, taskID string) error {
-	_, err := c.doRequest(ctx, "DELETE", "/tasks/"+taskID, nil)
-	return err
-}
-
 type quickAddRequest struct {
 	Text string `json:"text"`
 }
@@ -378,6 +300,7 @@ func (c *Client) QuickAdd(ctx context.Context, text string) (Task, error) {
 	if err := json.Unmarshal(data, &task); err != nil {
 		return Task{}, fmt.Errorf("decode quick add task: %w", err)
 	}
+	task.Priority = priorityFromSync(task.Priority)
 	return task, nil
 }
 
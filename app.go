@@ -35,10 +35,16 @@ type App struct {
 	queue     QueueView
 	completed CompletedView
 	triage    TriageView
+	detail    DetailView
+	labels    LabelsView
+	filters   FiltersView
 
-	// Loading state
-	loading bool
-	spinner spinner.Model
+	// Sync state
+	syncing    bool
+	lastSynced *time.Time
+	syncErr    string
+	sched      syncScheduler
+	spinner    spinner.Model
 
 	// Toast notification
 	toast      string
@@ -48,18 +54,14 @@ type App struct {
 	mode   appMode
 	search SearchView
 
+	// Mode to return to when the task detail pane closes
+	detailReturn appMode
+
 	// Track last selected project to detect changes
 	lastProjectID string
 
-	// Background refresh
-	bgRefreshStarted bool
-
-	// Cache/source feedback for header UX.
-	cacheHintActive   bool
-	cacheHintResource string
-	cacheHintLastSync *time.Time
-	cacheHintSyncing  bool
-	cacheHintError    string
+	// Control API server, or nil when not listening
+	control *controlServer
 }
 
 func NewApp(repo *Repository) App {
@@ -68,18 +70,23 @@ func NewApp(repo *Repository) App {
 	s.Style = lipgloss.NewStyle().Foreground(colorBlue)
 
 	return App{
-		repo:      repo,
-		focus:     focusSidebar,
-		projects:  NewProjectsView(repo),
-		tasks:     NewTasksView(repo),
-		today:     NewTodayView(repo),
-		queue:     NewQueueView(repo),
-		completed: NewCompletedView(repo),
-		triage:    NewTriageView(repo),
-		search:    NewSearchView(repo),
-		loading:   true,
-		spinner:   s,
-		mode:      appModeMain,
+		repo:       repo,
+		focus:      focusSidebar,
+		projects:   NewProjectsView(repo),
+		tasks:      NewTasksView(repo),
+		today:      NewTodayView(repo),
+		queue:      NewQueueView(repo),
+		completed:  NewCompletedView(repo),
+		triage:     NewTriageView(repo),
+		detail:     NewDetailView(repo),
+		labels:     NewLabelsView(repo),
+		filters:    NewFiltersView(repo),
+		search:     NewSearchView(repo),
+		syncing:    true,
+		lastSynced: repo.LastSynced(),
+		sched:      newSyncScheduler(syncIntervalFromEnv()),
+		spinner:    s,
+		mode:       appModeMain,
 	}
 }
 
@@ -87,8 +94,9 @@ func (a App) Init() tea.Cmd {
 	return tea.Batch(
 		a.spinner.Tick,
 		a.projects.Init(),
+		a.repo.PerformSync(),
 		a.repo.RefreshAssigneeDirectory(),
-		a.repo.FlushNext(),
+		a.repo.FlushPending(),
 	)
 }
 
@@ -108,9 +116,14 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.MouseMsg:
 		m := tea.MouseEvent(msg)
 
-		// Filter out motion and release events
+		// Filter out motion and release events, except to finish a task drag
 		if m.Action == tea.MouseActionMotion || m.Action == tea.MouseActionRelease {
-			return a, nil
+			if a.mode != appModeMain || !a.tasks.IsDragging() {
+				return a, nil
+			}
+			var cmd tea.Cmd
+			a.tasks, cmd = a.tasks.HandleMouse(m, 1)
+			return a, cmd
 		}
 
 		switch a.mode {
@@ -122,7 +135,15 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			var cmd tea.Cmd
 			a.completed, cmd = a.completed.Update(msg)
 			return a, cmd
-		case appModeHelp, appModeSearch, appModeTriage:
+		case appModeLabels:
+			var cmd tea.Cmd
+			a.labels, cmd = a.labels.Update(msg)
+			return a, cmd
+		case appModeFilters:
+			var cmd tea.Cmd
+			a.filters, cmd = a.filters.Update(msg)
+			return a, cmd
+		case appModeHelp, appModeSearch, appModeTriage, appModeDetail:
 			return a, nil
 		}
 
@@ -143,22 +164,10 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.tasks.SetFocused(false)
 			a.today.SetFocused(false)
 
-			prevToday := a.projects.IsTodaySelected()
-			prev := a.projects.SelectedProjectID()
+			prev := a.projects.SelectionKey()
 			a.projects, _ = a.projects.HandleMouse(m, 1)
-
-			// Switched to Today
-			if a.projects.IsTodaySelected() && !prevToday {
-				a.lastProjectID = ""
-				a.today.Refresh()
-				return a, nil
-			}
-			// Switched to a project
-			if p := a.projects.SelectedProject(); p != nil && p.ID != prev {
-				a.lastProjectID = p.ID
-				var taskCmd tea.Cmd
-				a.tasks, taskCmd = a.tasks.LoadProject(p.ID, p.Name)
-				return a, taskCmd
+			if a.projects.SelectionKey() != prev {
+				return a, a.loadSelection()
 			}
 			return a, nil
 		}
@@ -173,7 +182,9 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			a.tasks.SetFocused(true)
 			a.today.SetFocused(false)
-			a.tasks, _ = a.tasks.HandleMouse(m, 1)
+			var cmd tea.Cmd
+			a.tasks, cmd = a.tasks.HandleMouse(m, 1)
+			return a, cmd
 		}
 		return a, nil
 
@@ -205,12 +216,12 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, cmd
 
 		case appModeQueue:
-			if action == ActionOpenActions {
+			if action == ActionOpenActions && !a.queue.handlesInput() {
 				a.mode = appModeSearch
 				a.search.Open(ctx)
 				return a, textinput.Blink
 			}
-			if action == ActionCancel {
+			if action == ActionCancel && !a.queue.handlesInput() {
 				a.mode = appModeMain
 				return a, nil
 			}
@@ -232,6 +243,61 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.completed, cmd = a.completed.Update(msg)
 			return a, cmd
 
+		case appModeLabels:
+			if !a.labels.handlesInput() {
+				if action == ActionOpenActions {
+					a.mode = appModeSearch
+					a.search.Open(ctx)
+					return a, textinput.Blink
+				}
+				if action == ActionCancel {
+					a.mode = appModeMain
+					return a, nil
+				}
+			}
+			var cmd tea.Cmd
+			a.labels, cmd = a.labels.Update(msg)
+			return a, cmd
+
+		case appModeFilters:
+			if !a.filters.handlesInput() {
+				if action == ActionOpenActions {
+					a.mode = appModeSearch
+					a.search.Open(ctx)
+					return a, textinput.Blink
+				}
+				if action == ActionCancel {
+					a.mode = appModeMain
+					return a, nil
+				}
+				if action == ActionConfirm {
+					a.mode = appModeMain
+					if f := a.filters.selectedFilter(); f != nil && a.projects.selectByKey("filter:"+f.ID) {
+						return a, a.loadSelection()
+					}
+					return a, nil
+				}
+			}
+			var cmd tea.Cmd
+			a.filters, cmd = a.filters.Update(msg)
+			return a, cmd
+
+		case appModeDetail:
+			if !a.detail.handlesInput() {
+				if action == ActionOpenActions {
+					a.mode = appModeSearch
+					a.search.Open(ctx)
+					return a, textinput.Blink
+				}
+				if action == ActionCancel {
+					a.mode = a.detailReturn
+					return a, nil
+				}
+			}
+			var cmd tea.Cmd
+			a.detail, cmd = a.detail.Update(msg)
+			return a, cmd
+
 		case appModeTriage:
 			if !a.triage.handlesInput() {
 				if action == ActionOpenActions {
@@ -239,14 +305,14 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					a.search.Open(ctx)
 					return a, textinput.Blink
 				}
-				if action == ActionCancel {
+				if action == ActionCancel && !a.triage.HasMarks() {
 					a.mode = appModeMain
 					if a.isTodayActive() {
 						a.today.Refresh()
-					} else if a.tasks.CurrentProjectID() != "" {
-						cmds = append(cmds, a.repo.RefreshTasks(a.tasks.CurrentProjectID()))
+					} else {
+						a.tasks.Reload()
 					}
-					return a, tea.Batch(cmds...)
+					return a, nil
 				}
 			}
 			var cmd tea.Cmd
@@ -282,6 +348,10 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.mode = appModeQueue
 			a.queue.Refresh()
 			return a, nil
+		case ActionUndo:
+			return a, a.repo.Undo()
+		case ActionRedo:
+			return a, a.repo.Redo()
 		case ActionOpenSearch:
 			a.mode = appModeSearch
 			a.search.Open(ctx)
@@ -294,6 +364,16 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.completed.SetSize(a.height)
 			a.completed.Refresh()
 			return a, nil
+		case ActionOpenLabels:
+			a.mode = appModeLabels
+			a.labels.SetSize(a.height)
+			a.labels.Refresh()
+			return a, nil
+		case ActionOpenFilters:
+			a.mode = appModeFilters
+			a.filters.SetSize(a.width, a.height)
+			a.filters.Refresh()
+			return a, nil
 		case ActionOpenTriage:
 			a.mode = appModeTriage
 			a.triage.SetSize(a.width, a.height)
@@ -330,18 +410,10 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.tasks, cmd = a.tasks.OpenQuickAdd(defaultProject)
 			return a, cmd
 		case ActionRefresh:
-			// Refresh — force API fetch
-			a.loading = true
-			if a.isTodayActive() {
-				return a, tea.Batch(
-					a.repo.RefreshProjects(),
-					a.repo.RefreshAssigneeDirectory(),
-				)
-			}
+			// Refresh — one incremental sync round-trip
+			a.syncing = true
 			return a, tea.Batch(
-				a.repo.RefreshProjects(),
-				a.repo.RefreshTasks(a.tasks.CurrentProjectID()),
-				a.repo.RefreshSections(a.tasks.CurrentProjectID()),
+				a.repo.PerformSync(),
 				a.repo.RefreshAssigneeDirectory(),
 			)
 		case ActionFocusTasks:
@@ -360,148 +432,166 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-	case cachedProjectsMsg:
-		a.loading = true
-		a.setCacheHint("projects", msg.lastSynced, true, nil)
+	case projectsMsg:
 		var cmd tea.Cmd
 		a.projects, cmd = a.projects.Update(msg)
 		cmds = append(cmds, cmd)
+		if msg.err == nil && len(msg.projects) > 0 {
+			cmds = append(cmds, a.activateSelection())
+		}
+		return a, tea.Batch(cmds...)
 
-		// On first load, cursor=0 means Today is selected
-		if a.lastProjectID == "" && len(msg.projects) > 0 {
-			if a.isTodayActive() {
-				a.today.Refresh()
-				a.today.SetFocused(false)
-				a.projects.SetFocused(true)
-			} else if p := a.projects.SelectedProject(); p != nil {
-				a.lastProjectID = p.ID
-				var taskCmd tea.Cmd
-				a.tasks, taskCmd = a.tasks.LoadProject(p.ID, p.Name)
-				cmds = append(cmds, taskCmd)
-				a.tasks.SetFocused(false)
-				a.projects.SetFocused(true)
+	case syncDoneMsg:
+		a.syncing = false
+		a.lastSynced = msg.lastSynced
+		if msg.err != nil {
+			a.syncErr = msg.err.Error()
+			retry, news := a.sched.failed(msg.err)
+			if !news {
+				return a, retry
 			}
+			return a, tea.Batch(retry, func() tea.Msg {
+				return toastMsg{text: "Sync failed: " + msg.err.Error(), isError: true}
+			})
+		}
+		a.syncErr = ""
+		next, reconnected := a.sched.succeeded()
+		cmds = append(cmds, next)
+		if reconnected {
+			cmds = append(cmds, func() tea.Msg { return toastMsg{text: "Back online"} })
+		}
+		// Send what queued up while offline or failed with a retriable error.
+		if a.repo.PendingCount() > 0 {
+			cmds = append(cmds, a.repo.FlushPending())
 		}
-		// Start background cache warming
-		if !a.bgRefreshStarted {
-			a.bgRefreshStarted = true
-			cmds = append(cmds, a.repo.FindStaleProjects())
+		a.control.publish("synced")
+		a.projects.Reload()
+		cmds = append(cmds, a.activateSelection())
+		if a.isTodayActive() {
+			a.today.Refresh()
+		} else {
+			a.tasks.Reload()
+		}
+		if a.mode == appModeCompleted {
+			a.completed.Refresh()
+		}
+		if a.mode == appModeDetail {
+			a.detail.Reload()
+		}
+		if a.mode == appModeLabels {
+			a.labels.Refresh()
+		}
+		if a.mode == appModeFilters {
+			a.filters.Refresh()
+		}
+		if msg.fullSync {
+			cmds = append(cmds, a.repo.RefreshAssigneeDirectory())
 		}
 		return a, tea.Batch(cmds...)
 
-	case projectsMsg:
-		a.loading = false
-		if msg.err != nil {
-			a.setCacheHint("projects", msg.lastSynced, false, msg.err)
-		} else if msg.fromCache {
-			a.setCacheHint("projects", msg.lastSynced, false, nil)
-		} else if a.cacheHintResource == "projects" {
-			a.clearCacheHint()
+	case flushDoneMsg:
+		switch {
+		case msg.err != nil && a.sched.state == connOffline:
+			// Already offline: the scheduled retry flushes once it reconnects.
+		case msg.err != nil:
+			retry, news := a.sched.failed(msg.err)
+			cmds = append(cmds, retry)
+			if news {
+				cmds = append(cmds, func() tea.Msg {
+					return toastMsg{text: "Sync failed: " + msg.err.Error(), isError: true}
+				})
+			}
+		case msg.flushed > 0 && a.sched.state == connOffline:
+			next, _ := a.sched.succeeded()
+			cmds = append(cmds, next, func() tea.Msg { return toastMsg{text: "Back online"} })
 		}
-		var cmd tea.Cmd
-		a.projects, cmd = a.projects.Update(msg)
-		cmds = append(cmds, cmd)
-
-		// On first load or refresh, populate the active view
-		if msg.err == nil && len(msg.projects) > 0 && a.lastProjectID == "" {
+		if msg.conflicts > 0 {
+			cmds = append(cmds, func() tea.Msg {
+				return toastMsg{text: "Sync conflict — press Q to review", isError: true}
+			})
+		}
+		// Reconcile views to server IDs and any optimistic rollback applied in the repository.
+		if msg.flushed > 0 || msg.conflicts > 0 {
+			a.control.publish("queue_changed")
 			if a.isTodayActive() {
 				a.today.Refresh()
-				a.today.SetFocused(false)
-				a.projects.SetFocused(true)
-			} else if p := a.projects.SelectedProject(); p != nil {
-				a.lastProjectID = p.ID
-				var taskCmd tea.Cmd
-				a.tasks, taskCmd = a.tasks.LoadProject(p.ID, p.Name)
-				cmds = append(cmds, taskCmd)
-				a.tasks.SetFocused(false)
-				a.projects.SetFocused(true)
+			} else {
+				a.tasks.Reload()
 			}
+			if a.mode == appModeCompleted {
+				a.completed.Refresh()
+			}
+			if a.mode == appModeQueue {
+				a.queue.Refresh()
+			}
+			if a.mode == appModeDetail {
+				a.detail.Reload()
+			}
+			if a.mode == appModeLabels {
+				a.labels.Refresh()
+			}
+			if a.mode == appModeFilters {
+				a.filters.Refresh()
+			}
+			a.projects.Reload()
 		}
-		// Refresh today if active (cache may have been updated by background refresh)
-		if msg.err == nil && a.isTodayActive() {
-			a.today.Refresh()
-		}
-		// Enqueue sync for stale projects. Always run after API refresh
-		// to catch newly discovered projects whose tasks/sections have
-		// never been synced (no sync_meta entry = stale).
-		if msg.err == nil {
-			a.bgRefreshStarted = true
-			cmds = append(cmds, a.repo.FindStaleProjects())
+		// Mutations queued while the batch was in flight go out in the next one.
+		if msg.err == nil && msg.flushed > 0 && a.repo.PendingCount() > 0 {
+			cmds = append(cmds, a.repo.FlushPending())
 		}
 		return a, tea.Batch(cmds...)
 
-	case cachedTasksMsg:
-		if msg.projectID == a.tasks.CurrentProjectID() {
-			a.loading = true
-			a.setCacheHint("tasks", msg.lastSynced, true, nil)
+	case syncTickMsg:
+		// A sync already in flight reschedules when it finishes.
+		if msg.gen != a.sched.gen || a.syncing {
+			return a, nil
 		}
-		var cmd tea.Cmd
-		a.tasks, cmd = a.tasks.Update(msg)
-		return a, cmd
+		a.syncing = true
+		return a, a.repo.PerformSync()
 
-	case cachedSectionsMsg:
-		if msg.projectID == a.tasks.CurrentProjectID() {
-			a.loading = true
-			if !a.cacheHintActive || a.cacheHintResource != "tasks" {
-				a.setCacheHint("sections", msg.lastSynced, true, nil)
-			}
-		}
-		var cmd tea.Cmd
-		a.tasks, cmd = a.tasks.Update(msg)
-		return a, cmd
+	case rpcCallMsg:
+		return a, msg.run(a.repo)
 
-	case tasksMsg:
-		if msg.projectID == a.tasks.CurrentProjectID() {
-			a.loading = false
-			if msg.err != nil {
-				a.setCacheHint("tasks", msg.lastSynced, false, msg.err)
-			} else if msg.fromCache {
-				a.setCacheHint("tasks", msg.lastSynced, false, nil)
-			} else if a.cacheHintResource == "tasks" || a.cacheHintResource == "sections" {
-				a.clearCacheHint()
+	case conflictResolvedMsg:
+		if msg.err != nil {
+			return a, func() tea.Msg {
+				return toastMsg{text: "Failed to resolve: " + msg.err.Error(), isError: true}
 			}
 		}
-		var cmd tea.Cmd
-		a.tasks, cmd = a.tasks.Update(msg)
-		if msg.err == nil {
-			return a, tea.Batch(cmd, a.repo.RefreshAssigneeDirectory())
+		if a.isTodayActive() {
+			a.today.Refresh()
+		} else {
+			a.tasks.Reload()
 		}
-		return a, cmd
-
-	case sectionsMsg:
-		if msg.projectID == a.tasks.CurrentProjectID() {
-			if msg.err != nil {
-				a.setCacheHint("sections", msg.lastSynced, false, msg.err)
-			} else if msg.fromCache {
-				a.setCacheHint("sections", msg.lastSynced, false, nil)
-			} else if a.cacheHintResource == "sections" {
-				a.clearCacheHint()
-			}
+		if a.mode == appModeQueue {
+			a.queue.Refresh()
 		}
-		var cmd tea.Cmd
-		a.tasks, cmd = a.tasks.Update(msg)
-		return a, cmd
+		return a, a.repo.FlushPending()
 
-	case mutationFlushedMsg:
+	case undoneMsg:
 		if msg.err != nil {
-			cmds = append(cmds, func() tea.Msg {
-				return toastMsg{text: "Sync failed: " + msg.err.Error(), isError: true}
-			})
-		}
-		// Replace optimistic placeholders and ensure lists reconcile to server IDs.
-		if msg.err == nil {
-			switch msg.mutation.Action {
-			case MutationCreate, MutationQuickAdd:
-				if pid := a.tasks.CurrentProjectID(); pid != "" {
-					cmds = append(cmds, a.repo.RefreshTasks(pid))
-				}
-				if a.isTodayActive() {
-					a.today.Refresh()
-				}
+			return a, func() tea.Msg {
+				return toastMsg{text: msg.err.Error(), isError: true}
 			}
 		}
-		// Chain: flush next mutation
-		cmds = append(cmds, a.repo.FlushNext())
+		verb := "Undid"
+		if msg.redo {
+			verb = "Redid"
+		}
+		cmds = append(cmds, func() tea.Msg {
+			return toastMsg{text: verb + ": " + msg.desc}
+		})
+		a.control.publish("tasks_changed")
+		a.projects.Reload()
+		if a.isTodayActive() {
+			a.today.Refresh()
+		} else {
+			a.tasks.Reload()
+		}
+		if a.mode == appModeQueue {
+			a.queue.Refresh()
+		}
+		cmds = append(cmds, a.repo.FlushPending())
 		return a, tea.Batch(cmds...)
 
 	case taskReopenedMsg:
@@ -519,7 +609,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if a.mode == appModeCompleted {
 			a.completed.Refresh()
 		}
-		cmds = append(cmds, a.repo.FlushNext())
+		cmds = append(cmds, a.repo.FlushPending())
 		return a, tea.Batch(cmds...)
 
 	case projectCreatedMsg:
@@ -527,6 +617,20 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.projects, cmd = a.projects.Update(msg)
 		return a, cmd
 
+	case sectionUpdatedMsg:
+		var cmd tea.Cmd
+		a.tasks, cmd = a.tasks.Update(msg)
+		return a, tea.Batch(cmd, a.repo.FlushPending())
+
+	case projectMovedMsg:
+		if msg.err != nil {
+			return a, func() tea.Msg {
+				return toastMsg{text: "Failed to move project: " + msg.err.Error(), isError: true}
+			}
+		}
+		a.projects.Reload()
+		return a, a.repo.FlushPending()
+
 	case projectArchivedMsg:
 		var cmd tea.Cmd
 		a.projects, cmd = a.projects.Update(msg)
@@ -553,63 +657,63 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.completed.Refresh()
 		}
 		return a, tea.Batch(
-			a.repo.RefreshProjects(),
+			a.repo.PerformSync(),
 			func() tea.Msg { return toastMsg{text: "List unarchived", isError: false} },
 		)
 
-	case mutationConflictMsg:
-		cmds = append(cmds, func() tea.Msg {
-			return toastMsg{text: "Sync conflict — press Q to review", isError: true}
-		})
-		// Refresh visible views to reflect any optimistic rollback applied in the repository.
+	case openMovePickerMsg:
+		a.mode = appModeSearch
+		a.search.OpenMove(msg.tasks)
+		return a, textinput.Blink
+
+	case openTaskDetailMsg:
+		a.detailReturn = a.mode
+		a.mode = appModeDetail
+		a.detail.SetSize(a.width, a.height)
+		return a, a.detail.Open(msg.task)
+
+	case labelCreatedMsg, labelUpdatedMsg, labelDeletedMsg:
+		var cmd tea.Cmd
+		a.labels, cmd = a.labels.Update(msg)
+		cmds = append(cmds, cmd, a.repo.FlushPending())
+		a.projects.Reload()
+		// Renames and deletes rewrite labels on cached tasks.
 		if a.isTodayActive() {
 			a.today.Refresh()
-		} else if pid := a.tasks.CurrentProjectID(); pid != "" {
-			var cmd tea.Cmd
-			a.tasks, cmd = a.tasks.LoadProject(pid, a.tasks.CurrentProjectName())
-			cmds = append(cmds, cmd)
-		}
-		if a.mode == appModeCompleted {
-			a.completed.Refresh()
+		} else if label := a.tasks.CurrentLabelName(); label != "" && "@"+label != a.projects.SelectionKey() {
+			cmds = append(cmds, a.loadSelection())
+		} else {
+			a.tasks.Reload()
 		}
 		return a, tea.Batch(cmds...)
 
-	case mutationEnqueuedMsg:
-		// Sync indicator will update on next render
-		return a, nil
-
-	case flushNextMsg:
-		return a, a.repo.FlushNext()
-
-	case backgroundRefreshMsg:
-		if len(msg.staleProjects) == 0 {
-			return a, nil
-		}
-		// Split into 2 independent chains for concurrency=2
-		var chain1, chain2 []string
-		for i, pid := range msg.staleProjects {
-			if i%2 == 0 {
-				chain1 = append(chain1, pid)
+	case filterCreatedMsg, filterUpdatedMsg, filterDeletedMsg:
+		var cmd tea.Cmd
+		a.filters, cmd = a.filters.Update(msg)
+		cmds = append(cmds, cmd, a.repo.FlushPending())
+		a.projects.Reload()
+		// Re-evaluate an open filter view so an edited name or query takes effect.
+		if id := a.tasks.CurrentFilterID(); id != "" {
+			if f := a.projects.SelectedFilter(); f != nil && f.ID == id {
+				a.tasks.RefreshFilter(*f)
 			} else {
-				chain2 = append(chain2, pid)
+				cmds = append(cmds, a.loadSelection())
 			}
 		}
-		cmds = append(cmds, a.repo.BackgroundRefreshProject(chain1[0], chain1[1:]))
-		if len(chain2) > 0 {
-			cmds = append(cmds, a.repo.BackgroundRefreshProject(chain2[0], chain2[1:]))
-		}
 		return a, tea.Batch(cmds...)
 
-	case backgroundRefreshDoneMsg:
-		if len(msg.remaining) > 0 {
-			return a, a.repo.BackgroundRefreshProject(msg.remaining[0], msg.remaining[1:])
-		}
-		// Background warming done — refresh Today if active
-		if a.isTodayActive() {
-			a.today.Refresh()
-		}
+	case commentAddedMsg, commentUpdatedMsg, commentDeletedMsg:
+		var cmd tea.Cmd
+		a.detail, cmd = a.detail.Update(msg)
+		return a, tea.Batch(cmd, a.repo.FlushPending())
+
+	case mutationEnqueuedMsg:
+		// Sync indicator will update on next render
 		return a, nil
 
+	case flushNextMsg:
+		return a, a.repo.FlushPending()
+
 	case assigneeDirectoryMsg:
 		// No explicit state needed; views read names from the cache.
 		if msg.err != nil {
@@ -716,21 +820,13 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Delegate to focused view
 	switch a.focus {
 	case focusSidebar:
-		prevToday := a.projects.IsTodaySelected()
-		prev := a.projects.SelectedProjectID()
+		prev := a.projects.SelectionKey()
 		var cmd tea.Cmd
 		a.projects, cmd = a.projects.Update(msg)
 		cmds = append(cmds, cmd)
-		// Switched to Today
-		if a.projects.IsTodaySelected() && !prevToday {
-			a.lastProjectID = ""
-			a.today.Refresh()
-		} else if p := a.projects.SelectedProject(); p != nil && p.ID != prev {
-			// Auto-load project when sidebar cursor changes
-			a.lastProjectID = p.ID
-			var taskCmd tea.Cmd
-			a.tasks, taskCmd = a.tasks.LoadProject(p.ID, p.Name)
-			cmds = append(cmds, taskCmd)
+		// Auto-load the view when the sidebar cursor changes
+		if a.projects.SelectionKey() != prev {
+			cmds = append(cmds, a.loadSelection())
 		}
 	case focusTasks:
 		if a.isTodayActive() {
@@ -744,11 +840,18 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Keep the detail pane's task and text inputs current.
+	if a.mode == appModeDetail {
+		var cmd tea.Cmd
+		a.detail, cmd = a.detail.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	// Pass mutation results to the active content view even when sidebar is focused
 	switch msg.(type) {
-	case taskClosedMsg, taskDeletedMsg, taskCreatedMsg, taskUpdatedMsg, quickAddMsg:
-		// Route to triage if active
-		if a.mode == appModeTriage {
+	case taskClosedMsg, taskDeletedMsg, taskCreatedMsg, taskUpdatedMsg, taskMovedMsg, quickAddMsg, bulkEditMsg:
+		// Route to triage if active (or underneath the detail pane)
+		if a.mode == appModeTriage || (a.mode == appModeDetail && a.detailReturn == appModeTriage) {
 			var cmd tea.Cmd
 			a.triage, cmd = a.triage.Update(msg)
 			cmds = append(cmds, cmd)
@@ -767,7 +870,8 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		// Trigger flush after optimistic mutations
-		cmds = append(cmds, a.repo.FlushNext())
+		cmds = append(cmds, a.repo.FlushPending())
+		a.control.publish("tasks_changed")
 	}
 
 	// Pass project messages to sidebar when tasks are focused
@@ -802,6 +906,12 @@ func (a App) viewForMode(mode appMode) string {
 		return a.completed.View(a.width, a.height)
 	case appModeTriage:
 		return a.triage.View(a.width, a.height)
+	case appModeDetail:
+		return a.detail.View(a.width, a.height)
+	case appModeLabels:
+		return a.labels.View(a.width, a.height)
+	case appModeFilters:
+		return a.filters.View(a.width, a.height)
 	default:
 		return a.renderMainView()
 	}
@@ -891,24 +1001,42 @@ func (a App) localSearchPanel() string {
 	return a.tasks.SearchPanel()
 }
 
-func (a *App) setCacheHint(resource string, lastSynced *time.Time, syncing bool, err error) {
-	a.cacheHintActive = true
-	a.cacheHintResource = resource
-	a.cacheHintLastSync = lastSynced
-	a.cacheHintSyncing = syncing
-	if err != nil {
-		a.cacheHintError = err.Error()
-	} else {
-		a.cacheHintError = ""
+// activateSelection populates the content pane on first load, once projects
+// are known and nothing has been shown yet.
+func (a *App) activateSelection() tea.Cmd {
+	if a.lastProjectID != "" {
+		return nil
+	}
+	if a.isTodayActive() {
+		a.today.Refresh()
+		return nil
+	}
+	if a.projects.SelectedProject() == nil && a.projects.SelectedLabel() == nil && a.projects.SelectedFilter() == nil {
+		return nil
 	}
+	cmd := a.loadSelection()
+	a.tasks.SetFocused(false)
+	a.projects.SetFocused(true)
+	return cmd
 }
 
-func (a *App) clearCacheHint() {
-	a.cacheHintActive = false
-	a.cacheHintResource = ""
-	a.cacheHintLastSync = nil
-	a.cacheHintSyncing = false
-	a.cacheHintError = ""
+// loadSelection shows whatever the sidebar cursor is on: Today, a project, a
+// saved filter or a label view.
+func (a *App) loadSelection() tea.Cmd {
+	a.lastProjectID = a.projects.SelectionKey()
+	var cmd tea.Cmd
+	switch {
+	case a.projects.IsTodaySelected():
+		a.today.Refresh()
+	case a.projects.SelectedFilter() != nil:
+		a.tasks, cmd = a.tasks.LoadFilter(*a.projects.SelectedFilter())
+	case a.projects.SelectedLabel() != nil:
+		a.tasks, cmd = a.tasks.LoadLabel(a.projects.SelectedLabel().Name)
+	case a.projects.SelectedProject() != nil:
+		p := a.projects.SelectedProject()
+		a.tasks, cmd = a.tasks.LoadProject(p.ID, p.Name)
+	}
+	return cmd
 }
 
 func (a App) currentInputContext() InputContext {
@@ -918,7 +1046,7 @@ func (a App) currentInputContext() InputContext {
 	case appModeSearch:
 		return ContextSearchOverlay
 	case appModeQueue:
-		return ContextQueueOverlay
+		return a.queue.inputContext()
 	case appModeCompleted:
 		return ContextCompletedOverlay
 	case appModeTriage:
@@ -926,6 +1054,18 @@ func (a App) currentInputContext() InputContext {
 			return ContextTriageDialog
 		}
 		return ContextTriageOverlay
+	case appModeLabels:
+		return a.labels.inputContext()
+	case appModeFilters:
+		return a.filters.inputContext()
+	case appModeDetail:
+		if a.detail.confirming() {
+			return ContextMainSidebarDialog
+		}
+		if a.detail.handlesInput() {
+			return ContextDetailInput
+		}
+		return ContextDetailOverlay
 	}
 
 	// Main mode
@@ -968,10 +1108,16 @@ func (a App) renderHeader() string {
 		}
 		syncIndicator += syncConflictStyle.Render(fmt.Sprintf("⚠ %d conflicts", conflicts))
 	}
+	if budget := a.repo.RateBudget().String(); budget != "" {
+		if syncIndicator != "" {
+			syncIndicator += " "
+		}
+		syncIndicator += syncPendingStyle.Render(budget)
+	}
 
 	var right string
-	if a.loading {
-		right = a.spinner.View() + " Loading..."
+	if a.syncing {
+		right = a.spinner.View() + " Syncing..."
 	} else if a.toast != "" {
 		if a.toastError {
 			right = toastErrorStyle.Render("✗ " + a.toast)
@@ -980,29 +1126,26 @@ func (a App) renderHeader() string {
 		}
 	}
 
-	if a.cacheHintActive {
-		status := "cache"
-		if a.cacheHintLastSync != nil {
-			age := time.Since(*a.cacheHintLastSync)
-			if age < time.Minute {
-				status = "cached just now"
-			} else {
-				status = "cached " + formatAgeSince(*a.cacheHintLastSync) + " ago"
-			}
-		}
-		if a.cacheHintSyncing {
-			status += " · syncing"
+	// Last sync age, or a failure marker while the cache is serving stale data.
+	var status string
+	switch {
+	case a.sched.state == connOffline:
+		status = a.sched.status()
+	case a.syncErr != "":
+		status = "sync failed"
+	case a.lastSynced != nil && !a.syncing:
+		if time.Since(*a.lastSynced) < time.Minute {
+			status = "synced just now"
 		} else {
-			status += " · ready"
-		}
-		if a.cacheHintError != "" {
-			status += " · sync failed"
+			status = "synced " + formatAgeSince(*a.lastSynced) + " ago"
 		}
-		cacheLine := lipgloss.NewStyle().Foreground(colorYellow).Render(status)
+	}
+	if status != "" {
+		statusLine := lipgloss.NewStyle().Foreground(colorYellow).Render(status)
 		if right != "" {
-			right = cacheLine + "  " + right
+			right = statusLine + "  " + right
 		} else {
-			right = cacheLine
+			right = statusLine
 		}
 	}
 
@@ -9,6 +9,9 @@ const (
 	appModeQueue
 	appModeCompleted
 	appModeTriage
+	appModeDetail
+	appModeLabels
+	appModeFilters
 )
 
 func (m appMode) isOverlay() bool {
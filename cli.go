@@ -0,0 +1,525 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// cliCommands are the headless subcommands. They share the cache and mutation
+// queue with the TUI: edits are queued, then flushed if the network allows.
+var cliCommands = map[string]func(c *cli, args []string) error{
+	"add":    (*cli).add,
+	"ls":     (*cli).ls,
+	"done":   (*cli).done,
+	"today":  (*cli).today,
+	"sync":   (*cli).sync,
+	"queue":  (*cli).queue,
+	"export": (*cli).export,
+}
+
+const cliUsage = `Usage: todo [command] [--json]
+
+With no command, todo opens the interactive app. With --listen it also
+serves a JSON-RPC control API on control.sock in the cache directory.
+
+Commands:
+  add "text" [--project P]      quick-add a task (natural language)
+  ls [--project P] [--filter Q] list tasks, optionally by project or filter query
+  done <id>...                  complete tasks
+  today                         list overdue, due today and up next
+  sync                          send queued changes and pull remote ones
+  queue                         list queued and conflicted changes
+  export [--format json|ndjson] [--only KINDS]
+                                export the cache; KINDS is a comma list of
+                                projects,sections,labels,tasks,mutations
+
+--json output uses the export schema (schema_version in every record).
+`
+
+// errUsage reports a malformed command line; the usage text has been printed.
+var errUsage = errors.New("usage")
+
+// isCLICommand reports whether arg names a headless subcommand.
+func isCLICommand(arg string) bool {
+	_, ok := cliCommands[arg]
+	return ok || arg == "help" || arg == "-h" || arg == "--help"
+}
+
+type cli struct {
+	repo     *Repository
+	out, err io.Writer
+	json     bool
+}
+
+// runCLI runs a headless subcommand and returns the process exit code.
+func runCLI(args []string, token string) int {
+	run, ok := cliCommands[args[0]]
+	if !ok {
+		fmt.Fprint(os.Stdout, cliUsage)
+		return 0
+	}
+	if token == "" {
+		fmt.Fprintln(os.Stderr, "Error: no API token: set TODOIST_API_TOKEN or run todo --setup")
+		return 1
+	}
+
+	path, err := cacheDBPath()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	store, err := NewStore(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: open cache:", err)
+		return 1
+	}
+	defer store.Close()
+
+	c := &cli{
+		repo: NewRepository(NewClient(token), NewSyncClient(token), store),
+		out:  os.Stdout,
+		err:  os.Stderr,
+	}
+	if err := run(c, args[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// flags returns a flag set for a subcommand with the shared --json flag.
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.err)
+	fs.BoolVar(&c.json, "json", false, "print JSON")
+	fs.Usage = func() { fmt.Fprint(c.err, cliUsage) }
+	return fs
+}
+
+// parse parses args, allowing flags before and after positional arguments.
+func (c *cli) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func (c *cli) printJSON(v any) error {
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// ensureCache pulls a first full sync when the cache has never synced, so a
+// fresh machine lists tasks instead of nothing. Offline, it warns and carries
+// on with whatever the cache holds, such as tasks queued before any sync.
+func (c *cli) ensureCache() error {
+	if c.repo.LastSynced() != nil {
+		return nil
+	}
+	msg, ok := c.repo.PerformSync()().(syncDoneMsg)
+	switch {
+	case !ok || msg.err == nil:
+	case isNetworkError(msg.err):
+		fmt.Fprintf(c.err, "Warning: offline and the cache has never synced: %v\n", msg.err)
+	default:
+		return fmt.Errorf("sync: %w", msg.err)
+	}
+	return nil
+}
+
+// flush sends queued mutations until the queue drains or a flush makes no
+// progress, and reports what was sent.
+func (c *cli) flush() flushDoneMsg {
+	var total flushDoneMsg
+	for {
+		msg, ok := c.repo.FlushPending()().(flushDoneMsg)
+		if !ok {
+			return total
+		}
+		total.flushed += msg.flushed
+		total.conflicts += msg.conflicts
+		for id, serverID := range msg.created {
+			total.addCreated(id, serverID)
+		}
+		if msg.err != nil {
+			total.err = msg.err
+			return total
+		}
+		if msg.flushed == 0 {
+			return total
+		}
+	}
+}
+
+// reportFlush tells the user about changes that stayed queued.
+func (c *cli) reportFlush(done flushDoneMsg) {
+	if done.err != nil {
+		fmt.Fprintf(c.err, "Offline: %d change(s) queued, run `todo sync` to retry (%v)\n", c.repo.PendingCount(), done.err)
+	}
+	if done.conflicts > 0 {
+		fmt.Fprintf(c.err, "%d change(s) conflicted, see `todo queue`\n", done.conflicts)
+	}
+}
+
+// findProject resolves a project by ID or case-insensitive name.
+func findProject(repo *Repository, ref string) (Project, error) {
+	for _, p := range repo.GetCachedProjects() {
+		if p.ID == ref || strings.EqualFold(p.Name, ref) {
+			return p, nil
+		}
+	}
+	return Project{}, fmt.Errorf("no project %q", ref)
+}
+
+func (c *cli) add(args []string) error {
+	fs := c.flags("add")
+	project := fs.String("project", "", "project name or ID")
+	rest, err := c.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	text := strings.TrimSpace(strings.Join(rest, " "))
+	if text == "" {
+		fs.Usage()
+		return errUsage
+	}
+	if err := c.ensureCache(); err != nil {
+		return err
+	}
+	// Without a project the server files the task; the inbox only holds the
+	// optimistic copy, so a queued add still shows up and has an ID.
+	projectID := inboxProjectID(c.repo)
+	if *project != "" {
+		p, err := findProject(c.repo, *project)
+		if err != nil {
+			return err
+		}
+		projectID = p.ID
+	}
+
+	var added Mutation
+	msg, _ := c.repo.quickAdd(text, projectID, func(m Mutation) {
+		added = m
+		c.repo.history.record(m)
+	}).(quickAddMsg)
+	if msg.err != nil {
+		return msg.err
+	}
+	if added.ID == 0 {
+		return fmt.Errorf("add %q: could not queue the task", text)
+	}
+	done := c.flush()
+
+	m := findMutation(c.repo, added.ID)
+	if m != nil && m.Status == MutationConflicted {
+		c.reportFlush(done)
+		return fmt.Errorf("add %q: %s", text, conflictSummary(*m))
+	}
+	// Report the server's copy, or the optimistic one under its temp ID
+	// while the add is still queued.
+	task := Task{Content: text, ProjectID: projectID}
+	if m == nil {
+		task.ID = done.created[added.ID]
+		if t := findTask(c.repo, task.ID); t != nil {
+			task = *t
+		}
+	} else if msg.task != nil {
+		task = *msg.task
+	}
+
+	if c.json {
+		rec := newExporter(c.repo).task(task)
+		if m != nil {
+			rec.SyncStatus = string(m.Status)
+		}
+		return c.printJSON(rec)
+	}
+	if m == nil {
+		fmt.Fprintf(c.out, "Added %q\n", task.Content)
+	}
+	c.reportFlush(done)
+	return nil
+}
+
+// inboxProjectID returns the cached inbox's ID, or "" before the first sync.
+func inboxProjectID(repo *Repository) string {
+	for _, p := range repo.GetCachedProjects() {
+		if p.InboxProject {
+			return p.ID
+		}
+	}
+	return ""
+}
+
+// findMutation looks a queued mutation up by ID; nil once it has flushed.
+func findMutation(repo *Repository, id int64) *Mutation {
+	for _, m := range repo.GetAllMutations() {
+		if m.ID == id {
+			return &m
+		}
+	}
+	return nil
+}
+
+// conflictSummary describes why a mutation conflicted.
+func conflictSummary(m Mutation) string {
+	var reasons []string
+	for _, rec := range m.Conflicts() {
+		reasons = append(reasons, rec.String())
+	}
+	if len(reasons) == 0 {
+		return "conflicted"
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func (c *cli) ls(args []string) error {
+	fs := c.flags("ls")
+	project := fs.String("project", "", "project name or ID")
+	filter := fs.String("filter", "", "filter query, e.g. \"today & p1\"")
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if err := c.ensureCache(); err != nil {
+		return err
+	}
+	tasks, err := queryTasks(c.repo, *project, *filter)
+	if err != nil {
+		return err
+	}
+	return c.printTasks(tasks)
+}
+
+// queryTasks lists cached tasks, narrowed to a project (by ID or name) and a
+// filter query when either is set.
+func queryTasks(repo *Repository, project, filter string) ([]Task, error) {
+	var projectID string
+	if project != "" {
+		p, err := findProject(repo, project)
+		if err != nil {
+			return nil, err
+		}
+		projectID = p.ID
+	}
+	switch {
+	case filter != "":
+		all, err := repo.FilterTasks(filter)
+		if err != nil {
+			return nil, err
+		}
+		var tasks []Task
+		for _, t := range all {
+			if projectID == "" || t.ProjectID == projectID {
+				tasks = append(tasks, t)
+			}
+		}
+		return tasks, nil
+	case projectID != "":
+		return repo.GetCachedTasks(projectID), nil
+	}
+	return repo.GetAllCachedTasks(), nil
+}
+
+func (c *cli) done(args []string) error {
+	fs := c.flags("done")
+	ids, err := c.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	var closed []Task
+	for _, id := range ids {
+		task := findTask(c.repo, id)
+		if task == nil {
+			return fmt.Errorf("no task %q in the cache", id)
+		}
+		if msg, ok := c.repo.CloseTask(task.ID)().(taskClosedMsg); ok && msg.err != nil {
+			return msg.err
+		}
+		closed = append(closed, *task)
+	}
+	done := c.flush()
+	if c.json {
+		return c.printJSON(map[string]any{"completed": newExporter(c.repo).tasks(closed), "queued": done.err != nil})
+	}
+	for _, t := range closed {
+		fmt.Fprintf(c.out, "Completed %q\n", t.Content)
+	}
+	c.reportFlush(done)
+	return nil
+}
+
+// findTask looks a task up by ID in the cache.
+func findTask(repo *Repository, id string) *Task {
+	for _, t := range repo.GetAllCachedTasks() {
+		if t.ID == id {
+			return &t
+		}
+	}
+	return nil
+}
+
+func (c *cli) today(args []string) error {
+	fs := c.flags("today")
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if err := c.ensureCache(); err != nil {
+		return err
+	}
+	overdue, today, upcoming := todayBuckets(c.repo.GetAllCachedTasks())
+	if c.json {
+		e := newExporter(c.repo)
+		return c.printJSON(map[string][]taskRecord{"overdue": e.tasks(overdue), "today": e.tasks(today), "up_next": e.tasks(upcoming)})
+	}
+	projects := c.repo.GetProjectNameMap()
+	for _, group := range []struct {
+		title string
+		tasks []Task
+	}{{"Overdue", overdue}, {"Today", today}, {"Up Next", upcoming}} {
+		if len(group.tasks) == 0 {
+			continue
+		}
+		fmt.Fprintf(c.out, "%s\n", group.title)
+		for _, t := range group.tasks {
+			fmt.Fprintln(c.out, "  "+cliTaskLine(t, projects))
+		}
+	}
+	return nil
+}
+
+func (c *cli) sync(args []string) error {
+	fs := c.flags("sync")
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+	done := c.flush()
+	msg, _ := c.repo.PerformSync()().(syncDoneMsg)
+	if c.json {
+		out := map[string]any{
+			"flushed":   done.flushed,
+			"conflicts": done.conflicts,
+			"pending":   c.repo.PendingCount(),
+		}
+		if msg.err != nil {
+			out["error"] = msg.err.Error()
+		}
+		if err := c.printJSON(out); err != nil {
+			return err
+		}
+		if msg.err != nil {
+			return fmt.Errorf("sync: %w", msg.err)
+		}
+		return nil
+	}
+	if msg.err != nil {
+		c.reportFlush(done)
+		return fmt.Errorf("sync: %w", msg.err)
+	}
+	fmt.Fprintf(c.out, "Synced; sent %d change(s)\n", done.flushed)
+	c.reportFlush(done)
+	return nil
+}
+
+func (c *cli) queue(args []string) error {
+	fs := c.flags("queue")
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+	muts := c.repo.GetAllMutations()
+	if c.json {
+		return c.printJSON(mutationRecords(muts))
+	}
+	if len(muts) == 0 {
+		fmt.Fprintln(c.out, "No pending mutations")
+		return nil
+	}
+	for _, m := range muts {
+		fmt.Fprintf(c.out, "%d  %s\n", m.ID, renderMutationLine(m))
+		for _, rec := range m.Conflicts() {
+			fmt.Fprintf(c.out, "      %s\n", rec)
+		}
+	}
+	return nil
+}
+
+func (c *cli) export(args []string) error {
+	fs := c.flags("export")
+	format := fs.String("format", "json", "json or ndjson")
+	only := fs.String("only", "projects,sections,labels,tasks,mutations", "comma-separated kinds to export")
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+	kinds := make(map[string]bool)
+	for _, k := range strings.Split(*only, ",") {
+		switch k = strings.TrimSpace(k); k {
+		case "projects", "sections", "labels", "tasks", "mutations":
+			kinds[k] = true
+		default:
+			return fmt.Errorf("unknown kind %q", k)
+		}
+	}
+	if err := c.ensureCache(); err != nil {
+		return err
+	}
+	doc := buildExport(c.repo, kinds)
+	switch *format {
+	case "json":
+		return c.printJSON(doc)
+	case "ndjson":
+		return writeNDJSON(c.out, doc)
+	}
+	return fmt.Errorf("unknown format %q", *format)
+}
+
+func (c *cli) printTasks(tasks []Task) error {
+	if c.json {
+		return c.printJSON(newExporter(c.repo).tasks(tasks))
+	}
+	projects := c.repo.GetProjectNameMap()
+	for _, t := range tasks {
+		fmt.Fprintln(c.out, cliTaskLine(t, projects))
+	}
+	return nil
+}
+
+// cliTaskLine renders a task as "<id>  p1 content  due  #project @labels".
+func cliTaskLine(t Task, projects map[string]string) string {
+	parts := []string{t.ID + " "}
+	if p := priorityLabel(t.Priority); p != "" {
+		parts = append(parts, p)
+	}
+	parts = append(parts, t.Content)
+	if due := formatDue(t.Due); due != "" {
+		parts = append(parts, " "+due)
+	}
+	if deadline := formatDeadline(t.Deadline); deadline != "" {
+		parts = append(parts, deadline)
+	}
+	if name := projects[t.ProjectID]; name != "" {
+		parts = append(parts, " #"+name)
+	}
+	for _, l := range t.Labels {
+		parts = append(parts, "@"+l)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunCLIRequiresToken(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	if code := runCLI([]string{"ls"}, ""); code != 1 {
+		t.Errorf("runCLI without a token exited %d, want 1", code)
+	}
+}
+
+// fakeAccount is the server side of the CLI tests: one inbox holding one task.
+var fakeAccount = SyncResponse{
+	SyncToken: "tok",
+	FullSync:  true,
+	Projects:  []Project{{ID: "inbox", Name: "Inbox", InboxProject: true}},
+	Items:     []Task{{ID: "t1", ProjectID: "inbox", Content: "Existing task", Priority: 1}},
+}
+
+// serveFakeAccount answers syncs with fakeAccount, accepts every command and
+// quick-adds tasks; quickAddStatus, when set, fails quick adds instead.
+func serveFakeAccount(t *testing.T, quickAddStatus int) *[]SyncCommand {
+	t.Helper()
+	var sent []SyncCommand
+	withAPIServer(t, func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/sync":
+			cmds := syncCommands(t, req)
+			resp := fakeAccount
+			resp.SyncStatus = map[string]json.RawMessage{}
+			for _, cmd := range cmds {
+				resp.SyncStatus[cmd.UUID] = json.RawMessage(`"ok"`)
+			}
+			sent = append(sent, cmds...)
+			writeJSON(t, w, resp)
+		case "/tasks/quick":
+			if quickAddStatus != 0 {
+				http.Error(w, "Invalid argument value", quickAddStatus)
+				return
+			}
+			writeJSON(t, w, Task{ID: "real-1", ProjectID: "inbox", Content: "Buy milk", Priority: 4})
+		default:
+			t.Errorf("unexpected request %s", req.URL.Path)
+			http.NotFound(w, req)
+		}
+	})
+	return &sent
+}
+
+// goOffline points the API clients at a server that refuses connections.
+func goOffline(t *testing.T) {
+	t.Helper()
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	old := baseURL
+	baseURL = srv.URL
+	t.Cleanup(func() { baseURL = old })
+}
+
+// newTestCLI returns a CLI over a fresh cache; synced seeds it with
+// fakeAccount as an earlier sync would have.
+func newTestCLI(t *testing.T, synced bool) (c *cli, stdout, stderr *bytes.Buffer) {
+	t.Helper()
+	r := newTestRepository(t)
+	if synced {
+		resp := fakeAccount
+		if err := r.store.MergeSyncResponse(&resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+	return &cli{repo: r, out: stdout, err: stderr}, stdout, stderr
+}
+
+func decodeRecords[T any](t *testing.T, out *bytes.Buffer) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(out.Bytes(), &v); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	return v
+}
+
+func TestCLIAddJSON(t *testing.T) {
+	t.Run("online", func(t *testing.T) {
+		serveFakeAccount(t, 0)
+		c, out, _ := newTestCLI(t, false)
+		if err := c.add([]string{"Buy milk tomorrow", "--json"}); err != nil {
+			t.Fatal(err)
+		}
+		rec := decodeRecords[taskRecord](t, out)
+		if rec.Kind != "task" || rec.SchemaVersion != exportSchemaVersion || rec.ID != "real-1" || rec.SyncStatus != "" {
+			t.Errorf("add --json = %+v, want the server's task real-1", rec)
+		}
+		if n := len(c.repo.GetAllMutations()); n != 0 {
+			t.Errorf("%d mutations left queued", n)
+		}
+	})
+	t.Run("offline", func(t *testing.T) {
+		goOffline(t)
+		c, out, _ := newTestCLI(t, true)
+		if err := c.add([]string{"Buy milk tomorrow", "--json"}); err != nil {
+			t.Fatal(err)
+		}
+		rec := decodeRecords[taskRecord](t, out)
+		if !IsPendingID(rec.ID) || rec.ProjectID != "inbox" || rec.SyncStatus != string(MutationPending) {
+			t.Errorf("add --json = %+v, want a pending temp task in the inbox", rec)
+		}
+		if task := findTask(c.repo, rec.ID); task == nil {
+			t.Errorf("temp task %s not cached", rec.ID)
+		}
+	})
+}
+
+func TestCLIAddConflictFails(t *testing.T) {
+	serveFakeAccount(t, http.StatusBadRequest)
+	c, out, _ := newTestCLI(t, true)
+	if err := c.add([]string{"Buy milk"}); err == nil {
+		t.Fatal("add succeeded though the server rejected the task")
+	}
+	if strings.Contains(out.String(), "Added") {
+		t.Errorf("add reported success: %q", out)
+	}
+	muts := c.repo.GetAllMutations()
+	if len(muts) != 1 || muts[0].Status != MutationConflicted {
+		t.Errorf("queue = %+v, want one conflicted quick add", muts)
+	}
+}
+
+func TestCLILsJSON(t *testing.T) {
+	t.Run("online", func(t *testing.T) {
+		serveFakeAccount(t, 0)
+		c, out, _ := newTestCLI(t, false)
+		if err := c.ls([]string{"--json"}); err != nil {
+			t.Fatal(err)
+		}
+		recs := decodeRecords[[]taskRecord](t, out)
+		if len(recs) != 1 || recs[0].ID != "t1" || recs[0].ProjectName != "Inbox" || recs[0].SchemaVersion != exportSchemaVersion {
+			t.Errorf("ls --json = %+v, want t1 from the first sync", recs)
+		}
+	})
+	t.Run("offline", func(t *testing.T) {
+		goOffline(t)
+		c, out, _ := newTestCLI(t, true)
+		if err := c.ls([]string{"--json"}); err != nil {
+			t.Fatal(err)
+		}
+		if recs := decodeRecords[[]taskRecord](t, out); len(recs) != 1 || recs[0].ID != "t1" {
+			t.Errorf("ls --json = %+v, want t1 from the cache", recs)
+		}
+	})
+	t.Run("offline never synced", func(t *testing.T) {
+		goOffline(t)
+		c, out, stderr := newTestCLI(t, false)
+		if err := c.ls([]string{"--json"}); err != nil {
+			t.Fatal(err)
+		}
+		if recs := decodeRecords[[]taskRecord](t, out); len(recs) != 0 {
+			t.Errorf("ls --json = %+v, want no tasks", recs)
+		}
+		if !strings.Contains(stderr.String(), "offline") {
+			t.Errorf("no offline warning: %q", stderr)
+		}
+	})
+}
+
+func TestCLIDoneJSON(t *testing.T) {
+	type doneOutput struct {
+		Completed []taskRecord `json:"completed"`
+		Queued    bool         `json:"queued"`
+	}
+	t.Run("online", func(t *testing.T) {
+		sent := serveFakeAccount(t, 0)
+		c, out, _ := newTestCLI(t, true)
+		if err := c.done([]string{"t1", "--json"}); err != nil {
+			t.Fatal(err)
+		}
+		got := decodeRecords[doneOutput](t, out)
+		if got.Queued || len(got.Completed) != 1 || got.Completed[0].ID != "t1" || got.Completed[0].SchemaVersion != exportSchemaVersion {
+			t.Errorf("done --json = %+v, want t1 completed and sent", got)
+		}
+		if len(*sent) != 1 || (*sent)[0].Type != "item_complete" || (*sent)[0].Args["id"] != "t1" {
+			t.Errorf("sent %+v, want one item_complete for t1", *sent)
+		}
+	})
+	t.Run("offline", func(t *testing.T) {
+		goOffline(t)
+		c, out, _ := newTestCLI(t, true)
+		if err := c.done([]string{"t1", "--json"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := decodeRecords[doneOutput](t, out); !got.Queued || len(got.Completed) != 1 || got.Completed[0].ID != "t1" {
+			t.Errorf("done --json = %+v, want t1 completed and queued", got)
+		}
+		muts := c.repo.GetAllMutations()
+		if len(muts) != 1 || muts[0].Action != MutationClose || muts[0].Status != MutationPending {
+			t.Errorf("queue = %+v, want one pending close", muts)
+		}
+	})
+}
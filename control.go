@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The control API lets editor plugins and status bars drive a running
+// instance. It speaks JSON-RPC 2.0 on a Unix socket, one message per line:
+//
+//	tasks.list           {"project"?, "filter"?}   → [task records]
+//	tasks.quickAdd       {"text", "project"?}      → {"task": task record | null}
+//	tasks.complete       {"ids"}                   → {"completed": [task records], "error"?}
+//	ui.navigateToTask    {"id"}                    → {"project_id"}
+//	ui.navigateToProject {"project"}               → {"project_id"}; "" opens Today
+//	events.subscribe                               → {"subscribed": true}
+//	events.unsubscribe                             → {"subscribed": false}
+//
+// Subscribers receive "event" notifications whose params are a controlEvent.
+// Task records follow the export schema.
+
+const controlSocketName = "control.sock"
+
+// controlCallTimeout bounds how long a call waits on the app's update loop.
+const controlCallTimeout = 10 * time.Second
+
+// JSON-RPC error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// controlEvent tells subscribers the app's data changed.
+type controlEvent struct {
+	Type    string    `json:"type"`    // "tasks_changed", "synced" or "queue_changed"
+	Pending int       `json:"pending"` // mutations still queued
+	At      time.Time `json:"at"`
+}
+
+// rpcCallMsg injects a control API call into the running program. The app
+// runs it as a command and the outcome goes back on reply.
+type rpcCallMsg struct {
+	method string
+	params json.RawMessage
+	reply  chan rpcReply
+}
+
+type rpcReply struct {
+	result any
+	err    error
+}
+
+// controlMethod runs a call against the repository. The returned message, if
+// any, is fed to the app so views update as they would for a keypress.
+type controlMethod func(repo *Repository, params json.RawMessage) (any, tea.Msg, error)
+
+var controlMethods = map[string]controlMethod{
+	"tasks.list":           rpcListTasks,
+	"tasks.quickAdd":       rpcQuickAdd,
+	"tasks.complete":       rpcCompleteTasks,
+	"ui.navigateToTask":    rpcNavigateToTask,
+	"ui.navigateToProject": rpcNavigateToProject,
+}
+
+// run executes the call on a command goroutine.
+func (call rpcCallMsg) run(repo *Repository) tea.Cmd {
+	return func() tea.Msg {
+		result, msg, err := controlMethods[call.method](repo, call.params)
+		call.reply <- rpcReply{result: result, err: err}
+		if msg == nil {
+			return noopMsg{}
+		}
+		return msg
+	}
+}
+
+func decodeParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func rpcListTasks(repo *Repository, raw json.RawMessage) (any, tea.Msg, error) {
+	var params struct {
+		Project string `json:"project"`
+		Filter  string `json:"filter"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, nil, err
+	}
+	tasks, err := queryTasks(repo, params.Project, params.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newExporter(repo).tasks(tasks), nil, nil
+}
+
+func rpcQuickAdd(repo *Repository, raw json.RawMessage) (any, tea.Msg, error) {
+	var params struct {
+		Text    string `json:"text"`
+		Project string `json:"project"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, nil, err
+	}
+	if strings.TrimSpace(params.Text) == "" {
+		return nil, nil, &rpcError{Code: rpcInvalidParams, Message: "text is required"}
+	}
+	projectID := ""
+	if params.Project != "" {
+		p, err := findProject(repo, params.Project)
+		if err != nil {
+			return nil, nil, err
+		}
+		projectID = p.ID
+	}
+	msg, _ := repo.QuickAdd(params.Text, projectID)().(quickAddMsg)
+	if msg.err != nil {
+		return nil, nil, msg.err
+	}
+	var task *taskRecord
+	if msg.task != nil {
+		rec := newExporter(repo).task(*msg.task)
+		task = &rec
+	}
+	return map[string]any{"task": task}, msg, nil
+}
+
+func rpcCompleteTasks(repo *Repository, raw json.RawMessage) (any, tea.Msg, error) {
+	var params struct {
+		IDs []string `json:"ids"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, nil, err
+	}
+	if len(params.IDs) == 0 {
+		return nil, nil, &rpcError{Code: rpcInvalidParams, Message: "ids is required"}
+	}
+	tasks := make(map[string]Task, len(params.IDs))
+	for _, id := range params.IDs {
+		task := findTask(repo, id)
+		if task == nil {
+			return nil, nil, fmt.Errorf("no task %q in the cache", id)
+		}
+		tasks[id] = *task
+	}
+	// One call is one queue group and one undo step; only tasks that
+	// actually closed are reported.
+	var closed []Task
+	var closeErr error
+	msg, _ := repo.BulkEdit("Completed", params.IDs, func(id string) tea.Cmd {
+		return func() tea.Msg {
+			closeMsg, _ := repo.CloseTask(id)().(taskClosedMsg)
+			if closeMsg.err != nil {
+				closeErr = errors.Join(closeErr, fmt.Errorf("complete %s: %w", id, closeMsg.err))
+				return closeMsg
+			}
+			closed = append(closed, tasks[id])
+			return closeMsg
+		}
+	})().(bulkEditMsg)
+	if len(closed) == 0 && closeErr != nil {
+		return nil, nil, closeErr
+	}
+	msg.count = len(closed)
+	result := map[string]any{"completed": newExporter(repo).tasks(closed)}
+	if closeErr != nil {
+		result["error"] = closeErr.Error()
+	}
+	return result, msg, nil
+}
+
+func rpcNavigateToTask(repo *Repository, raw json.RawMessage) (any, tea.Msg, error) {
+	var params struct {
+		ID string `json:"id"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, nil, err
+	}
+	task := findTask(repo, params.ID)
+	if task == nil {
+		return nil, nil, fmt.Errorf("no task %q in the cache", params.ID)
+	}
+	result := map[string]string{"project_id": task.ProjectID}
+	return result, navigateToTaskMsg{projectID: task.ProjectID, taskID: task.ID}, nil
+}
+
+func rpcNavigateToProject(repo *Repository, raw json.RawMessage) (any, tea.Msg, error) {
+	var params struct {
+		Project string `json:"project"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, nil, err
+	}
+	projectID := ""
+	if params.Project != "" {
+		p, err := findProject(repo, params.Project)
+		if err != nil {
+			return nil, nil, err
+		}
+		projectID = p.ID
+	}
+	return map[string]string{"project_id": projectID}, navigateToProjectMsg{projectID: projectID}, nil
+}
+
+// controlServer serves the control API for one running program.
+type controlServer struct {
+	repo     *Repository
+	listener net.Listener
+
+	mu   sync.Mutex
+	subs map[*controlConn]bool
+}
+
+// controlConn queues a connection's outgoing messages for its writer.
+type controlConn struct {
+	out    chan any
+	closed chan struct{} // closed when the writer stops
+}
+
+// listenControl opens the control socket in the cache directory. A socket
+// left behind by a crashed instance is replaced; a live one is an error.
+func listenControl(repo *Repository) (*controlServer, error) {
+	db, err := cacheDBPath()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(filepath.Dir(db), controlSocketName)
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("another instance is listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return &controlServer{repo: repo, listener: l, subs: make(map[*controlConn]bool)}, nil
+}
+
+// Close stops accepting connections and removes the socket.
+func (s *controlServer) Close() error {
+	return s.listener.Close()
+}
+
+// serve accepts connections until Close, injecting their calls into p.
+func (s *controlServer) serve(p *tea.Program) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn, p)
+	}
+}
+
+// publish notifies subscribers of a change. It never blocks: a subscriber
+// that falls behind misses events.
+func (s *controlServer) publish(kind string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.subs) == 0 {
+		return
+	}
+	note := rpcNotification{
+		JSONRPC: "2.0",
+		Method:  "event",
+		Params:  controlEvent{Type: kind, Pending: s.repo.PendingCount(), At: time.Now().UTC()},
+	}
+	for c := range s.subs {
+		select {
+		case c.out <- note:
+		default:
+		}
+	}
+}
+
+func (s *controlServer) subscribe(c *controlConn, on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if on {
+		s.subs[c] = true
+	} else {
+		delete(s.subs, c)
+	}
+}
+
+func (s *controlServer) handle(nc net.Conn, p *tea.Program) {
+	c := &controlConn{out: make(chan any, 64), closed: make(chan struct{})}
+	go func() {
+		defer close(c.closed)
+		defer nc.Close()
+		enc := json.NewEncoder(nc)
+		for v := range c.out {
+			if err := enc.Encode(v); err != nil {
+				return
+			}
+		}
+	}()
+	// Unsubscribe before closing out so publish never sends on it; the
+	// writer then drains what's left and closes the connection.
+	defer func() {
+		s.subscribe(c, false)
+		close(c.out)
+	}()
+
+	r := bufio.NewReader(nc)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			if resp, ok := s.call(c, p, line); ok {
+				select {
+				case c.out <- resp:
+				case <-c.closed:
+					return
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// call answers one request line. Notifications (requests without an ID) run
+// but get no response.
+func (s *controlServer) call(c *controlConn, p *tea.Program, line []byte) (rpcResponse, bool) {
+	var req rpcRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return rpcFailure(json.RawMessage("null"), &rpcError{Code: rpcParseError, Message: err.Error()}), true
+	}
+	respond := len(req.ID) > 0
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcFailure(req.ID, &rpcError{Code: rpcInvalidRequest, Message: "not a JSON-RPC 2.0 request"}), respond
+	}
+
+	var result any
+	switch req.Method {
+	case "events.subscribe", "events.unsubscribe":
+		on := req.Method == "events.subscribe"
+		s.subscribe(c, on)
+		result = map[string]bool{"subscribed": on}
+	default:
+		if _, ok := controlMethods[req.Method]; !ok {
+			return rpcFailure(req.ID, &rpcError{Code: rpcMethodNotFound, Message: "unknown method " + req.Method}), respond
+		}
+		reply := make(chan rpcReply, 1)
+		p.Send(rpcCallMsg{method: req.Method, params: req.Params, reply: reply})
+		select {
+		case r := <-reply:
+			if r.err != nil {
+				return rpcFailure(req.ID, r.err), respond
+			}
+			result = r.result
+		case <-time.After(controlCallTimeout):
+			return rpcFailure(req.ID, errors.New("timed out waiting for the app")), respond
+		}
+	}
+
+	blob, err := json.Marshal(result)
+	if err != nil {
+		return rpcFailure(req.ID, err), respond
+	}
+	return rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: blob}, respond
+}
+
+func rpcFailure(id json.RawMessage, err error) rpcResponse {
+	var rerr *rpcError
+	if !errors.As(err, &rerr) {
+		rerr = &rpcError{Code: rpcServerError, Message: err.Error()}
+	}
+	return rpcResponse{JSONRPC: "2.0", ID: id, Error: rerr}
+}
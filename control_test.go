@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRPCCompleteTasksIsOneUnit(t *testing.T) {
+	r := newTestRepository(t)
+	for _, id := range []string{"t1", "t2"} {
+		if err := r.store.UpsertTask(Task{ID: id, Content: id, ProjectID: "p"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, msg, err := rpcCompleteTasks(r, json.RawMessage(`{"ids":["t1","t2"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bulk, ok := msg.(bulkEditMsg); !ok || bulk.count != 2 {
+		t.Errorf("message = %#v, want a bulk edit of 2 tasks", msg)
+	}
+	if completed := result.(map[string]any)["completed"].([]taskRecord); len(completed) != 2 {
+		t.Errorf("completed = %+v, want t1 and t2", completed)
+	}
+	muts := r.GetAllMutations()
+	if len(muts) != 2 || muts[0].GroupID == "" || muts[0].GroupID != muts[1].GroupID {
+		t.Errorf("queue = %+v, want two closes in one group", muts)
+	}
+	if len(r.history.undo) != 1 {
+		t.Errorf("undo stack has %d steps, want 1", len(r.history.undo))
+	}
+}
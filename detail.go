@@ -0,0 +1,397 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DetailView shows a single task in full: content, description, metadata
+// and its comment thread.
+type DetailView struct {
+	repo     *Repository
+	task     Task
+	comments []Comment
+	cursor   int // selected comment
+
+	// Input state
+	mode         string // "", "description", "comment", "edit-comment", "delete-comment"
+	descInput    textarea.Model
+	commentInput textarea.Model
+
+	scrollOffset int
+	width        int
+	height       int
+}
+
+func NewDetailView(repo *Repository) DetailView {
+	di := textarea.New()
+	di.Placeholder = "Add a description..."
+	di.ShowLineNumbers = false
+	di.CharLimit = 16000
+
+	ci := textarea.New()
+	ci.Placeholder = "Write a comment..."
+	ci.ShowLineNumbers = false
+	ci.CharLimit = 15000
+	ci.SetHeight(3)
+
+	return DetailView{repo: repo, descInput: di, commentInput: ci}
+}
+
+// Open shows task with its cached comment thread.
+func (v *DetailView) Open(task Task) tea.Cmd {
+	v.task = task
+	v.mode = ""
+	v.scrollOffset = 0
+	v.comments = v.repo.GetCachedComments(task.ID)
+	v.cursor = len(v.comments) - 1
+	v.clampCursor()
+	return nil
+}
+
+// Reload re-reads the task and its comments from cache after a sync or flush.
+func (v *DetailView) Reload() {
+	if t, err := v.repo.store.GetTaskByID(v.task.ID); err == nil && t != nil {
+		v.task = *t
+	}
+	v.comments = v.repo.GetCachedComments(v.task.ID)
+	v.clampCursor()
+}
+
+func (v *DetailView) clampCursor() {
+	if v.cursor >= len(v.comments) {
+		v.cursor = len(v.comments) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
+func (v DetailView) selectedComment() *Comment {
+	if v.cursor < 0 || v.cursor >= len(v.comments) {
+		return nil
+	}
+	return &v.comments[v.cursor]
+}
+
+func (v *DetailView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+	v.descInput.SetWidth(width - 8)
+	v.descInput.SetHeight(max(3, height/3))
+	v.commentInput.SetWidth(width - 8)
+}
+
+func (v DetailView) handlesInput() bool {
+	return v.mode != ""
+}
+
+// confirming reports whether the pane is asking to confirm a comment deletion.
+func (v DetailView) confirming() bool {
+	return v.mode == "delete-comment"
+}
+
+func (v DetailView) Update(msg tea.Msg) (DetailView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case commentAddedMsg:
+		if msg.comment.TaskID != nil && *msg.comment.TaskID == v.task.ID {
+			v.comments = append(v.comments, msg.comment)
+			v.cursor = len(v.comments) - 1
+		}
+		return v, nil
+
+	case commentUpdatedMsg:
+		for i := range v.comments {
+			if v.comments[i].ID == msg.comment.ID {
+				v.comments[i] = msg.comment
+			}
+		}
+		return v, nil
+
+	case commentDeletedMsg:
+		for i := range v.comments {
+			if v.comments[i].ID == msg.commentID {
+				v.comments = append(v.comments[:i], v.comments[i+1:]...)
+				break
+			}
+		}
+		v.clampCursor()
+		return v, nil
+
+	case taskUpdatedMsg:
+		if msg.err == nil && msg.task.ID == v.task.ID {
+			v.task = msg.task
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.mode != "" {
+			return v.handleInputKey(msg)
+		}
+		switch ResolveAction(ContextDetailOverlay, msg.String()) {
+		case ActionNavDown:
+			v.scrollOffset++
+			return v, nil
+		case ActionNavUp:
+			if v.scrollOffset > 0 {
+				v.scrollOffset--
+			}
+			return v, nil
+		case ActionEditTask:
+			v.mode = "description"
+			v.descInput.SetValue(v.task.Description)
+			return v, v.descInput.Focus()
+		case ActionAddComment:
+			v.mode = "comment"
+			v.commentInput.Reset()
+			return v, v.commentInput.Focus()
+		case ActionNextComment:
+			if v.cursor < len(v.comments)-1 {
+				v.cursor++
+			}
+			return v, nil
+		case ActionPrevComment:
+			if v.cursor > 0 {
+				v.cursor--
+			}
+			return v, nil
+		case ActionEditComment:
+			if c := v.selectedComment(); c != nil {
+				v.mode = "edit-comment"
+				v.commentInput.SetValue(c.Content)
+				return v, v.commentInput.Focus()
+			}
+			return v, nil
+		case ActionDeleteComment:
+			if v.selectedComment() != nil {
+				v.mode = "delete-comment"
+			}
+			return v, nil
+		}
+		return v, nil
+	}
+
+	// Cursor blink and other input plumbing
+	switch v.mode {
+	case "description":
+		var cmd tea.Cmd
+		v.descInput, cmd = v.descInput.Update(msg)
+		return v, cmd
+	case "comment", "edit-comment":
+		var cmd tea.Cmd
+		v.commentInput, cmd = v.commentInput.Update(msg)
+		return v, cmd
+	}
+	return v, nil
+}
+
+func (v DetailView) handleInputKey(msg tea.KeyMsg) (DetailView, tea.Cmd) {
+	if v.mode == "delete-comment" {
+		switch ResolveAction(ContextMainSidebarDialog, msg.String()) {
+		case ActionConfirm:
+			v.mode = ""
+			if c := v.selectedComment(); c != nil {
+				return v, v.repo.DeleteComment(c.ID)
+			}
+		case ActionCancel:
+			v.mode = ""
+		}
+		return v, nil
+	}
+
+	switch ResolveAction(ContextDetailInput, msg.String()) {
+	case ActionConfirm:
+		mode := v.mode
+		v.mode = ""
+		v.descInput.Blur()
+		v.commentInput.Blur()
+		switch mode {
+		case "description":
+			desc := strings.TrimRight(v.descInput.Value(), " \n")
+			if desc == v.task.Description {
+				return v, nil
+			}
+			return v, v.repo.UpdateTask(v.task.ID, updateTaskRequest{Description: &desc})
+		case "comment":
+			content := strings.TrimSpace(v.commentInput.Value())
+			if content == "" {
+				return v, nil
+			}
+			return v, v.repo.AddComment(v.task.ID, content)
+		case "edit-comment":
+			content := strings.TrimSpace(v.commentInput.Value())
+			c := v.selectedComment()
+			if c == nil || content == "" || content == c.Content {
+				return v, nil
+			}
+			return v, v.repo.UpdateComment(c.ID, content)
+		}
+		return v, nil
+	case ActionCancel:
+		v.mode = ""
+		v.descInput.Blur()
+		v.commentInput.Blur()
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	if v.mode == "description" {
+		v.descInput, cmd = v.descInput.Update(msg)
+	} else {
+		v.commentInput, cmd = v.commentInput.Update(msg)
+	}
+	return v, cmd
+}
+
+func (v DetailView) View(width, height int) string {
+	textW := width - 8
+	if textW < 20 {
+		textW = 20
+	}
+	wrap := lipgloss.NewStyle().Width(textW)
+
+	var lines []string
+	add := func(s string) {
+		lines = append(lines, strings.Split(s, "\n")...)
+	}
+
+	add(lipgloss.NewStyle().Foreground(colorBright).Bold(true).Width(textW).Render(v.task.Content))
+	add("")
+	for _, row := range v.metadataRows() {
+		add(inputLabelStyle.Render(fmt.Sprintf("%-10s", row[0])) + row[1])
+	}
+	add("")
+
+	add(queueTitleStyle.Render("━━ Description"))
+	switch {
+	case v.mode == "description":
+		add(v.descInput.View())
+	case v.task.Description == "":
+		add(emptyStyle.Render("No description — press e to add one"))
+	default:
+		add(wrap.Render(v.task.Description))
+	}
+	add("")
+
+	add(queueTitleStyle.Render(fmt.Sprintf("━━ Comments (%d)", len(v.comments))))
+	if len(v.comments) == 0 && v.mode != "comment" {
+		add(emptyStyle.Render("No comments — press c to add one"))
+	}
+	syncStatus := v.repo.CommentMutationStatusMap()
+	for i, c := range v.comments {
+		marker := "  "
+		if i == v.cursor {
+			marker = footerKeyStyle.Render("▸ ")
+		}
+		meta := todayProjectTagStyle.Render(formatCommentTime(c.PostedAt))
+		if badge := mutationBadgeStyled(syncStatus[c.ID]); badge != "" {
+			meta += " " + badge
+		}
+		add(marker + meta)
+		switch {
+		case i == v.cursor && v.mode == "edit-comment":
+			add(v.commentInput.View())
+		case i == v.cursor && v.mode == "delete-comment":
+			add(queueConflictStyle.Render("Delete this comment?"))
+		default:
+			add(wrap.Render(c.Content))
+		}
+		add("")
+	}
+	if v.mode == "comment" {
+		add(v.commentInput.View())
+	}
+
+	// Keep the input in view while editing; otherwise honor the scroll offset.
+	bodyH := height - 6
+	if bodyH < 1 {
+		bodyH = 1
+	}
+	maxOffset := len(lines) - bodyH
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+	offset := v.scrollOffset
+	if offset > maxOffset || v.mode == "comment" {
+		offset = maxOffset
+	}
+	if v.mode == "description" {
+		offset = 0
+	}
+	end := offset + bodyH
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(lines[offset:end], "\n"))
+	b.WriteString("\n\n")
+	switch v.mode {
+	case "":
+		b.WriteString(footerKeyStyle.Render("j/k") + " scroll  " +
+			footerKeyStyle.Render("J/K") + " select comment  " +
+			footerKeyStyle.Render("e") + " description  " +
+			footerKeyStyle.Render("c") + " comment  " +
+			footerKeyStyle.Render("E/D") + " edit/delete comment  " +
+			footerKeyStyle.Render("esc") + " close")
+	case "delete-comment":
+		b.WriteString(footerKeyStyle.Render("y") + " delete  " +
+			footerKeyStyle.Render("n") + " cancel")
+	default:
+		b.WriteString(footerKeyStyle.Render("ctrl+s") + " save  " +
+			footerKeyStyle.Render("esc") + " cancel")
+	}
+
+	return helpStyle.Width(width).Height(height).Render(b.String())
+}
+
+// metadataRows returns the label/value pairs shown under the task title.
+func (v DetailView) metadataRows() [][2]string {
+	t := v.task
+	var rows [][2]string
+	if name := v.repo.GetProjectNameMap()[t.ProjectID]; name != "" {
+		rows = append(rows, [2]string{"Project", name})
+	}
+	if t.Due != nil {
+		due := formatDue(t.Due)
+		if t.Due.String != "" && t.Due.Date != "" {
+			due += " (" + t.Due.Date + ")"
+		}
+		if t.Due.IsRecurring {
+			due += " " + recurringStyle.Render("↻")
+		}
+		rows = append(rows, [2]string{"Due", due})
+	}
+	if t.Deadline != nil && t.Deadline.Date != "" {
+		rows = append(rows, [2]string{"Deadline", t.Deadline.Date})
+	}
+	if t.Priority > 0 && t.Priority < 4 {
+		rows = append(rows, [2]string{"Priority", priorityStyle(t.Priority).Render(priorityLabel(t.Priority))})
+	}
+	if len(t.Labels) > 0 {
+		lbls := make([]string, len(t.Labels))
+		for i, l := range t.Labels {
+			lbls[i] = "@" + l
+		}
+		rows = append(rows, [2]string{"Labels", labelStyle.Render(strings.Join(lbls, " "))})
+	}
+	if assignee := formatAssignee(&t, v.repo.GetAssigneeNameMap()); assignee != "" {
+		rows = append(rows, [2]string{"Assignee", assigneeStyle.Render(assignee)})
+	}
+	return rows
+}
+
+// formatCommentTime renders a comment timestamp in local time.
+func formatCommentTime(postedAt string) string {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.000000Z"} {
+		if t, err := time.Parse(layout, postedAt); err == nil {
+			return t.Local().Format("Jan 2 15:04")
+		}
+	}
+	return postedAt
+}
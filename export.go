@@ -0,0 +1,337 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+// exportSchemaVersion versions the export records below. Adding a field keeps
+// the version; renaming, removing or retyping one bumps it.
+const exportSchemaVersion = 1
+
+// recordHeader starts every export record, so each NDJSON line says what it
+// is and which schema it follows.
+type recordHeader struct {
+	Kind          string `json:"kind"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+func header(kind string) recordHeader {
+	return recordHeader{Kind: kind, SchemaVersion: exportSchemaVersion}
+}
+
+type taskRecord struct {
+	recordHeader
+	ID           string     `json:"id"`
+	Content      string     `json:"content"`
+	Description  string     `json:"description"`
+	ProjectID    string     `json:"project_id"`
+	ProjectName  string     `json:"project_name"`
+	SectionID    string     `json:"section_id"`
+	SectionName  string     `json:"section_name"`
+	ParentID     string     `json:"parent_id"`
+	Priority     int        `json:"priority"`
+	Due          *dueRecord `json:"due"`
+	Deadline     string     `json:"deadline"`
+	Labels       []string   `json:"labels"`
+	AssigneeID   string     `json:"assignee_id"`
+	AssigneeName string     `json:"assignee_name"`
+	Overdue      bool       `json:"overdue"`
+	DueToday     bool       `json:"due_today"`
+	Completed    bool       `json:"completed"`
+	AddedAt      string     `json:"added_at"`
+	SyncStatus   string     `json:"sync_status"` // "", "pending", "flushing" or "conflicted"
+}
+
+type dueRecord struct {
+	Date      string `json:"date"`
+	String    string `json:"string"`
+	Recurring bool   `json:"recurring"`
+}
+
+type projectRecord struct {
+	recordHeader
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	ParentID   string `json:"parent_id"`
+	Color      string `json:"color"`
+	IsFavorite bool   `json:"is_favorite"`
+	IsInbox    bool   `json:"is_inbox"`
+	Order      int    `json:"order"`
+}
+
+type sectionRecord struct {
+	recordHeader
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	Order       int    `json:"order"`
+}
+
+type labelRecord struct {
+	recordHeader
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Color      string `json:"color"`
+	IsFavorite bool   `json:"is_favorite"`
+	Order      int    `json:"order"`
+}
+
+type mutationRecord struct {
+	recordHeader
+	ID          int64            `json:"id"`
+	EntityType  string           `json:"entity_type"`
+	EntityID    string           `json:"entity_id"`
+	Action      MutationAction   `json:"action"`
+	Description string           `json:"description"`
+	Status      MutationStatus   `json:"status"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Attempts    int              `json:"attempts"`
+	GroupID     string           `json:"group_id"`
+	Conflicts   []ConflictRecord `json:"conflicts"`
+}
+
+// exportDocument is the single-object JSON export.
+type exportDocument struct {
+	SchemaVersion int              `json:"schema_version"`
+	ExportedAt    time.Time        `json:"exported_at"`
+	Projects      []projectRecord  `json:"projects"`
+	Sections      []sectionRecord  `json:"sections"`
+	Labels        []labelRecord    `json:"labels"`
+	Tasks         []taskRecord     `json:"tasks"`
+	Mutations     []mutationRecord `json:"mutations"`
+}
+
+// exporter resolves the names and sync state export records carry.
+type exporter struct {
+	projects  map[string]string
+	sections  map[string]string
+	assignees map[string]string
+	status    map[string]MutationStatus
+}
+
+func newExporter(repo *Repository) exporter {
+	sections := make(map[string]string)
+	for _, s := range repo.GetAllCachedSections() {
+		sections[s.ID] = s.Name
+	}
+	return exporter{
+		projects:  repo.GetProjectNameMap(),
+		sections:  sections,
+		assignees: repo.GetAssigneeNameMap(),
+		status:    repo.TaskMutationStatusMap(),
+	}
+}
+
+func (e exporter) task(t Task) taskRecord {
+	rec := taskRecord{
+		recordHeader: header("task"),
+		ID:           t.ID,
+		Content:      t.Content,
+		Description:  t.Description,
+		ProjectID:    t.ProjectID,
+		ProjectName:  e.projects[t.ProjectID],
+		SectionID:    t.SectionID,
+		SectionName:  e.sections[t.SectionID],
+		Priority:     t.Priority,
+		Deadline:     deadlineDate(t),
+		Labels:       t.Labels,
+		Overdue:      isTaskOverdue(&t),
+		DueToday:     isDueToday(t.Due),
+		Completed:    t.Checked,
+		AddedAt:      t.AddedAt,
+		SyncStatus:   string(e.status[t.ID]),
+	}
+	if rec.Labels == nil {
+		rec.Labels = []string{}
+	}
+	if t.ParentID != nil {
+		rec.ParentID = *t.ParentID
+	}
+	if t.Due != nil {
+		rec.Due = &dueRecord{Date: t.Due.Date, String: t.Due.String, Recurring: t.Due.IsRecurring}
+	}
+	if t.ResponsibleUID != nil {
+		rec.AssigneeID = *t.ResponsibleUID
+		rec.AssigneeName = e.assignees[rec.AssigneeID]
+	}
+	return rec
+}
+
+func (e exporter) tasks(tasks []Task) []taskRecord {
+	out := make([]taskRecord, len(tasks))
+	for i, t := range tasks {
+		out[i] = e.task(t)
+	}
+	return out
+}
+
+func (e exporter) project(p Project) projectRecord {
+	rec := projectRecord{
+		recordHeader: header("project"),
+		ID:           p.ID,
+		Name:         p.Name,
+		Color:        p.Color,
+		IsFavorite:   p.IsFavorite,
+		IsInbox:      p.InboxProject,
+		Order:        p.ChildOrder,
+	}
+	if p.ParentID != nil {
+		rec.ParentID = *p.ParentID
+	}
+	return rec
+}
+
+func (e exporter) section(s Section) sectionRecord {
+	return sectionRecord{
+		recordHeader: header("section"),
+		ID:           s.ID,
+		Name:         s.Name,
+		ProjectID:    s.ProjectID,
+		ProjectName:  e.projects[s.ProjectID],
+		Order:        s.SectionOrder,
+	}
+}
+
+func labelRecordOf(l Label) labelRecord {
+	return labelRecord{
+		recordHeader: header("label"),
+		ID:           l.ID,
+		Name:         l.Name,
+		Color:        l.Color,
+		IsFavorite:   l.IsFavorite,
+		Order:        l.Order,
+	}
+}
+
+func mutationRecordOf(m Mutation) mutationRecord {
+	conflicts := m.Conflicts()
+	if conflicts == nil {
+		conflicts = []ConflictRecord{}
+	}
+	return mutationRecord{
+		recordHeader: header("mutation"),
+		ID:           m.ID,
+		EntityType:   m.EntityType,
+		EntityID:     m.EntityID,
+		Action:       m.Action,
+		Description:  strings.TrimPrefix(strings.TrimPrefix(renderMutationLine(m), "↑ "), "⚠ "),
+		Status:       m.Status,
+		CreatedAt:    m.CreatedAt.UTC(),
+		Attempts:     m.Attempts,
+		GroupID:      m.GroupID,
+		Conflicts:    conflicts,
+	}
+}
+
+func mutationRecords(muts []Mutation) []mutationRecord {
+	out := make([]mutationRecord, len(muts))
+	for i, m := range muts {
+		out[i] = mutationRecordOf(m)
+	}
+	return out
+}
+
+// buildExport collects the cached data of the given kinds ("projects",
+// "sections", "labels", "tasks", "mutations") into one document.
+func buildExport(repo *Repository, kinds map[string]bool) exportDocument {
+	e := newExporter(repo)
+	doc := exportDocument{
+		SchemaVersion: exportSchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		Projects:      []projectRecord{},
+		Sections:      []sectionRecord{},
+		Labels:        []labelRecord{},
+		Tasks:         []taskRecord{},
+		Mutations:     []mutationRecord{},
+	}
+	if kinds["projects"] {
+		for _, p := range repo.GetCachedProjects() {
+			doc.Projects = append(doc.Projects, e.project(p))
+		}
+	}
+	if kinds["sections"] {
+		for _, s := range repo.GetAllCachedSections() {
+			doc.Sections = append(doc.Sections, e.section(s))
+		}
+	}
+	if kinds["labels"] {
+		for _, l := range repo.GetCachedLabels() {
+			doc.Labels = append(doc.Labels, labelRecordOf(l))
+		}
+	}
+	if kinds["tasks"] {
+		doc.Tasks = append(doc.Tasks, e.tasks(repo.GetAllCachedTasks())...)
+	}
+	if kinds["mutations"] {
+		doc.Mutations = append(doc.Mutations, mutationRecords(repo.GetAllMutations())...)
+	}
+	return doc
+}
+
+// writeNDJSON writes the document one record per line, projects first so
+// consumers see names before the tasks that use them.
+func writeNDJSON(w io.Writer, doc exportDocument) error {
+	enc := json.NewEncoder(w)
+	for _, p := range doc.Projects {
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+	}
+	for _, s := range doc.Sections {
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+	}
+	for _, l := range doc.Labels {
+		if err := enc.Encode(l); err != nil {
+			return err
+		}
+	}
+	for _, t := range doc.Tasks {
+		if err := enc.Encode(t); err != nil {
+			return err
+		}
+	}
+	for _, m := range doc.Mutations {
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyTasksJSON copies tasks to the clipboard as export records: one object
+// for a single task, an array for several. Without a system clipboard it
+// falls back to the terminal's (OSC 52).
+func copyTasksJSON(repo *Repository, tasks []Task) tea.Cmd {
+	if len(tasks) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		e := newExporter(repo)
+		var v any = e.tasks(tasks)
+		if len(tasks) == 1 {
+			v = e.task(tasks[0])
+		}
+		blob, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return toastMsg{text: "Copy failed: " + err.Error(), isError: true}
+		}
+		if err := clipboard.WriteAll(string(blob)); err != nil {
+			termenv.Copy(string(blob))
+		}
+		if len(tasks) == 1 {
+			return toastMsg{text: "Copied task as JSON"}
+		}
+		return toastMsg{text: fmt.Sprintf("Copied %d tasks as JSON", len(tasks))}
+	}
+}
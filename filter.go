@@ -0,0 +1,590 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter is a parsed Todoist filter query. A comma-separated query holds
+// several sub-queries; a task matches the filter if any of them match.
+type Filter struct {
+	Queries []string // source text of each comma-separated sub-query
+	preds   []filterPred
+}
+
+// filterPred reports whether a task satisfies one compiled filter expression.
+type filterPred func(t *Task, env *filterEnv) bool
+
+// filterEnv carries the cache context a filter is evaluated against.
+type filterEnv struct {
+	now       time.Time
+	projects  map[string]Project
+	sections  map[string]Section
+	userID    string            // signed-in user, for "assigned to: me"
+	userNames map[string]string // user ID -> display name
+}
+
+// Match reports whether a task satisfies any of the filter's sub-queries.
+func (f *Filter) Match(t *Task, env *filterEnv) bool {
+	for _, p := range f.preds {
+		if p(t, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply evaluates each sub-query against tasks and returns one group of
+// matches per sub-query, in query order.
+func (f *Filter) Apply(tasks []Task, env *filterEnv) [][]Task {
+	groups := make([][]Task, len(f.preds))
+	for i, p := range f.preds {
+		for j := range tasks {
+			t := &tasks[j]
+			if t.Checked || t.IsDeleted {
+				continue
+			}
+			if p(t, env) {
+				groups[i] = append(groups[i], *t)
+			}
+		}
+	}
+	return groups
+}
+
+// --- Lexer ---
+
+type filterTokKind int
+
+const (
+	filterTokTerm filterTokKind = iota
+	filterTokAnd
+	filterTokOr
+	filterTokNot
+	filterTokLParen
+	filterTokRParen
+	filterTokComma
+	filterTokEOF
+)
+
+type filterTok struct {
+	kind       filterTokKind
+	text       string
+	start, end int // byte offsets into the source query
+}
+
+// lexFilter splits a query into operators and term text. "!" only negates
+// where a term may begin, so it can still appear inside names; a backslash
+// escapes the next character.
+func lexFilter(src string) ([]filterTok, error) {
+	var toks []filterTok
+	termStart := true
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch c {
+		case ' ', '\t', '\n':
+			i++
+			continue
+		case '&', '|', '(', ')', ',':
+			kind := map[byte]filterTokKind{
+				'&': filterTokAnd, '|': filterTokOr, '(': filterTokLParen,
+				')': filterTokRParen, ',': filterTokComma,
+			}[c]
+			toks = append(toks, filterTok{kind: kind, text: string(c), start: i, end: i + 1})
+			termStart = c != ')'
+			i++
+			continue
+		case '!':
+			if termStart {
+				toks = append(toks, filterTok{kind: filterTokNot, text: "!", start: i, end: i + 1})
+				i++
+				continue
+			}
+		}
+
+		start := i
+		var b strings.Builder
+		for i < len(src) && !strings.ContainsRune("&|(),", rune(src[i])) {
+			if src[i] == '\\' {
+				if i+1 >= len(src) {
+					return nil, fmt.Errorf("dangling escape at end of filter")
+				}
+				i++
+			}
+			b.WriteByte(src[i])
+			i++
+		}
+		toks = append(toks, filterTok{kind: filterTokTerm, text: strings.TrimSpace(b.String()), start: start, end: i})
+		termStart = false
+	}
+	toks = append(toks, filterTok{kind: filterTokEOF, start: len(src), end: len(src)})
+	return toks, nil
+}
+
+// --- Parser ---
+
+// ParseFilter parses a Todoist filter query such as
+// "(today | overdue) & #Work & !@waiting & p1". Terms are compiled as they
+// are parsed, so unsupported syntax is reported here rather than at match time.
+func ParseFilter(query string) (*Filter, error) {
+	toks, err := lexFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{toks: toks}
+	f := &Filter{}
+	for {
+		start := p.peek().start
+		pred, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		f.preds = append(f.preds, pred)
+		f.Queries = append(f.Queries, strings.TrimSpace(query[start:p.toks[p.pos-1].end]))
+
+		switch tok := p.next(); tok.kind {
+		case filterTokComma:
+			continue
+		case filterTokEOF:
+			return f, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q in filter", tok.text)
+		}
+	}
+}
+
+type filterParser struct {
+	toks []filterTok
+	pos  int
+}
+
+func (p *filterParser) peek() filterTok { return p.toks[p.pos] }
+
+func (p *filterParser) next() filterTok {
+	tok := p.toks[p.pos]
+	if tok.kind != filterTokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr := parseAnd ('|' parseAnd)*
+func (p *filterParser) parseOr() (filterPred, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == filterTokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t *Task, env *filterEnv) bool { return l(t, env) || right(t, env) }
+	}
+	return left, nil
+}
+
+// parseAnd := parseUnary ('&' parseUnary)*
+func (p *filterParser) parseAnd() (filterPred, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == filterTokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t *Task, env *filterEnv) bool { return l(t, env) && right(t, env) }
+	}
+	return left, nil
+}
+
+// parseUnary := '!' parseUnary | '(' parseOr ')' | term
+func (p *filterParser) parseUnary() (filterPred, error) {
+	tok := p.next()
+	switch tok.kind {
+	case filterTokNot:
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(t *Task, env *filterEnv) bool { return !inner(t, env) }, nil
+	case filterTokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != filterTokRParen {
+			return nil, fmt.Errorf("missing ) in filter")
+		}
+		return inner, nil
+	case filterTokTerm:
+		return compileFilterTerm(tok.text)
+	case filterTokEOF:
+		return nil, fmt.Errorf("filter ends unexpectedly")
+	default:
+		return nil, fmt.Errorf("unexpected %q in filter", tok.text)
+	}
+}
+
+// --- Terms ---
+
+var (
+	filterDaysRe  = regexp.MustCompile(`^(?:next )?(-?\d+) days?$`)
+	filterSpaceRe = regexp.MustCompile(`\s+`)
+)
+
+// compileFilterTerm turns a single term such as "today", "#Work" or
+// "due before: friday" into a predicate.
+func compileFilterTerm(raw string) (filterPred, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("empty term in filter")
+	}
+	term := filterSpaceRe.ReplaceAllString(strings.ToLower(raw), " ")
+
+	switch {
+	case strings.HasPrefix(term, "##"):
+		return projectTreePred(term[2:]), nil
+	case strings.HasPrefix(term, "#"):
+		pattern := term[1:]
+		return func(t *Task, env *filterEnv) bool {
+			return wildcardMatch(pattern, env.projects[t.ProjectID].Name)
+		}, nil
+	case strings.HasPrefix(term, "@"):
+		pattern := term[1:]
+		return func(t *Task, env *filterEnv) bool {
+			for _, l := range t.Labels {
+				if wildcardMatch(pattern, l) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case strings.HasPrefix(term, "/"):
+		pattern := term[1:]
+		return func(t *Task, env *filterEnv) bool {
+			s, ok := env.sections[t.SectionID]
+			return ok && wildcardMatch(pattern, s.Name)
+		}, nil
+	}
+
+	if key, value, ok := strings.Cut(term, ":"); ok {
+		return compileFilterKeyword(key, strings.TrimSpace(value), raw)
+	}
+
+	switch term {
+	case "all", "view all":
+		return func(*Task, *filterEnv) bool { return true }, nil
+	case "overdue", "od":
+		return func(t *Task, env *filterEnv) bool { return dueOverdueAt(t.Due, env.now) }, nil
+	case "no date", "no due date":
+		return func(t *Task, _ *filterEnv) bool { return t.Due == nil || t.Due.Date == "" }, nil
+	case "no time":
+		return func(t *Task, _ *filterEnv) bool { return t.Due != nil && len(t.Due.Date) == 10 }, nil
+	case "no deadline":
+		return func(t *Task, _ *filterEnv) bool { return t.Deadline == nil || t.Deadline.Date == "" }, nil
+	case "recurring":
+		return func(t *Task, _ *filterEnv) bool { return t.Due != nil && t.Due.IsRecurring }, nil
+	case "subtask", "subtasks":
+		return func(t *Task, _ *filterEnv) bool { return t.ParentID != nil && *t.ParentID != "" }, nil
+	case "no labels", "no label":
+		return func(t *Task, _ *filterEnv) bool { return len(t.Labels) == 0 }, nil
+	case "assigned":
+		return func(t *Task, _ *filterEnv) bool { return t.ResponsibleUID != nil && *t.ResponsibleUID != "" }, nil
+	case "p1", "p2", "p3", "p4", "no priority":
+		want := 4
+		if term != "no priority" {
+			want = int(term[1] - '0')
+		}
+		return func(t *Task, _ *filterEnv) bool { return taskPriority(t) == want }, nil
+	}
+
+	if m := filterDaysRe.FindStringSubmatch(term); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return func(t *Task, env *filterEnv) bool {
+			day, ok := dueDay(t.Due, env.now)
+			if !ok {
+				return false
+			}
+			today := startOfDay(env.now)
+			if n < 0 {
+				return !day.Before(today.AddDate(0, 0, n)) && day.Before(today)
+			}
+			return !day.Before(today) && day.Before(today.AddDate(0, 0, n))
+		}, nil
+	}
+
+	if spec, ok := parseFilterDate(term); ok {
+		return dueOnPred(spec), nil
+	}
+	return nil, fmt.Errorf("unknown filter term %q", raw)
+}
+
+// compileFilterKeyword handles "key: value" terms.
+func compileFilterKeyword(key, value, raw string) (filterPred, error) {
+	switch key {
+	case "search":
+		if value == "" {
+			return nil, fmt.Errorf("search: needs text")
+		}
+		return func(t *Task, _ *filterEnv) bool {
+			return strings.Contains(strings.ToLower(t.Content), value)
+		}, nil
+	case "assigned to":
+		return assigneePred(value, func(t *Task) *string { return t.ResponsibleUID }), nil
+	case "assigned by":
+		return assigneePred(value, func(t *Task) *string { return t.AssignedByUID }), nil
+	}
+
+	var date func(t *Task, now time.Time) (time.Time, bool)
+	field, cmp, _ := strings.Cut(key, " ")
+	switch field {
+	case "due", "date":
+		date = func(t *Task, now time.Time) (time.Time, bool) { return dueDay(t.Due, now) }
+	case "deadline":
+		date = func(t *Task, now time.Time) (time.Time, bool) {
+			if t.Deadline == nil {
+				return time.Time{}, false
+			}
+			return parseDay(t.Deadline.Date, now.Location())
+		}
+	default:
+		return nil, fmt.Errorf("unknown filter term %q", raw)
+	}
+	if cmp != "" && cmp != "before" && cmp != "after" {
+		return nil, fmt.Errorf("unknown filter term %q", raw)
+	}
+	spec, ok := parseFilterDate(value)
+	if !ok {
+		return nil, fmt.Errorf("unrecognised date %q in filter", value)
+	}
+	return func(t *Task, env *filterEnv) bool {
+		day, ok := date(t, env.now)
+		if !ok {
+			return false
+		}
+		want := spec(env.now)
+		switch cmp {
+		case "before":
+			return day.Before(want)
+		case "after":
+			return day.After(want)
+		default:
+			return day.Equal(want)
+		}
+	}, nil
+}
+
+// projectTreePred matches tasks in a project whose name matches pattern, or
+// in any of that project's subprojects.
+func projectTreePred(pattern string) filterPred {
+	return func(t *Task, env *filterEnv) bool {
+		id := t.ProjectID
+		for depth := 0; id != "" && depth < 32; depth++ {
+			p, ok := env.projects[id]
+			if !ok {
+				return false
+			}
+			if wildcardMatch(pattern, p.Name) {
+				return true
+			}
+			if p.ParentID == nil {
+				return false
+			}
+			id = *p.ParentID
+		}
+		return false
+	}
+}
+
+// assigneePred matches "me", "others" or a user name against a task's user field.
+func assigneePred(who string, field func(*Task) *string) filterPred {
+	return func(t *Task, env *filterEnv) bool {
+		uid := field(t)
+		if uid == nil || *uid == "" {
+			return false
+		}
+		switch who {
+		case "me":
+			return env.userID != "" && *uid == env.userID
+		case "others":
+			return env.userID == "" || *uid != env.userID
+		}
+		return wildcardMatch(who, env.userNames[*uid]) || wildcardMatch(who+" *", env.userNames[*uid])
+	}
+}
+
+func dueOnPred(spec filterDate) filterPred {
+	return func(t *Task, env *filterEnv) bool {
+		day, ok := dueDay(t.Due, env.now)
+		return ok && day.Equal(spec(env.now))
+	}
+}
+
+// taskPriority returns the task's priority in app order, treating unset as p4.
+func taskPriority(t *Task) int {
+	if t.Priority < 1 || t.Priority > 4 {
+		return 4
+	}
+	return t.Priority
+}
+
+// wildcardMatch reports whether s matches pattern case-insensitively, where
+// "*" matches any run of characters. pattern must already be lower case.
+func wildcardMatch(pattern, s string) bool {
+	s = strings.ToLower(s)
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return s == pattern
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return len(s) >= len(last) && strings.HasSuffix(s, last)
+}
+
+// --- Dates ---
+
+// filterDate resolves a date term to a local day relative to now, so parsed
+// filters stay correct across midnight.
+type filterDate func(now time.Time) time.Time
+
+var (
+	filterMonthDayRe = regexp.MustCompile(`^([a-z]+) (\d{1,2})(?: (\d{4}))?$`)
+	filterDayMonthRe = regexp.MustCompile(`^(\d{1,2}) ([a-z]+)(?: (\d{4}))?$`)
+)
+
+// parseFilterDate parses today/tomorrow/yesterday, ISO dates, "jan 3",
+// "3 jan 2027" and weekday names. Dates without a year and weekday names
+// resolve to their next occurrence, today included.
+func parseFilterDate(s string) (filterDate, bool) {
+	switch s {
+	case "today":
+		return func(now time.Time) time.Time { return startOfDay(now) }, true
+	case "tomorrow":
+		return func(now time.Time) time.Time { return startOfDay(now).AddDate(0, 0, 1) }, true
+	case "yesterday":
+		return func(now time.Time) time.Time { return startOfDay(now).AddDate(0, 0, -1) }, true
+	}
+	if wd, ok := weekdayNames[s]; ok {
+		return func(now time.Time) time.Time {
+			today := startOfDay(now)
+			return today.AddDate(0, 0, (int(wd)-int(today.Weekday())+7)%7)
+		}, true
+	}
+	if d, err := time.Parse("2006-01-02", s); err == nil {
+		return func(now time.Time) time.Time {
+			return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, now.Location())
+		}, true
+	}
+
+	var monthName, dayStr, yearStr string
+	if m := filterMonthDayRe.FindStringSubmatch(s); m != nil {
+		monthName, dayStr, yearStr = m[1], m[2], m[3]
+	} else if m := filterDayMonthRe.FindStringSubmatch(s); m != nil {
+		dayStr, monthName, yearStr = m[1], m[2], m[3]
+	} else {
+		return nil, false
+	}
+	month, ok := monthFromName(monthName)
+	day, _ := strconv.Atoi(dayStr)
+	if !ok || day < 1 || day > 31 {
+		return nil, false
+	}
+	year, _ := strconv.Atoi(yearStr)
+	return func(now time.Time) time.Time {
+		if year != 0 {
+			return time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+		}
+		d := time.Date(now.Year(), month, day, 0, 0, 0, 0, now.Location())
+		if d.Before(startOfDay(now)) {
+			d = d.AddDate(1, 0, 0)
+		}
+		return d
+	}, true
+}
+
+func monthFromName(name string) (time.Month, bool) {
+	if len(name) < 3 {
+		return 0, false
+	}
+	for m := time.January; m <= time.December; m++ {
+		full := strings.ToLower(m.String())
+		if strings.HasPrefix(full, name) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// parseDay parses the date part of a due or deadline date as a local day.
+func parseDay(date string, loc *time.Location) (time.Time, bool) {
+	if len(date) < 10 {
+		return time.Time{}, false
+	}
+	d, err := time.ParseInLocation("2006-01-02", date[:10], loc)
+	return d, err == nil
+}
+
+// dueDay returns the local day a task is due, if it has a due date. Floating
+// datetimes are read as local; UTC datetimes are converted first.
+func dueDay(due *Due, now time.Time) (time.Time, bool) {
+	if due == nil || due.Date == "" {
+		return time.Time{}, false
+	}
+	if strings.HasSuffix(due.Date, "Z") {
+		for _, layout := range []string{"2006-01-02T15:04:05.000000Z", "2006-01-02T15:04:05Z"} {
+			if t, err := time.Parse(layout, due.Date); err == nil {
+				return startOfDay(t.In(now.Location())), true
+			}
+		}
+	}
+	return parseDay(due.Date, now.Location())
+}
+
+// dueOverdueAt mirrors isOverdue but against an explicit clock.
+func dueOverdueAt(due *Due, now time.Time) bool {
+	if due == nil || due.Date == "" {
+		return false
+	}
+	if len(due.Date) == 10 {
+		day, ok := parseDay(due.Date, now.Location())
+		return ok && day.Before(startOfDay(now))
+	}
+	for _, layout := range []string{
+		"2006-01-02T15:04:05.000000Z",
+		"2006-01-02T15:04:05Z",
+	} {
+		if t, err := time.Parse(layout, due.Date); err == nil {
+			return t.Before(now)
+		}
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05.000000", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, due.Date, now.Location()); err == nil {
+			return t.Before(now)
+		}
+	}
+	return false
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordFilters re-records the filter fixture's expectations from the server:
+//
+//	TODOIST_API_TOKEN=... go test -run TestRecordFilterGolden -record-filters
+//
+// Use a scratch account in the same time zone as the machine running the test.
+// The fixture's projects and tasks are created there, every case is run
+// through /tasks/filter, and everything created is deleted again. Assignments
+// to other users need a shared project, so only the account's own user is
+// assigned; the fixture records what the server then returns.
+var recordFilters = flag.Bool("record-filters", false, "re-record testdata/filters.json against the account in TODOIST_API_TOKEN")
+
+const filterGoldenPath = "testdata/filters.json"
+
+// filterGolden is a fixture of cached state in Sync API shape and the task IDs
+// each filter matches against it, one list per comma-separated sub-query.
+// TestRecordFilterGolden re-records it from the server's /tasks/filter.
+type filterGolden struct {
+	Now      time.Time         `json:"now"`
+	UserID   string            `json:"user_id"`
+	Users    map[string]string `json:"users"`
+	Projects []Project         `json:"projects"`
+	Sections []Section         `json:"sections"`
+	Items    []Task            `json:"items"`
+	Cases    []filterCase      `json:"cases"`
+}
+
+type filterCase struct {
+	Filter string     `json:"filter"`
+	IDs    [][]string `json:"ids,omitempty"`
+	Error  bool       `json:"error,omitempty"`
+}
+
+// readFilterGolden reads the fixture as stored, with Sync API priorities.
+func readFilterGolden(t *testing.T) *filterGolden {
+	t.Helper()
+	data, err := os.ReadFile(filterGoldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g filterGolden
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatal(err)
+	}
+	return &g
+}
+
+func loadFilterGolden(t *testing.T) *filterGolden {
+	t.Helper()
+	g := readFilterGolden(t)
+	for i := range g.Items {
+		g.Items[i].Priority = priorityFromSync(g.Items[i].Priority)
+	}
+	return g
+}
+
+func TestFilterGolden(t *testing.T) {
+	g := loadFilterGolden(t)
+	env := &filterEnv{
+		now:       g.Now,
+		projects:  make(map[string]Project),
+		sections:  make(map[string]Section),
+		userID:    g.UserID,
+		userNames: g.Users,
+	}
+	for _, p := range g.Projects {
+		env.projects[p.ID] = p
+	}
+	for _, s := range g.Sections {
+		env.sections[s.ID] = s
+	}
+
+	for _, tc := range g.Cases {
+		f, err := ParseFilter(tc.Filter)
+		if tc.Error {
+			if err == nil {
+				t.Errorf("ParseFilter(%q) accepted a malformed filter", tc.Filter)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tc.Filter, err)
+			continue
+		}
+		var got [][]string
+		for _, group := range f.Apply(g.Items, env) {
+			ids := []string{}
+			for _, task := range group {
+				ids = append(ids, task.ID)
+			}
+			got = append(got, ids)
+		}
+		if !reflect.DeepEqual(got, tc.IDs) {
+			t.Errorf("%q matched %v, want %v", tc.Filter, got, tc.IDs)
+		}
+	}
+}
+
+func TestFilterApplySkipsCompleted(t *testing.T) {
+	g := loadFilterGolden(t)
+	f, err := ParseFilter("#Work & p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &filterEnv{now: g.Now, projects: map[string]Project{"work": {ID: "work", Name: "Work"}}}
+	done := Task{ID: "t9", ProjectID: "work", Priority: 1, Checked: true}
+	if !f.Match(&done, env) {
+		t.Error("Match rejected a completed task; only Apply hides them")
+	}
+	if groups := f.Apply([]Task{done}, env); len(groups[0]) != 0 {
+		t.Errorf("Apply returned completed tasks: %v", groups[0])
+	}
+}
+
+func TestRecordFilterGolden(t *testing.T) {
+	if !*recordFilters {
+		t.Skip("run with -record-filters to re-record against the server")
+	}
+	token := os.Getenv("TODOIST_API_TOKEN")
+	if token == "" {
+		t.Fatal("-record-filters needs TODOIST_API_TOKEN")
+	}
+	ctx := context.Background()
+	client, syncClient := NewClient(token), NewSyncClient(token)
+	g := readFilterGolden(t)
+
+	me, err := client.GetCurrentUserDirectoryEntry(ctx)
+	if err != nil || me == nil {
+		t.Fatalf("current user: %v", err)
+	}
+	state, err := syncClient.Sync(ctx, fullSyncToken, []string{"projects"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fixture IDs double as temp IDs; the inbox and the fixture's own user
+	// are the account's.
+	toServer := map[string]string{g.UserID: me.ID}
+	for _, p := range state.Projects {
+		if p.InboxProject {
+			for _, fp := range g.Projects {
+				if fp.InboxProject {
+					toServer[fp.ID] = p.ID
+				}
+			}
+		}
+	}
+	onServer := func(fixtureID string) string {
+		if serverID, ok := toServer[fixtureID]; ok {
+			return serverID
+		}
+		return fixtureID
+	}
+	var cmds []SyncCommand
+	add := func(typ, tempID string, args map[string]any) {
+		cmds = append(cmds, SyncCommand{Type: typ, UUID: NewPendingID(), TempID: tempID, Args: args})
+	}
+	for _, p := range g.Projects {
+		if p.InboxProject {
+			continue
+		}
+		args := map[string]any{"name": p.Name}
+		if p.ParentID != nil {
+			args["parent_id"] = onServer(*p.ParentID)
+		}
+		add("project_add", p.ID, args)
+	}
+	for _, sec := range g.Sections {
+		add("section_add", sec.ID, map[string]any{"name": sec.Name, "project_id": onServer(sec.ProjectID)})
+	}
+	for _, task := range g.Items {
+		args := map[string]any{"content": task.Content, "project_id": onServer(task.ProjectID), "priority": task.Priority, "labels": task.Labels}
+		if task.SectionID != "" {
+			args["section_id"] = onServer(task.SectionID)
+		}
+		if task.ParentID != nil {
+			args["parent_id"] = onServer(*task.ParentID)
+		}
+		if task.ResponsibleUID != nil && *task.ResponsibleUID == g.UserID {
+			args["responsible_uid"] = me.ID
+		}
+		// The server resolves due strings against its own today, which is
+		// what the recorded filters run against too.
+		if task.Due != nil {
+			args["due"] = map[string]any{"string": task.Due.String}
+		}
+		if task.Deadline != nil {
+			args["deadline"] = map[string]any{"date": task.Deadline.Date}
+		}
+		add("item_add", task.ID, args)
+		if task.Checked {
+			add("item_complete", "", map[string]any{"id": task.ID})
+		}
+	}
+
+	now := time.Now()
+	resp, err := syncClient.SyncWithCommands(ctx, fullSyncToken, []string{"projects", "items", "sections"}, cmds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cleanup []SyncCommand
+	for _, p := range g.Projects {
+		if serverID, ok := resp.TempIDMap[p.ID]; ok && p.ParentID == nil {
+			cleanup = append(cleanup, SyncCommand{Type: "project_delete", UUID: NewPendingID(), Args: map[string]any{"id": serverID}})
+		}
+	}
+	for _, task := range g.Items {
+		if serverID, ok := resp.TempIDMap[task.ID]; ok && onServer(task.ProjectID) != task.ProjectID {
+			cleanup = append(cleanup, SyncCommand{Type: "item_delete", UUID: NewPendingID(), Args: map[string]any{"id": serverID}})
+		}
+	}
+	t.Cleanup(func() {
+		if _, err := syncClient.SyncWithCommands(ctx, "", nil, cleanup); err != nil {
+			t.Errorf("cleanup: %v", err)
+		}
+	})
+	for _, cmd := range cmds {
+		if cmdErr, ok := resp.CommandResult(cmd.UUID); !ok || cmdErr != nil {
+			t.Fatalf("%s %v: %v", cmd.Type, cmd.Args, cmdErr)
+		}
+	}
+	for fixtureID, serverID := range resp.TempIDMap {
+		toServer[fixtureID] = serverID
+	}
+	toFixture := make(map[string]string, len(toServer))
+	for fixtureID, serverID := range toServer {
+		toFixture[serverID] = fixtureID
+	}
+	fixtureID := func(serverID *string) *string {
+		if serverID == nil {
+			return nil
+		}
+		if id, ok := toFixture[*serverID]; ok {
+			return &id
+		}
+		return serverID
+	}
+
+	// Keep the server's copies of the tasks, back under their fixture IDs;
+	// completed ones are not in the sync and keep the fixture copy.
+	server := make(map[string]Task, len(resp.Items))
+	for _, task := range resp.Items {
+		if id, ok := toFixture[task.ID]; ok {
+			task.ID, task.UserID = id, ""
+			task.ProjectID = *fixtureID(&task.ProjectID)
+			task.SectionID = *fixtureID(&task.SectionID)
+			task.ParentID = fixtureID(task.ParentID)
+			task.ResponsibleUID = fixtureID(task.ResponsibleUID)
+			task.AssignedByUID = fixtureID(task.AssignedByUID)
+			task.AddedAt, task.ChildOrder = "", 0
+			server[id] = task
+		}
+	}
+	order := make([]string, len(g.Items))
+	for i, task := range g.Items {
+		order[i] = task.ID
+		if recorded, ok := server[task.ID]; ok {
+			g.Items[i] = recorded
+		}
+	}
+
+	for i, c := range g.Cases {
+		g.Cases[i] = filterCase{Filter: c.Filter}
+		for _, query := range splitFilterQueries(c.Filter) {
+			ids, err := serverFilter(ctx, client, query, toFixture)
+			if apiErrorStatus(err) == http.StatusBadRequest {
+				g.Cases[i] = filterCase{Filter: c.Filter, Error: true}
+				break
+			}
+			if err != nil {
+				t.Fatalf("%q: %v", query, err)
+			}
+			slices.SortFunc(ids, func(a, b string) int { return slices.Index(order, a) - slices.Index(order, b) })
+			g.Cases[i].IDs = append(g.Cases[i].IDs, ids)
+		}
+	}
+
+	g.Now = now.Truncate(time.Second)
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filterGoldenPath, append(data, '\n'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// splitFilterQueries splits a query at its top-level commas, which the
+// server answers as one combined list.
+func splitFilterQueries(query string) []string {
+	toks, err := lexFilter(query)
+	if err != nil {
+		return []string{query}
+	}
+	var queries []string
+	start := 0
+	for _, tok := range toks {
+		if tok.kind == filterTokComma || tok.kind == filterTokEOF {
+			queries = append(queries, strings.TrimSpace(query[start:tok.start]))
+			start = tok.end
+		}
+	}
+	return queries
+}
+
+// serverFilter returns the fixture IDs of the tasks the server matches for
+// query, leaving out any task the recording didn't create.
+func serverFilter(ctx context.Context, client *Client, query string, toFixture map[string]string) ([]string, error) {
+	ids := []string{}
+	var cursor *string
+	for {
+		path := "/tasks/filter?limit=200&query=" + url.QueryEscape(query)
+		if cursor != nil {
+			path += "&cursor=" + url.QueryEscape(*cursor)
+		}
+		data, err := client.doRequest(ctx, "GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var resp PaginatedResponse[Task]
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("decode tasks: %w", err)
+		}
+		for _, task := range resp.Results {
+			if id, ok := toFixture[task.ID]; ok {
+				ids = append(ids, id)
+			}
+		}
+		if resp.NextCursor == nil || *resp.NextCursor == "" {
+			return ids, nil
+		}
+		cursor = resp.NextCursor
+	}
+}
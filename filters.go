@@ -0,0 +1,458 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FiltersView is the saved filter browser overlay: lists filters with their
+// queries and manages create, edit, recolor, favorite, reorder and delete.
+type FiltersView struct {
+	repo         *Repository
+	filters      []SavedFilter
+	cursor       int
+	scrollOffset int
+	height       int
+
+	// Dialog state
+	mode        string // "", "add", "edit", "color", "delete"
+	nameInput   textinput.Model
+	queryInput  textinput.Model
+	colorCursor int
+}
+
+func NewFiltersView(repo *Repository) FiltersView {
+	ni := textinput.New()
+	ni.Placeholder = "Filter name..."
+	ni.CharLimit = 60
+
+	qi := textinput.New()
+	qi.Placeholder = "(today | overdue) & #Work & p1"
+	qi.CharLimit = 1024
+
+	return FiltersView{repo: repo, nameInput: ni, queryInput: qi}
+}
+
+// Refresh reloads saved filters from cache.
+func (v *FiltersView) Refresh() {
+	v.filters = v.repo.GetCachedFilters()
+	if v.cursor >= len(v.filters) {
+		v.cursor = len(v.filters) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
+func (v *FiltersView) SetSize(width, height int) {
+	v.height = height
+	v.nameInput.Width = width - 12
+	v.queryInput.Width = width - 12
+	v.ensureVisible()
+}
+
+func (v *FiltersView) ensureVisible() {
+	visibleHeight := v.height - 8
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+	listEnsureVisible(v.cursor, &v.scrollOffset, visibleHeight)
+}
+
+func (v FiltersView) handlesInput() bool {
+	return v.mode != ""
+}
+
+// inputContext returns the key context for the active dialog.
+func (v FiltersView) inputContext() InputContext {
+	switch v.mode {
+	case "":
+		return ContextFiltersOverlay
+	case "delete":
+		return ContextMainSidebarDialog
+	case "color":
+		return ContextMainTasksSearch
+	default:
+		return ContextFiltersDialog
+	}
+}
+
+func (v FiltersView) selectedFilter() *SavedFilter {
+	if v.cursor < 0 || v.cursor >= len(v.filters) {
+		return nil
+	}
+	return &v.filters[v.cursor]
+}
+
+func (v *FiltersView) selectByID(id string) {
+	for i, f := range v.filters {
+		if f.ID == id {
+			v.cursor = i
+		}
+	}
+	v.ensureVisible()
+}
+
+func (v FiltersView) Update(msg tea.Msg) (FiltersView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case filterCreatedMsg:
+		if msg.err != nil {
+			return v, func() tea.Msg {
+				return toastMsg{text: "Failed to create filter: " + msg.err.Error(), isError: true}
+			}
+		}
+		v.Refresh()
+		v.selectByID(msg.filter.ID)
+		return v, nil
+
+	case filterUpdatedMsg:
+		if msg.err != nil {
+			return v, func() tea.Msg {
+				return toastMsg{text: "Failed to update filter: " + msg.err.Error(), isError: true}
+			}
+		}
+		v.Refresh()
+		v.selectByID(msg.filter.ID)
+		return v, nil
+
+	case filterDeletedMsg:
+		if msg.err != nil {
+			return v, func() tea.Msg {
+				return toastMsg{text: "Failed to delete filter: " + msg.err.Error(), isError: true}
+			}
+		}
+		v.Refresh()
+		return v, nil
+
+	case tea.MouseMsg:
+		m := tea.MouseEvent(msg)
+		if v.mode != "" {
+			return v, nil
+		}
+		switch m.Button {
+		case tea.MouseButtonWheelDown:
+			if v.cursor < len(v.filters)-1 {
+				v.cursor++
+			}
+			v.ensureVisible()
+		case tea.MouseButtonWheelUp:
+			if v.cursor > 0 {
+				v.cursor--
+			}
+			v.ensureVisible()
+		case tea.MouseButtonLeft:
+			// helpStyle has Padding(1,2): 1 row top pad, then title, MarginBottom(1), blank line
+			idx := v.scrollOffset + m.Y - 4
+			if m.Y >= 4 && idx < len(v.filters) {
+				v.cursor = idx
+			}
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.mode != "" {
+			return v.handleDialogKey(msg)
+		}
+		switch ResolveAction(ContextFiltersOverlay, msg.String()) {
+		case ActionNavDown:
+			if v.cursor < len(v.filters)-1 {
+				v.cursor++
+			}
+			v.ensureVisible()
+		case ActionNavUp:
+			if v.cursor > 0 {
+				v.cursor--
+			}
+			v.ensureVisible()
+		case ActionNavTop:
+			v.cursor = 0
+			v.ensureVisible()
+		case ActionNavBottom:
+			v.cursor = max(len(v.filters)-1, 0)
+			v.ensureVisible()
+		case ActionAddFilter:
+			v.mode = "add"
+			v.nameInput.Reset()
+			v.queryInput.Reset()
+			v.queryInput.Blur()
+			v.nameInput.Focus()
+			return v, textinput.Blink
+		case ActionEditFilter:
+			if f := v.selectedFilter(); f != nil {
+				v.mode = "edit"
+				v.nameInput.SetValue(f.Name)
+				v.nameInput.CursorEnd()
+				v.queryInput.SetValue(f.Query)
+				v.queryInput.CursorEnd()
+				v.nameInput.Blur()
+				v.queryInput.Focus()
+				return v, textinput.Blink
+			}
+		case ActionRecolorFilter:
+			if f := v.selectedFilter(); f != nil {
+				v.mode = "color"
+				v.colorCursor = 0
+				for i, c := range todoistColors {
+					if c == f.Color {
+						v.colorCursor = i
+					}
+				}
+			}
+		case ActionToggleFavorite:
+			if f := v.selectedFilter(); f != nil {
+				fav := !f.IsFavorite
+				return v, v.repo.UpdateFilter(f.ID, filterPayload{IsFavorite: &fav})
+			}
+		case ActionDeleteFilter:
+			if v.selectedFilter() != nil {
+				v.mode = "delete"
+			}
+		case ActionMoveDown:
+			if f := v.selectedFilter(); f != nil {
+				return v, v.repo.MoveFilter(f.ID, 1)
+			}
+		case ActionMoveUp:
+			if f := v.selectedFilter(); f != nil {
+				return v, v.repo.MoveFilter(f.ID, -1)
+			}
+		}
+		return v, nil
+	}
+
+	if v.mode == "add" || v.mode == "edit" {
+		var cmd tea.Cmd
+		if v.queryInput.Focused() {
+			v.queryInput, cmd = v.queryInput.Update(msg)
+		} else {
+			v.nameInput, cmd = v.nameInput.Update(msg)
+		}
+		return v, cmd
+	}
+	return v, nil
+}
+
+func (v FiltersView) handleDialogKey(msg tea.KeyMsg) (FiltersView, tea.Cmd) {
+	switch v.mode {
+	case "add", "edit":
+		switch ResolveAction(ContextFiltersDialog, msg.String()) {
+		case ActionToggleFocus:
+			if v.nameInput.Focused() {
+				v.nameInput.Blur()
+				v.queryInput.Focus()
+			} else {
+				v.queryInput.Blur()
+				v.nameInput.Focus()
+			}
+			return v, textinput.Blink
+		case ActionConfirm:
+			name := strings.TrimSpace(v.nameInput.Value())
+			query := strings.TrimSpace(v.queryInput.Value())
+			if name == "" || query == "" {
+				// Move to whichever field still needs a value.
+				v.nameInput.Blur()
+				v.queryInput.Blur()
+				if name == "" {
+					v.nameInput.Focus()
+				} else {
+					v.queryInput.Focus()
+				}
+				return v, textinput.Blink
+			}
+			mode := v.mode
+			v.mode = ""
+			v.nameInput.Blur()
+			v.queryInput.Blur()
+			if mode == "add" {
+				return v, v.repo.CreateFilter(filterPayload{Name: name, Query: query, Color: "charcoal"})
+			}
+			f := v.selectedFilter()
+			if f == nil || (name == f.Name && query == f.Query) {
+				return v, nil
+			}
+			req := filterPayload{}
+			if name != f.Name {
+				req.Name = name
+			}
+			if query != f.Query {
+				req.Query = query
+			}
+			return v, v.repo.UpdateFilter(f.ID, req)
+		case ActionCancel:
+			v.mode = ""
+			v.nameInput.Blur()
+			v.queryInput.Blur()
+			return v, nil
+		}
+		var cmd tea.Cmd
+		if v.queryInput.Focused() {
+			v.queryInput, cmd = v.queryInput.Update(msg)
+		} else {
+			v.nameInput, cmd = v.nameInput.Update(msg)
+		}
+		return v, cmd
+
+	case "color":
+		switch ResolveAction(ContextFiltersOverlay, msg.String()) {
+		case ActionNavDown:
+			if v.colorCursor < len(todoistColors)-1 {
+				v.colorCursor++
+			}
+			return v, nil
+		case ActionNavUp:
+			if v.colorCursor > 0 {
+				v.colorCursor--
+			}
+			return v, nil
+		}
+		switch ResolveAction(ContextMainTasksSearch, msg.String()) {
+		case ActionConfirm:
+			v.mode = ""
+			color := todoistColors[v.colorCursor]
+			if f := v.selectedFilter(); f != nil && color != f.Color {
+				return v, v.repo.UpdateFilter(f.ID, filterPayload{Color: color})
+			}
+		case ActionCancel:
+			v.mode = ""
+		}
+		return v, nil
+
+	case "delete":
+		switch ResolveAction(ContextMainSidebarDialog, msg.String()) {
+		case ActionConfirm:
+			v.mode = ""
+			if f := v.selectedFilter(); f != nil {
+				return v, v.repo.DeleteFilter(f.ID)
+			}
+		case ActionCancel:
+			v.mode = ""
+		}
+		return v, nil
+	}
+	return v, nil
+}
+
+func (v FiltersView) View(width, height int) string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().
+		Foreground(colorBlue).
+		Bold(true).
+		MarginBottom(1).
+		Render("Filters"))
+	b.WriteString("\n\n")
+
+	if len(v.filters) == 0 && v.mode != "add" {
+		b.WriteString(emptyStyle.Render("No saved filters — press n to create one"))
+		b.WriteString("\n\n")
+		b.WriteString(footerKeyStyle.Render("n") + " new  " +
+			footerKeyStyle.Render("F") + " " + footerDescStyle.Render("close"))
+		return helpStyle.Width(width).Height(height).Render(b.String())
+	}
+
+	visibleHeight := height - 8
+	if v.mode != "" {
+		visibleHeight -= 6
+	}
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+	end := v.scrollOffset + visibleHeight
+	if end > len(v.filters) {
+		end = len(v.filters)
+	}
+
+	syncStatus := v.repo.FilterMutationStatusMap()
+	queryWidth := max(width-44, 10)
+	for i := v.scrollOffset; i < end; i++ {
+		f := v.filters[i]
+		fav := " "
+		if f.IsFavorite {
+			fav = "★"
+		}
+		query := truncate(f.Query, queryWidth)
+		if i == v.cursor {
+			// Plain text avoids inner ANSI resets breaking the selection background
+			line := fmt.Sprintf("● %-24s %s %s  %s", truncate(f.Name, 24), fav, query, mutationBadgePlain(syncStatus[f.ID]))
+			b.WriteString(queueSelectedStyle.Width(width - 4).Render(line))
+		} else {
+			line := lipgloss.NewStyle().Foreground(projectColor(f.Color)).Render("●") + " " +
+				taskContentStyle.Render(fmt.Sprintf("%-24s", truncate(f.Name, 24))) + " " +
+				projectFavStyle.Render(fav) + " " +
+				subtaskCountStyle.Render(query)
+			if _, err := ParseFilter(f.Query); err != nil {
+				line += " " + queueConflictStyle.Render("⚠")
+			}
+			if badge := mutationBadgeStyled(syncStatus[f.ID]); badge != "" {
+				line += "  " + badge
+			}
+			b.WriteString(queueItemStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	switch v.mode {
+	case "add", "edit":
+		title := "New Filter"
+		if v.mode == "edit" {
+			title = "Edit Filter"
+		}
+		b.WriteString("\n")
+		b.WriteString(dialogTitleStyle.Render(title) + "\n")
+		b.WriteString(inputLabelStyle.Render("Name  ") + v.nameInput.View() + "\n")
+		b.WriteString(inputLabelStyle.Render("Query ") + v.queryInput.View() + "\n")
+		if q := strings.TrimSpace(v.queryInput.Value()); q != "" {
+			if _, err := ParseFilter(q); err != nil {
+				b.WriteString(queueConflictStyle.Render("⚠ "+err.Error()+" — the server may still accept it") + "\n")
+			}
+		}
+	case "color":
+		b.WriteString("\n")
+		b.WriteString(dialogTitleStyle.Render("Filter Color") + "\n")
+		var swatches []string
+		for i, c := range todoistColors {
+			dot := lipgloss.NewStyle().Foreground(projectColor(c)).Render("●")
+			if i == v.colorCursor {
+				dot = lipgloss.NewStyle().Foreground(projectColor(c)).Underline(true).Render("◉")
+			}
+			swatches = append(swatches, dot)
+		}
+		b.WriteString(strings.Join(swatches, " ") + "  " + inputLabelStyle.Render(todoistColors[v.colorCursor]) + "\n")
+	case "delete":
+		b.WriteString("\n")
+		b.WriteString(dialogTitleStyle.Render("Delete Filter?") + "\n")
+		if f := v.selectedFilter(); f != nil {
+			b.WriteString(taskContentStyle.Render(f.Name) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	switch v.mode {
+	case "":
+		b.WriteString(footerKeyStyle.Render("j/k") + " nav  " +
+			footerKeyStyle.Render("enter") + " open  " +
+			footerKeyStyle.Render("n") + " new  " +
+			footerKeyStyle.Render("e") + " edit  " +
+			footerKeyStyle.Render("c") + " color  " +
+			footerKeyStyle.Render("f") + " favorite  " +
+			footerKeyStyle.Render("J/K") + " reorder  " +
+			footerKeyStyle.Render("d") + " delete  " +
+			footerKeyStyle.Render("F") + " close")
+	case "color":
+		b.WriteString(footerKeyStyle.Render("j/k") + " pick  " +
+			footerKeyStyle.Render("enter") + " save  " +
+			footerKeyStyle.Render("esc") + " cancel")
+	case "delete":
+		b.WriteString(footerKeyStyle.Render("y") + " confirm  " +
+			footerKeyStyle.Render("n") + " cancel")
+	default:
+		b.WriteString(footerKeyStyle.Render("tab") + " next field  " +
+			footerKeyStyle.Render("enter") + " save  " +
+			footerKeyStyle.Render("esc") + " cancel")
+	}
+
+	return helpStyle.Width(width).Height(height).Render(b.String())
+}
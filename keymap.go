@@ -49,6 +49,41 @@ const (
 	ActionClearAll
 	ActionUnarchive
 	ActionSearchCreate
+	ActionToggleCollapse
+	ActionIndent
+	ActionOutdent
+	ActionOpenDetail
+	ActionAddComment
+	ActionEditComment
+	ActionDeleteComment
+	ActionNextComment
+	ActionPrevComment
+	ActionOpenLabels
+	ActionAddLabel
+	ActionRenameLabel
+	ActionRecolorLabel
+	ActionDeleteLabel
+	ActionCompleteLabel
+	ActionOpenFilters
+	ActionAddFilter
+	ActionEditFilter
+	ActionRecolorFilter
+	ActionToggleFavorite
+	ActionDeleteFilter
+	ActionMoveDown
+	ActionMoveUp
+	ActionAddSection
+	ActionArchiveSection
+	ActionMoveTask
+	ActionToggleMark
+	ActionMarkRange
+	ActionUndo
+	ActionRedo
+	ActionResolveConflict
+	ActionTakeMine
+	ActionTakeTheirs
+	ActionEditField
+	ActionCopyJSON
 )
 
 // InputContext defines where key input is currently routed.
@@ -66,9 +101,15 @@ const (
 	ContextHelp
 	ContextSearchOverlay
 	ContextQueueOverlay
+	ContextQueueResolve
 	ContextCompletedOverlay
 	ContextTriageOverlay
 	ContextTriageDialog
+	ContextDetailOverlay
+	ContextDetailInput
+	ContextLabelsOverlay
+	ContextFiltersOverlay
+	ContextFiltersDialog
 )
 
 type KeyBinding struct {
@@ -96,10 +137,20 @@ var contextBindings = map[InputContext][]KeyBinding{
 		{Action: ActionNavDown, Keys: []string{"j", "down"}, Hint: "j/k", Desc: "nav"},
 		{Action: ActionNavUp, Keys: []string{"k", "up"}, Hint: "j/k", Desc: "nav"},
 		{Action: ActionRetry, Keys: []string{"r"}, Hint: "r", Desc: "retry"},
+		{Action: ActionResolveConflict, Keys: []string{"enter"}, Hint: "enter", Desc: "resolve"},
 		{Action: ActionDismiss, Keys: []string{"d"}, Hint: "d", Desc: "dismiss"},
 		{Action: ActionClearConflicts, Keys: []string{"x"}, Hint: "x", Desc: "clear conflicts"},
 		{Action: ActionClearAll, Keys: []string{"X"}, Hint: "X", Desc: "clear all"},
 	},
+	ContextQueueResolve: {
+		{Action: ActionNavDown, Keys: []string{"j", "down"}, Hint: "j/k", Desc: "field"},
+		{Action: ActionNavUp, Keys: []string{"k", "up"}, Hint: "j/k", Desc: "field"},
+		{Action: ActionTakeMine, Keys: []string{"m"}, Hint: "m", Desc: "mine"},
+		{Action: ActionTakeTheirs, Keys: []string{"t"}, Hint: "t", Desc: "theirs"},
+		{Action: ActionEditField, Keys: []string{"e"}, Hint: "e", Desc: "edit"},
+		{Action: ActionConfirm, Keys: []string{"enter"}, Hint: "enter", Desc: "apply"},
+		{Action: ActionCancel, Keys: []string{"esc"}, Hint: "esc", Desc: "cancel"},
+	},
 	ContextCompletedOverlay: {
 		{Action: ActionCancel, Keys: []string{"C", "esc"}, Hint: "C", Desc: "close"},
 		{Action: ActionOpenActions, Keys: []string{"."}, Hint: ".", Desc: "actions"},
@@ -127,11 +178,64 @@ var contextBindings = map[InputContext][]KeyBinding{
 		{Action: ActionEditTask, Keys: []string{"e"}, Hint: "e", Desc: "edit"},
 		{Action: ActionSetLabels, Keys: []string{"l"}, Hint: "l", Desc: "labels"},
 		{Action: ActionNewTask, Keys: []string{"n"}, Hint: "n", Desc: "new"},
-		{Action: ActionMarkReviewed, Keys: []string{"enter", " "}, Hint: "enter", Desc: "skip"},
+		{Action: ActionOpenDetail, Keys: []string{"enter"}, Hint: "enter", Desc: "details"},
+		{Action: ActionMarkReviewed, Keys: []string{" "}, Hint: "space", Desc: "skip"},
+		{Action: ActionToggleMark, Keys: []string{"v"}, Hint: "v/V", Desc: "mark"},
+		{Action: ActionMarkRange, Keys: []string{"V"}, Hint: "v/V", Desc: "mark range"},
+	},
+	ContextDetailOverlay: {
+		{Action: ActionCancel, Keys: []string{"esc"}, Hint: "esc", Desc: "close"},
+		{Action: ActionOpenActions, Keys: []string{"."}, Hint: ".", Desc: "actions"},
+		{Action: ActionNavDown, Keys: []string{"j", "down"}, Hint: "j/k", Desc: "scroll"},
+		{Action: ActionNavUp, Keys: []string{"k", "up"}, Hint: "j/k", Desc: "scroll"},
+		{Action: ActionEditTask, Keys: []string{"e"}, Hint: "e", Desc: "edit description"},
+		{Action: ActionAddComment, Keys: []string{"c"}, Hint: "c", Desc: "comment"},
+		{Action: ActionNextComment, Keys: []string{"J"}, Hint: "J/K", Desc: "select comment"},
+		{Action: ActionPrevComment, Keys: []string{"K"}, Hint: "J/K", Desc: "select comment"},
+		{Action: ActionEditComment, Keys: []string{"E"}, Hint: "E", Desc: "edit comment"},
+		{Action: ActionDeleteComment, Keys: []string{"D"}, Hint: "D", Desc: "delete comment"},
+	},
+	ContextLabelsOverlay: {
+		{Action: ActionCancel, Keys: []string{"L", "esc"}, Hint: "L", Desc: "close"},
+		{Action: ActionOpenActions, Keys: []string{"."}, Hint: ".", Desc: "actions"},
+		{Action: ActionNavDown, Keys: []string{"j", "down"}, Hint: "j/k", Desc: "nav"},
+		{Action: ActionNavUp, Keys: []string{"k", "up"}, Hint: "j/k", Desc: "nav"},
+		{Action: ActionNavTop, Keys: []string{"g"}, Hint: "g", Desc: "top"},
+		{Action: ActionNavBottom, Keys: []string{"G"}, Hint: "G", Desc: "bottom"},
+		{Action: ActionAddLabel, Keys: []string{"n", "a"}, Hint: "n", Desc: "new label"},
+		{Action: ActionRenameLabel, Keys: []string{"r", "e"}, Hint: "r", Desc: "rename"},
+		{Action: ActionRecolorLabel, Keys: []string{"c"}, Hint: "c", Desc: "color"},
+		{Action: ActionDeleteLabel, Keys: []string{"d"}, Hint: "d", Desc: "delete"},
+	},
+	ContextFiltersOverlay: {
+		{Action: ActionCancel, Keys: []string{"F", "esc"}, Hint: "F", Desc: "close"},
+		{Action: ActionOpenActions, Keys: []string{"."}, Hint: ".", Desc: "actions"},
+		{Action: ActionNavDown, Keys: []string{"j", "down"}, Hint: "j/k", Desc: "nav"},
+		{Action: ActionNavUp, Keys: []string{"k", "up"}, Hint: "j/k", Desc: "nav"},
+		{Action: ActionNavTop, Keys: []string{"g"}, Hint: "g", Desc: "top"},
+		{Action: ActionNavBottom, Keys: []string{"G"}, Hint: "G", Desc: "bottom"},
+		{Action: ActionConfirm, Keys: []string{"enter"}, Hint: "enter", Desc: "open"},
+		{Action: ActionAddFilter, Keys: []string{"n", "a"}, Hint: "n", Desc: "new filter"},
+		{Action: ActionEditFilter, Keys: []string{"e", "r"}, Hint: "e", Desc: "edit"},
+		{Action: ActionRecolorFilter, Keys: []string{"c"}, Hint: "c", Desc: "color"},
+		{Action: ActionToggleFavorite, Keys: []string{"f"}, Hint: "f", Desc: "favorite"},
+		{Action: ActionDeleteFilter, Keys: []string{"d"}, Hint: "d", Desc: "delete"},
+		{Action: ActionMoveDown, Keys: []string{"J"}, Hint: "J/K", Desc: "reorder"},
+		{Action: ActionMoveUp, Keys: []string{"K"}, Hint: "J/K", Desc: "reorder"},
+	},
+	ContextFiltersDialog: {
+		{Action: ActionConfirm, Keys: []string{"enter"}, Hint: "enter", Desc: "save"},
+		{Action: ActionToggleFocus, Keys: []string{"tab", "shift+tab"}, Hint: "tab", Desc: "next field"},
+		{Action: ActionCancel, Keys: []string{"esc"}, Hint: "esc", Desc: "cancel"},
+	},
+	ContextDetailInput: {
+		{Action: ActionConfirm, Keys: []string{"ctrl+s"}, Hint: "ctrl+s", Desc: "save"},
+		{Action: ActionCancel, Keys: []string{"esc"}, Hint: "esc", Desc: "cancel"},
 	},
 	ContextTriageDialog: {
 		{Action: ActionConfirm, Keys: []string{"enter"}, Hint: "enter", Desc: "confirm"},
-		{Action: ActionCancel, Keys: []string{"esc", "n"}, Hint: "esc", Desc: "cancel"},
+		{Action: ActionCompleteLabel, Keys: []string{"tab"}, Hint: "tab", Desc: "complete label"},
+		{Action: ActionCancel, Keys: []string{"esc"}, Hint: "esc", Desc: "cancel"},
 	},
 	ContextMainSidebar: {
 		{Action: ActionQuit, Keys: []string{"q", "ctrl+c"}, Hint: "q", Desc: "quit"},
@@ -139,8 +243,12 @@ var contextBindings = map[InputContext][]KeyBinding{
 		{Action: ActionToggleHelp, Keys: []string{"?"}, Hint: "?", Desc: "help"},
 		{Action: ActionOpenSearch, Keys: []string{"ctrl+p", "alt+p"}, Hint: "^P", Desc: "search"},
 		{Action: ActionOpenQueue, Keys: []string{"Q"}, Hint: "Q", Desc: "queue"},
+		{Action: ActionUndo, Keys: []string{"u"}, Hint: "u", Desc: "undo"},
+		{Action: ActionRedo, Keys: []string{"ctrl+r"}, Hint: "^R", Desc: "redo"},
 		{Action: ActionOpenCompleted, Keys: []string{"C"}, Hint: "C", Desc: "completed"},
 		{Action: ActionOpenTriage, Keys: []string{"T"}, Hint: "T", Desc: "triage"},
+		{Action: ActionOpenLabels, Keys: []string{"L"}, Hint: "L", Desc: "labels"},
+		{Action: ActionOpenFilters, Keys: []string{"F"}, Hint: "F", Desc: "filters"},
 		{Action: ActionToggleFocus, Keys: []string{"tab"}, Hint: "tab", Desc: "tasks"},
 		{Action: ActionFocusTasks, Keys: []string{"enter"}, Hint: "enter", Desc: "tasks"},
 		{Action: ActionNavDown, Keys: []string{"j", "down"}, Hint: "j/k", Desc: "nav"},
@@ -150,6 +258,11 @@ var contextBindings = map[InputContext][]KeyBinding{
 		{Action: ActionNewTask, Keys: []string{"n"}, Hint: "n", Desc: "new task"},
 		{Action: ActionAddProject, Keys: []string{"a"}, Hint: "a", Desc: "add list"},
 		{Action: ActionArchiveProject, Keys: []string{"d"}, Hint: "d", Desc: "archive"},
+		{Action: ActionToggleCollapse, Keys: []string{"z"}, Hint: "z", Desc: "fold"},
+		{Action: ActionIndent, Keys: []string{">"}, Hint: ">/<", Desc: "nest"},
+		{Action: ActionOutdent, Keys: []string{"<"}, Hint: ">/<", Desc: "unnest"},
+		{Action: ActionMoveDown, Keys: []string{"J"}, Hint: "J/K", Desc: "reorder"},
+		{Action: ActionMoveUp, Keys: []string{"K"}, Hint: "J/K", Desc: "reorder"},
 		{Action: ActionRefresh, Keys: []string{"r"}, Hint: "r", Desc: "refresh"},
 	},
 	ContextMainSidebarDialog: {
@@ -162,8 +275,12 @@ var contextBindings = map[InputContext][]KeyBinding{
 		{Action: ActionToggleHelp, Keys: []string{"?"}, Hint: "?", Desc: "help"},
 		{Action: ActionOpenSearch, Keys: []string{"ctrl+p", "alt+p"}, Hint: "^P", Desc: "search all"},
 		{Action: ActionOpenQueue, Keys: []string{"Q"}, Hint: "Q", Desc: "queue"},
+		{Action: ActionUndo, Keys: []string{"u"}, Hint: "u", Desc: "undo"},
+		{Action: ActionRedo, Keys: []string{"ctrl+r"}, Hint: "^R", Desc: "redo"},
 		{Action: ActionOpenCompleted, Keys: []string{"C"}, Hint: "C", Desc: "completed"},
 		{Action: ActionOpenTriage, Keys: []string{"T"}, Hint: "T", Desc: "triage"},
+		{Action: ActionOpenLabels, Keys: []string{"L"}, Hint: "L", Desc: "labels"},
+		{Action: ActionOpenFilters, Keys: []string{"F"}, Hint: "F", Desc: "filters"},
 		{Action: ActionToggleFocus, Keys: []string{"tab"}, Hint: "tab", Desc: "projects"},
 		{Action: ActionNavDown, Keys: []string{"j", "down"}, Hint: "j/k", Desc: "nav"},
 		{Action: ActionNavUp, Keys: []string{"k", "up"}, Hint: "j/k", Desc: "nav"},
@@ -172,10 +289,23 @@ var contextBindings = map[InputContext][]KeyBinding{
 		{Action: ActionToggleDone, Keys: []string{"x", " "}, Hint: "x/space", Desc: "toggle"},
 		{Action: ActionNewTask, Keys: []string{"n"}, Hint: "n", Desc: "new"},
 		{Action: ActionEditTask, Keys: []string{"e"}, Hint: "e", Desc: "edit"},
+		{Action: ActionOpenDetail, Keys: []string{"enter"}, Hint: "enter", Desc: "details"},
 		{Action: ActionSetDue, Keys: []string{"s"}, Hint: "s", Desc: "due"},
 		{Action: ActionSetDeadline, Keys: []string{"S"}, Hint: "S", Desc: "deadline"},
 		{Action: ActionClearDates, Keys: []string{"-"}, Hint: "-", Desc: "clear dates"},
 		{Action: ActionDeleteTask, Keys: []string{"d"}, Hint: "d", Desc: "del"},
+		{Action: ActionToggleCollapse, Keys: []string{"z"}, Hint: "z", Desc: "fold"},
+		{Action: ActionIndent, Keys: []string{">"}, Hint: ">/<", Desc: "indent"},
+		{Action: ActionOutdent, Keys: []string{"<"}, Hint: ">/<", Desc: "outdent"},
+		{Action: ActionMoveDown, Keys: []string{"J"}, Hint: "J/K", Desc: "reorder"},
+		{Action: ActionMoveUp, Keys: []string{"K"}, Hint: "J/K", Desc: "reorder"},
+		{Action: ActionAddSection, Keys: []string{"A"}, Hint: "A", Desc: "new section"},
+		{Action: ActionArchiveSection, Keys: []string{"a"}, Hint: "a", Desc: "archive section"},
+		{Action: ActionMoveTask, Keys: []string{"m"}, Hint: "m", Desc: "move"},
+		{Action: ActionSetLabels, Keys: []string{"l"}, Hint: "l", Desc: "labels"},
+		{Action: ActionCopyJSON, Keys: []string{"y"}, Hint: "y", Desc: "copy JSON"},
+		{Action: ActionToggleMark, Keys: []string{"v"}, Hint: "v/V", Desc: "mark"},
+		{Action: ActionMarkRange, Keys: []string{"V"}, Hint: "v/V", Desc: "mark range"},
 		{Action: ActionSetPriority1, Keys: []string{"1"}, Hint: "1-4", Desc: "prio"},
 		{Action: ActionSetPriority2, Keys: []string{"2"}, Hint: "1-4", Desc: "prio"},
 		{Action: ActionSetPriority3, Keys: []string{"3"}, Hint: "1-4", Desc: "prio"},
@@ -192,6 +322,7 @@ var contextBindings = map[InputContext][]KeyBinding{
 	},
 	ContextMainTasksSearch: {
 		{Action: ActionConfirm, Keys: []string{"enter"}, Hint: "enter", Desc: "search"},
+		{Action: ActionCompleteLabel, Keys: []string{"tab"}, Hint: "tab", Desc: "complete label"},
 		{Action: ActionCancel, Keys: []string{"esc"}, Hint: "esc", Desc: "cancel"},
 	},
 	ContextMainToday: {
@@ -200,17 +331,32 @@ var contextBindings = map[InputContext][]KeyBinding{
 		{Action: ActionToggleHelp, Keys: []string{"?"}, Hint: "?", Desc: "help"},
 		{Action: ActionOpenSearch, Keys: []string{"ctrl+p", "alt+p"}, Hint: "^P", Desc: "search all"},
 		{Action: ActionOpenQueue, Keys: []string{"Q"}, Hint: "Q", Desc: "queue"},
+		{Action: ActionUndo, Keys: []string{"u"}, Hint: "u", Desc: "undo"},
+		{Action: ActionRedo, Keys: []string{"ctrl+r"}, Hint: "^R", Desc: "redo"},
 		{Action: ActionOpenCompleted, Keys: []string{"C"}, Hint: "C", Desc: "completed"},
 		{Action: ActionOpenTriage, Keys: []string{"T"}, Hint: "T", Desc: "triage"},
+		{Action: ActionOpenLabels, Keys: []string{"L"}, Hint: "L", Desc: "labels"},
+		{Action: ActionOpenFilters, Keys: []string{"F"}, Hint: "F", Desc: "filters"},
 		{Action: ActionToggleFocus, Keys: []string{"tab"}, Hint: "tab", Desc: "projects"},
 		{Action: ActionNavDown, Keys: []string{"j", "down"}, Hint: "j/k", Desc: "nav"},
 		{Action: ActionNavUp, Keys: []string{"k", "up"}, Hint: "j/k", Desc: "nav"},
 		{Action: ActionNavTop, Keys: []string{"g"}, Hint: "g", Desc: "top"},
 		{Action: ActionNavBottom, Keys: []string{"G"}, Hint: "G", Desc: "bottom"},
 		{Action: ActionToggleDone, Keys: []string{"x", " "}, Hint: "x/space", Desc: "toggle"},
+		{Action: ActionOpenDetail, Keys: []string{"enter"}, Hint: "enter", Desc: "details"},
 		{Action: ActionSetDue, Keys: []string{"s"}, Hint: "s", Desc: "due"},
 		{Action: ActionSetDeadline, Keys: []string{"S"}, Hint: "S", Desc: "deadline"},
 		{Action: ActionClearDates, Keys: []string{"-"}, Hint: "-", Desc: "clear dates"},
+		{Action: ActionDeleteTask, Keys: []string{"d"}, Hint: "d", Desc: "del"},
+		{Action: ActionMoveTask, Keys: []string{"m"}, Hint: "m", Desc: "move"},
+		{Action: ActionSetLabels, Keys: []string{"l"}, Hint: "l", Desc: "labels"},
+		{Action: ActionCopyJSON, Keys: []string{"y"}, Hint: "y", Desc: "copy JSON"},
+		{Action: ActionToggleMark, Keys: []string{"v"}, Hint: "v/V", Desc: "mark"},
+		{Action: ActionMarkRange, Keys: []string{"V"}, Hint: "v/V", Desc: "mark range"},
+		{Action: ActionSetPriority1, Keys: []string{"1"}, Hint: "1-4", Desc: "prio"},
+		{Action: ActionSetPriority2, Keys: []string{"2"}, Hint: "1-4", Desc: "prio"},
+		{Action: ActionSetPriority3, Keys: []string{"3"}, Hint: "1-4", Desc: "prio"},
+		{Action: ActionSetPriority4, Keys: []string{"4"}, Hint: "1-4", Desc: "prio"},
 		{Action: ActionNewTask, Keys: []string{"n"}, Hint: "n", Desc: "new"},
 		{Action: ActionSearchLocal, Keys: []string{"/"}, Hint: "/", Desc: "search"},
 		{Action: ActionSearchNext, Keys: []string{"n"}, Hint: "n/N", Desc: "next/prev"},
@@ -220,6 +366,7 @@ var contextBindings = map[InputContext][]KeyBinding{
 	},
 	ContextMainTodayDialog: {
 		{Action: ActionConfirm, Keys: []string{"enter"}, Hint: "enter", Desc: "confirm"},
+		{Action: ActionCompleteLabel, Keys: []string{"tab"}, Hint: "tab", Desc: "complete label"},
 		{Action: ActionCancel, Keys: []string{"esc"}, Hint: "esc", Desc: "cancel"},
 	},
 	ContextMainTodaySearch: {
@@ -316,11 +463,16 @@ func HintsForContext(ctx InputContext) []string {
 func HelpSections() []helpSection {
 	return []helpSection{
 		{Title: "Navigation", Context: ContextMainTasks, ActionFilter: map[Action]bool{ActionNavDown: true, ActionNavUp: true, ActionNavTop: true, ActionNavBottom: true, ActionToggleFocus: true, ActionFocusTasks: true}},
-		{Title: "Tasks", Context: ContextMainTasks, ActionFilter: map[Action]bool{ActionToggleDone: true, ActionNewTask: true, ActionEditTask: true, ActionSetDue: true, ActionSetDeadline: true, ActionClearDates: true, ActionDeleteTask: true, ActionSetPriority1: true}},
-		{Title: "Projects", Context: ContextMainSidebar, ActionFilter: map[Action]bool{ActionAddProject: true, ActionArchiveProject: true}},
+		{Title: "Tasks", Context: ContextMainTasks, ActionFilter: map[Action]bool{ActionToggleDone: true, ActionNewTask: true, ActionEditTask: true, ActionSetDue: true, ActionSetDeadline: true, ActionClearDates: true, ActionDeleteTask: true, ActionSetPriority1: true, ActionToggleCollapse: true, ActionIndent: true, ActionOutdent: true, ActionMoveDown: true, ActionMoveTask: true, ActionSetLabels: true, ActionCopyJSON: true, ActionOpenDetail: true}},
+		{Title: "Multi-select", Context: ContextMainTasks, ActionFilter: map[Action]bool{ActionToggleMark: true, ActionMarkRange: true}},
+		{Title: "Task details", Context: ContextDetailOverlay, ActionFilter: map[Action]bool{ActionEditTask: true, ActionAddComment: true, ActionNextComment: true, ActionEditComment: true, ActionDeleteComment: true}},
+		{Title: "Labels", Context: ContextLabelsOverlay, ActionFilter: map[Action]bool{ActionAddLabel: true, ActionRenameLabel: true, ActionRecolorLabel: true, ActionDeleteLabel: true}},
+		{Title: "Filters", Context: ContextFiltersOverlay, ActionFilter: map[Action]bool{ActionAddFilter: true, ActionEditFilter: true, ActionRecolorFilter: true, ActionToggleFavorite: true, ActionDeleteFilter: true, ActionMoveDown: true}},
+		{Title: "Sections", Context: ContextMainTasks, ActionFilter: map[Action]bool{ActionAddSection: true, ActionArchiveSection: true, ActionMoveDown: true}},
+		{Title: "Projects", Context: ContextMainSidebar, ActionFilter: map[Action]bool{ActionAddProject: true, ActionArchiveProject: true, ActionToggleCollapse: true, ActionIndent: true, ActionMoveDown: true}},
 		{Title: "Search", Context: ContextMainTasks, ActionFilter: map[Action]bool{ActionOpenSearch: true, ActionSearchLocal: true, ActionSearchNext: true, ActionClearSearch: true}},
 		{Title: "Triage", Context: ContextTriageOverlay, ActionFilter: map[Action]bool{ActionOpenTriage: true, ActionSetPriority1: true, ActionSetPriority2: true, ActionSetPriority3: true, ActionClearPriority: true, ActionSetLabels: true, ActionMarkReviewed: true}},
-		{Title: "General", Context: ContextMainTasks, ActionFilter: map[Action]bool{ActionOpenActions: true, ActionRefresh: true, ActionOpenCompleted: true, ActionOpenQueue: true, ActionToggleHelp: true, ActionQuit: true}},
+		{Title: "General", Context: ContextMainTasks, ActionFilter: map[Action]bool{ActionOpenActions: true, ActionRefresh: true, ActionOpenCompleted: true, ActionOpenLabels: true, ActionOpenFilters: true, ActionOpenQueue: true, ActionUndo: true, ActionRedo: true, ActionToggleHelp: true, ActionQuit: true}},
 	}
 }
 
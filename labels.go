@@ -0,0 +1,466 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// todoistColors lists the Todoist palette in the order the web app shows it.
+var todoistColors = []string{
+	"berry_red", "red", "orange", "yellow", "olive_green", "lime_green", "green",
+	"mint_green", "teal", "sky_blue", "light_blue", "blue", "grape", "violet",
+	"lavender", "magenta", "salmon", "charcoal", "grey", "taupe",
+}
+
+// LabelsView is the label browser overlay: lists personal labels with their
+// open task counts and manages create, rename, recolor and delete.
+type LabelsView struct {
+	repo         *Repository
+	labels       []Label
+	counts       map[string]int
+	cursor       int
+	scrollOffset int
+	height       int
+
+	// Dialog state
+	mode        string // "", "add", "rename", "color", "delete"
+	nameInput   textinput.Model
+	colorCursor int
+}
+
+func NewLabelsView(repo *Repository) LabelsView {
+	ni := textinput.New()
+	ni.Placeholder = "Label name..."
+	ni.CharLimit = 60
+	return LabelsView{repo: repo, nameInput: ni}
+}
+
+// Refresh reloads labels and task counts from cache.
+func (v *LabelsView) Refresh() {
+	v.labels = v.repo.GetCachedLabels()
+	v.counts = v.repo.GetLabelTaskCounts()
+	if v.cursor >= len(v.labels) {
+		v.cursor = len(v.labels) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
+func (v *LabelsView) SetSize(height int) {
+	v.height = height
+	v.ensureVisible()
+}
+
+func (v *LabelsView) ensureVisible() {
+	visibleHeight := v.height - 8
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+	listEnsureVisible(v.cursor, &v.scrollOffset, visibleHeight)
+}
+
+func (v LabelsView) handlesInput() bool {
+	return v.mode != ""
+}
+
+// inputContext returns the key context for the active dialog.
+func (v LabelsView) inputContext() InputContext {
+	switch v.mode {
+	case "":
+		return ContextLabelsOverlay
+	case "delete":
+		return ContextMainSidebarDialog
+	default:
+		return ContextMainTasksSearch
+	}
+}
+
+func (v LabelsView) selectedLabel() *Label {
+	if v.cursor < 0 || v.cursor >= len(v.labels) {
+		return nil
+	}
+	return &v.labels[v.cursor]
+}
+
+func (v LabelsView) Update(msg tea.Msg) (LabelsView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case labelCreatedMsg:
+		if msg.err != nil {
+			return v, func() tea.Msg {
+				return toastMsg{text: "Failed to create label: " + msg.err.Error(), isError: true}
+			}
+		}
+		v.Refresh()
+		for i, l := range v.labels {
+			if l.ID == msg.label.ID {
+				v.cursor = i
+			}
+		}
+		v.ensureVisible()
+		return v, nil
+
+	case labelUpdatedMsg:
+		if msg.err != nil {
+			return v, func() tea.Msg {
+				return toastMsg{text: "Failed to update label: " + msg.err.Error(), isError: true}
+			}
+		}
+		v.Refresh()
+		return v, nil
+
+	case labelDeletedMsg:
+		if msg.err != nil {
+			return v, func() tea.Msg {
+				return toastMsg{text: "Failed to delete label: " + msg.err.Error(), isError: true}
+			}
+		}
+		v.Refresh()
+		return v, nil
+
+	case tea.MouseMsg:
+		m := tea.MouseEvent(msg)
+		if v.mode != "" {
+			return v, nil
+		}
+		switch m.Button {
+		case tea.MouseButtonWheelDown:
+			if v.cursor < len(v.labels)-1 {
+				v.cursor++
+			}
+			v.ensureVisible()
+		case tea.MouseButtonWheelUp:
+			if v.cursor > 0 {
+				v.cursor--
+			}
+			v.ensureVisible()
+		case tea.MouseButtonLeft:
+			// helpStyle has Padding(1,2): 1 row top pad, then title, MarginBottom(1), blank line
+			idx := v.scrollOffset + m.Y - 4
+			if m.Y >= 4 && idx < len(v.labels) {
+				v.cursor = idx
+			}
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.mode != "" {
+			return v.handleDialogKey(msg)
+		}
+		switch ResolveAction(ContextLabelsOverlay, msg.String()) {
+		case ActionNavDown:
+			if v.cursor < len(v.labels)-1 {
+				v.cursor++
+			}
+			v.ensureVisible()
+		case ActionNavUp:
+			if v.cursor > 0 {
+				v.cursor--
+			}
+			v.ensureVisible()
+		case ActionNavTop:
+			v.cursor = 0
+			v.ensureVisible()
+		case ActionNavBottom:
+			v.cursor = max(len(v.labels)-1, 0)
+			v.ensureVisible()
+		case ActionAddLabel:
+			v.mode = "add"
+			v.nameInput.Reset()
+			v.nameInput.Focus()
+			return v, textinput.Blink
+		case ActionRenameLabel:
+			if l := v.selectedLabel(); l != nil {
+				v.mode = "rename"
+				v.nameInput.SetValue(l.Name)
+				v.nameInput.CursorEnd()
+				v.nameInput.Focus()
+				return v, textinput.Blink
+			}
+		case ActionRecolorLabel:
+			if l := v.selectedLabel(); l != nil {
+				v.mode = "color"
+				v.colorCursor = 0
+				for i, c := range todoistColors {
+					if c == l.Color {
+						v.colorCursor = i
+					}
+				}
+			}
+		case ActionDeleteLabel:
+			if v.selectedLabel() != nil {
+				v.mode = "delete"
+			}
+		}
+		return v, nil
+	}
+
+	if v.mode == "add" || v.mode == "rename" {
+		var cmd tea.Cmd
+		v.nameInput, cmd = v.nameInput.Update(msg)
+		return v, cmd
+	}
+	return v, nil
+}
+
+func (v LabelsView) handleDialogKey(msg tea.KeyMsg) (LabelsView, tea.Cmd) {
+	switch v.mode {
+	case "add", "rename":
+		switch ResolveAction(ContextMainTasksSearch, msg.String()) {
+		case ActionConfirm:
+			mode := v.mode
+			v.mode = ""
+			v.nameInput.Blur()
+			name := normalizeLabelName(v.nameInput.Value())
+			if name == "" {
+				return v, nil
+			}
+			if mode == "add" {
+				return v, v.repo.CreateLabel(name, "charcoal")
+			}
+			if l := v.selectedLabel(); l != nil && name != l.Name {
+				return v, v.repo.UpdateLabel(l.ID, labelPayload{Name: name})
+			}
+			return v, nil
+		case ActionCancel:
+			v.mode = ""
+			v.nameInput.Blur()
+			return v, nil
+		}
+		var cmd tea.Cmd
+		v.nameInput, cmd = v.nameInput.Update(msg)
+		return v, cmd
+
+	case "color":
+		switch ResolveAction(ContextLabelsOverlay, msg.String()) {
+		case ActionNavDown:
+			if v.colorCursor < len(todoistColors)-1 {
+				v.colorCursor++
+			}
+			return v, nil
+		case ActionNavUp:
+			if v.colorCursor > 0 {
+				v.colorCursor--
+			}
+			return v, nil
+		}
+		switch ResolveAction(ContextMainTasksSearch, msg.String()) {
+		case ActionConfirm:
+			v.mode = ""
+			color := todoistColors[v.colorCursor]
+			if l := v.selectedLabel(); l != nil && color != l.Color {
+				return v, v.repo.UpdateLabel(l.ID, labelPayload{Color: color})
+			}
+		case ActionCancel:
+			v.mode = ""
+		}
+		return v, nil
+
+	case "delete":
+		switch ResolveAction(ContextMainSidebarDialog, msg.String()) {
+		case ActionConfirm:
+			v.mode = ""
+			if l := v.selectedLabel(); l != nil {
+				return v, v.repo.DeleteLabel(l.ID)
+			}
+		case ActionCancel:
+			v.mode = ""
+		}
+		return v, nil
+	}
+	return v, nil
+}
+
+func (v LabelsView) View(width, height int) string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().
+		Foreground(colorBlue).
+		Bold(true).
+		MarginBottom(1).
+		Render("Labels"))
+	b.WriteString("\n\n")
+
+	if len(v.labels) == 0 && v.mode != "add" {
+		b.WriteString(emptyStyle.Render("No labels — press n to create one"))
+		b.WriteString("\n\n")
+		b.WriteString(footerKeyStyle.Render("n") + " new  " +
+			footerKeyStyle.Render("L") + " " + footerDescStyle.Render("close"))
+		return helpStyle.Width(width).Height(height).Render(b.String())
+	}
+
+	visibleHeight := height - 8
+	if v.mode != "" {
+		visibleHeight -= 4
+	}
+	if visibleHeight < 1 {
+		visibleHeight = 1
+	}
+	end := v.scrollOffset + visibleHeight
+	if end > len(v.labels) {
+		end = len(v.labels)
+	}
+
+	syncStatus := v.repo.LabelMutationStatusMap()
+	for i := v.scrollOffset; i < end; i++ {
+		l := v.labels[i]
+		count := fmt.Sprintf("%d", v.counts[l.Name])
+		if i == v.cursor {
+			// Plain text avoids inner ANSI resets breaking the selection background
+			line := fmt.Sprintf("● @%-24s %4s  %s", l.Name, count, mutationBadgePlain(syncStatus[l.ID]))
+			b.WriteString(queueSelectedStyle.Width(width - 4).Render(line))
+		} else {
+			line := lipgloss.NewStyle().Foreground(projectColor(l.Color)).Render("●") + " " +
+				labelStyle.Render(fmt.Sprintf("@%-24s", l.Name)) + " " +
+				subtaskCountStyle.Render(fmt.Sprintf("%4s", count))
+			if badge := mutationBadgeStyled(syncStatus[l.ID]); badge != "" {
+				line += "  " + badge
+			}
+			b.WriteString(queueItemStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+
+	switch v.mode {
+	case "add", "rename":
+		title := "New Label"
+		if v.mode == "rename" {
+			title = "Rename Label"
+		}
+		b.WriteString("\n")
+		b.WriteString(dialogTitleStyle.Render(title) + "\n")
+		if v.mode == "rename" {
+			b.WriteString(inputLabelStyle.Render("Tasks carrying this label are renamed too") + "\n")
+		}
+		b.WriteString(v.nameInput.View() + "\n")
+	case "color":
+		b.WriteString("\n")
+		b.WriteString(dialogTitleStyle.Render("Label Color") + "\n")
+		var swatches []string
+		for i, c := range todoistColors {
+			dot := lipgloss.NewStyle().Foreground(projectColor(c)).Render("●")
+			if i == v.colorCursor {
+				dot = lipgloss.NewStyle().Foreground(projectColor(c)).Underline(true).Render("◉")
+			}
+			swatches = append(swatches, dot)
+		}
+		b.WriteString(strings.Join(swatches, " ") + "  " + inputLabelStyle.Render(todoistColors[v.colorCursor]) + "\n")
+	case "delete":
+		b.WriteString("\n")
+		b.WriteString(dialogTitleStyle.Render("Delete Label?") + "\n")
+		if l := v.selectedLabel(); l != nil {
+			b.WriteString(taskContentStyle.Render("@"+l.Name) + " " +
+				inputLabelStyle.Render(fmt.Sprintf("(removed from %d task(s))", v.counts[l.Name])) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	switch v.mode {
+	case "":
+		b.WriteString(footerKeyStyle.Render("j/k") + " nav  " +
+			footerKeyStyle.Render("n") + " new  " +
+			footerKeyStyle.Render("r") + " rename  " +
+			footerKeyStyle.Render("c") + " color  " +
+			footerKeyStyle.Render("d") + " delete  " +
+			footerKeyStyle.Render("L") + " close")
+	case "color":
+		b.WriteString(footerKeyStyle.Render("j/k") + " pick  " +
+			footerKeyStyle.Render("enter") + " save  " +
+			footerKeyStyle.Render("esc") + " cancel")
+	case "delete":
+		b.WriteString(footerKeyStyle.Render("y") + " confirm  " +
+			footerKeyStyle.Render("n") + " cancel")
+	default:
+		b.WriteString(footerKeyStyle.Render("enter") + " save  " +
+			footerKeyStyle.Render("esc") + " cancel")
+	}
+
+	return helpStyle.Width(width).Height(height).Render(b.String())
+}
+
+// normalizeLabelName trims a typed label name. Label inputs are
+// space-separated, so inner spaces become underscores.
+func normalizeLabelName(s string) string {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "@")
+	return strings.Join(strings.Fields(s), "_")
+}
+
+// labelTokens splits a space-separated labels input into names without "@".
+func labelTokens(input string) []string {
+	fields := strings.Fields(input)
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if name := strings.TrimPrefix(f, "@"); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// labelCompletions returns known labels matching the word being typed at the
+// end of input, skipping ones already entered.
+func labelCompletions(input string, known []Label) []string {
+	if input == "" || strings.HasSuffix(input, " ") {
+		return nil
+	}
+	tokens := labelTokens(input)
+	if len(tokens) == 0 {
+		return nil
+	}
+	prefix := strings.ToLower(tokens[len(tokens)-1])
+	entered := make(map[string]bool, len(tokens))
+	for _, t := range tokens[:len(tokens)-1] {
+		entered[strings.ToLower(t)] = true
+	}
+	var out []string
+	for _, l := range known {
+		lower := strings.ToLower(l.Name)
+		if strings.HasPrefix(lower, prefix) && !entered[lower] {
+			out = append(out, l.Name)
+		}
+	}
+	return out
+}
+
+// completeLabelInput replaces the word being typed with name.
+func completeLabelInput(input, name string) string {
+	i := strings.LastIndex(input, " ")
+	return input[:i+1] + name + " "
+}
+
+// unknownLabels returns entered names that match no known label; saving them
+// makes the server create the labels implicitly.
+func unknownLabels(input string, known []Label) []string {
+	names := make(map[string]bool, len(known))
+	for _, l := range known {
+		names[l.Name] = true
+	}
+	var out []string
+	for _, t := range labelTokens(input) {
+		if !names[t] && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// renderLabelHints shows completions and implicit-create warnings under a
+// labels input.
+func renderLabelHints(input string, known []Label) string {
+	var lines []string
+	if matches := labelCompletions(input, known); len(matches) > 0 {
+		if len(matches) > 6 {
+			matches = matches[:6]
+		}
+		lines = append(lines, footerKeyStyle.Render("tab")+" "+labelStyle.Render("@"+strings.Join(matches, " @")))
+	}
+	if unknown := unknownLabels(input, known); len(unknown) > 0 {
+		lines = append(lines, queueConflictStyle.Render("⚠ will create new label(s): @"+strings.Join(unknown, " @")))
+	}
+	return strings.Join(lines, "\n")
+}
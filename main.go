@@ -4,13 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
+	"slices"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-const cacheTTL = 1 * time.Hour
-
 func main() {
 	// Resolve API token: env var > keychain > setup wizard
 	token := os.Getenv("TODOIST_API_TOKEN")
@@ -18,7 +16,12 @@ func main() {
 		token, _ = keychainGet()
 	}
 
+	if len(os.Args) > 1 && isCLICommand(os.Args[1]) {
+		os.Exit(runCLI(os.Args[1:], token))
+	}
+
 	forceSetup := len(os.Args) > 1 && os.Args[1] == "--setup"
+	listen := slices.Contains(os.Args[1:], "--listen")
 
 	// Set terminal background
 	fmt.Fprint(os.Stdout, "\033]11;#1A1B26\007")
@@ -36,16 +39,29 @@ func main() {
 	// Set up SQLite cache
 	var store *Store
 	if cacheDir, err := cacheDBPath(); err == nil {
-		if s, err := NewStore(cacheDir, cacheTTL); err == nil {
+		if s, err := NewStore(cacheDir); err == nil {
 			store = s
 			defer store.Close()
 		}
 	}
 
-	repo := NewRepository(client, store)
+	repo := NewRepository(client, NewSyncClient(token), store)
 	app := NewApp(repo)
 
+	if listen {
+		control, err := listenControl(repo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error: control API:", err)
+			return
+		}
+		defer control.Close()
+		app.control = control
+	}
+
 	p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if app.control != nil {
+		go app.control.serve(p)
+	}
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
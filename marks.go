@@ -0,0 +1,155 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// taskMarks tracks the tasks marked for a bulk edit in a list view. The
+// anchor is the last task toggled; a range mark extends from it to the cursor.
+type taskMarks struct {
+	ids    map[string]bool
+	anchor string
+}
+
+func newTaskMarks() taskMarks {
+	return taskMarks{ids: make(map[string]bool)}
+}
+
+// toggle flips the mark on a task and makes it the range anchor.
+func (m *taskMarks) toggle(id string) {
+	if m.ids[id] {
+		delete(m.ids, id)
+	} else {
+		m.ids[id] = true
+	}
+	m.anchor = id
+}
+
+// markRange marks every task in order between the anchor and id, inclusive.
+// Without an anchor in order it marks id alone.
+func (m *taskMarks) markRange(order []string, id string) {
+	from, to := slices.Index(order, m.anchor), slices.Index(order, id)
+	if to < 0 {
+		return
+	}
+	if from < 0 {
+		from = to
+	}
+	if from > to {
+		from, to = to, from
+	}
+	for _, tid := range order[from : to+1] {
+		m.ids[tid] = true
+	}
+	m.anchor = id
+}
+
+func (m *taskMarks) clear() {
+	clear(m.ids)
+	m.anchor = ""
+}
+
+func (m taskMarks) has(id string) bool {
+	return m.ids[id]
+}
+
+func (m taskMarks) count() int {
+	return len(m.ids)
+}
+
+// prune drops marks on tasks no longer in order (completed, deleted or
+// moved out of the view).
+func (m *taskMarks) prune(order []string) {
+	for id := range m.ids {
+		if !slices.Contains(order, id) {
+			delete(m.ids, id)
+		}
+	}
+}
+
+// targets returns the marked tasks in list order, or the selected task when
+// nothing is marked.
+func (m taskMarks) targets(order []string, selected *Task) []string {
+	if len(m.ids) == 0 {
+		if selected == nil {
+			return nil
+		}
+		return []string{selected.ID}
+	}
+	var ids []string
+	for _, id := range order {
+		if m.ids[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// bulkOrSingle runs edit on a single task directly, or on several as one
+// grouped bulk edit.
+func bulkOrSingle(repo *Repository, verb string, ids []string, edit func(taskID string) tea.Cmd) tea.Cmd {
+	switch len(ids) {
+	case 0:
+		return nil
+	case 1:
+		return edit(ids[0])
+	}
+	return repo.BulkEdit(verb, ids, edit)
+}
+
+// commonLabels returns the labels every task carries, in the first task's order.
+func commonLabels(tasks []Task) []string {
+	if len(tasks) == 0 {
+		return nil
+	}
+	var out []string
+	for _, l := range tasks[0].Labels {
+		shared := true
+		for _, t := range tasks[1:] {
+			if !slices.Contains(t.Labels, l) {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// parseLabelInput splits a space-separated label list, dropping @ prefixes.
+func parseLabelInput(s string) []string {
+	labels := []string{}
+	for _, l := range strings.Fields(s) {
+		if l = strings.TrimPrefix(l, "@"); l != "" && !slices.Contains(labels, l) {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// editLabels applies a label edit made against the shared labels before to a
+// task's current labels: shared labels left out are removed, new ones added,
+// and labels only this task carries are kept.
+func editLabels(current, before, after []string) []string {
+	out := []string{}
+	for _, l := range current {
+		if slices.Contains(before, l) && !slices.Contains(after, l) {
+			continue
+		}
+		out = append(out, l)
+	}
+	for _, l := range after {
+		if !slices.Contains(out, l) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// markedCheck replaces a task's checkbox while it is marked.
+const markedCheck = "◉"
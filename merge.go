@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Task fields a queued update can conflict on, in display order. Labels are
+// absent: they merge as a set and never conflict.
+const (
+	fieldContent     = "content"
+	fieldDescription = "description"
+	fieldPriority    = "priority"
+	fieldDue         = "due"
+	fieldDeadline    = "deadline"
+)
+
+// String renders a conflict record on one line.
+func (c ConflictRecord) String() string {
+	if c.Field == "" {
+		if c.HTTPStatus != 0 {
+			return fmt.Sprintf("%s (HTTP %d)", c.Reason, c.HTTPStatus)
+		}
+		return c.Reason
+	}
+	return fmt.Sprintf("%s: you changed %q→%q, server has %q", c.Field, c.Base, c.Local, c.Remote)
+}
+
+// encodeConflicts stamps conflict records with the detection time and
+// encodes them for Mutation.Conflict.
+func encodeConflicts(records ...ConflictRecord) string {
+	now := time.Now().UTC()
+	for i := range records {
+		if records[i].DetectedAt.IsZero() {
+			records[i].DetectedAt = now
+		}
+	}
+	blob, _ := json.Marshal(records)
+	return string(blob)
+}
+
+// legacyConflictPattern matches one field conflict in the free-text
+// descriptions queued before conflicts were stored as records.
+var legacyConflictPattern = regexp.MustCompile(`^(\w+): you changed ("(?:[^"\\]|\\.)*"|-?\d+)→("(?:[^"\\]|\\.)*"|-?\d+), server has ("(?:[^"\\]|\\.)*"|-?\d+)`)
+
+// legacyConflictRecords converts a free-text conflict description into
+// records: field conflicts where every part parses, else one Reason. Parts
+// are joined by "; ", which quoted values may contain too, so each part is
+// matched in turn rather than split out first.
+func legacyConflictRecords(text string, detectedAt time.Time) []ConflictRecord {
+	var records []ConflictRecord
+	for rest := text; ; {
+		match := legacyConflictPattern.FindStringSubmatch(rest)
+		if match == nil {
+			return []ConflictRecord{{Reason: text, DetectedAt: detectedAt}}
+		}
+		rec := ConflictRecord{Field: match[1], DetectedAt: detectedAt}
+		for i, dst := range []*string{&rec.Base, &rec.Local, &rec.Remote} {
+			if v, err := strconv.Unquote(match[i+2]); err == nil {
+				*dst = v
+			} else {
+				*dst = match[i+2]
+			}
+		}
+		records = append(records, rec)
+		rest = rest[len(match[0]):]
+		if rest == "" {
+			return records
+		}
+		var ok bool
+		if rest, ok = strings.CutPrefix(rest, "; "); !ok {
+			return []ConflictRecord{{Reason: text, DetectedAt: detectedAt}}
+		}
+	}
+}
+
+// mergeTaskUpdate three-way merges a queued update with the server copy of
+// the task, using the snapshot taken when it was queued as the common base.
+// Fields only one side changed merge cleanly, and labels merge as a set: the
+// labels the update added or removed are applied to the server's labels. The
+// returned request carries the merge; conflicts lists the fields both sides
+// changed differently, which the request still sets to the local value.
+func mergeTaskUpdate(base, remote Task, req updateTaskRequest) (updateTaskRequest, []ConflictRecord) {
+	var conflicts []ConflictRecord
+	check := func(field, baseValue, local, remoteValue string) {
+		if remoteValue != baseValue && remoteValue != local {
+			conflicts = append(conflicts, ConflictRecord{Field: field, Base: baseValue, Local: local, Remote: remoteValue})
+		}
+	}
+	if req.Content != nil {
+		check(fieldContent, base.Content, *req.Content, remote.Content)
+	}
+	if req.Description != nil {
+		check(fieldDescription, base.Description, *req.Description, remote.Description)
+	}
+	if req.Priority != nil {
+		check(fieldPriority, strconv.Itoa(base.Priority), strconv.Itoa(*req.Priority), strconv.Itoa(remote.Priority))
+	}
+	if req.DueString != nil {
+		check(fieldDue, dueString(base), *req.DueString, dueString(remote))
+	}
+	if req.ClearDeadline || req.DeadlineDate != nil {
+		local := ""
+		if !req.ClearDeadline {
+			local = *req.DeadlineDate
+		}
+		check(fieldDeadline, deadlineDate(base), local, deadlineDate(remote))
+	}
+	if req.Labels != nil {
+		req.Labels = editLabels(remote.Labels, base.Labels, req.Labels)
+	}
+	return req, conflicts
+}
+
+// resolveTaskUpdate settles the conflicts of a merged update: each field in
+// values is set to the chosen value, and conflicting fields left out keep the
+// server's value.
+func resolveTaskUpdate(req updateTaskRequest, conflicts []ConflictRecord, values map[string]string) updateTaskRequest {
+	for _, c := range conflicts {
+		v, ok := values[c.Field]
+		switch c.Field {
+		case fieldContent:
+			req.Content = nil
+			if ok {
+				req.Content = &v
+			}
+		case fieldDescription:
+			req.Description = nil
+			if ok {
+				req.Description = &v
+			}
+		case fieldPriority:
+			req.Priority = nil
+			if p, err := strconv.Atoi(v); ok && err == nil {
+				req.Priority = &p
+			}
+		case fieldDue:
+			req.DueString = nil
+			if ok {
+				req.DueString = &v
+			}
+		case fieldDeadline:
+			req.ClearDeadline, req.DeadlineDate = false, nil
+			if ok && v == "" {
+				req.ClearDeadline = true
+			} else if ok {
+				req.DeadlineDate = &v
+			}
+		}
+	}
+	return req
+}
+
+// withRemoteChanges copies onto a cached task the mergeable fields the server
+// changed since base, leaving its other optimistic state alone.
+func withRemoteChanges(task, base, remote Task) Task {
+	if remote.Content != base.Content {
+		task.Content = remote.Content
+	}
+	if remote.Description != base.Description {
+		task.Description = remote.Description
+	}
+	if remote.Priority != base.Priority {
+		task.Priority = remote.Priority
+	}
+	if dueString(remote) != dueString(base) {
+		task.Due = remote.Due
+	}
+	if deadlineDate(remote) != deadlineDate(base) {
+		task.Deadline = remote.Deadline
+	}
+	if !slices.Equal(remote.Labels, base.Labels) {
+		task.Labels = remote.Labels
+	}
+	return task
+}
+
+func dueString(t Task) string {
+	if t.Due == nil {
+		return ""
+	}
+	return t.Due.String
+}
+
+func deadlineDate(t Task) string {
+	if t.Deadline == nil {
+		return ""
+	}
+	return t.Deadline.Date
+}
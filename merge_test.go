@@ -0,0 +1,162 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeTaskUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	base := Task{Content: "buy milk", Description: "", Priority: 4, Labels: []string{"home", "errand"},
+		Due: &Due{String: "today"}, Deadline: &Deadline{Date: "2026-03-20"}}
+
+	tests := []struct {
+		name      string
+		remote    Task
+		req       updateTaskRequest
+		want      updateTaskRequest
+		conflicts []string // conflicting fields
+	}{
+		{
+			name:   "server changed another field",
+			remote: Task{Content: "buy milk", Description: "2 litres", Priority: 4, Labels: base.Labels, Due: base.Due},
+			req:    updateTaskRequest{Content: str("buy oat milk")},
+			want:   updateTaskRequest{Content: str("buy oat milk")},
+		},
+		{
+			name:   "both made the same change",
+			remote: Task{Content: "buy oat milk", Priority: 4, Labels: base.Labels},
+			req:    updateTaskRequest{Content: str("buy oat milk")},
+			want:   updateTaskRequest{Content: str("buy oat milk")},
+		},
+		{
+			name:      "both changed content",
+			remote:    Task{Content: "buy soy milk", Priority: 4, Labels: base.Labels},
+			req:       updateTaskRequest{Content: str("buy oat milk"), Priority: num(1)},
+			want:      updateTaskRequest{Content: str("buy oat milk"), Priority: num(1)},
+			conflicts: []string{fieldContent},
+		},
+		{
+			name:      "priority, due and cleared deadline",
+			remote:    Task{Content: "buy milk", Priority: 2, Labels: base.Labels, Due: &Due{String: "tomorrow"}, Deadline: &Deadline{Date: "2026-03-25"}},
+			req:       updateTaskRequest{Priority: num(1), DueString: str("friday"), ClearDeadline: true},
+			want:      updateTaskRequest{Priority: num(1), DueString: str("friday"), ClearDeadline: true},
+			conflicts: []string{fieldPriority, fieldDue, fieldDeadline},
+		},
+		{
+			name:   "labels merge as a set",
+			remote: Task{Content: "buy milk", Priority: 4, Labels: []string{"home", "errand", "shop"}},
+			req:    updateTaskRequest{Labels: []string{"errand", "urgent"}}, // removed home, added urgent
+			want:   updateTaskRequest{Labels: []string{"errand", "shop", "urgent"}},
+		},
+	}
+	for _, tt := range tests {
+		got, conflicts := mergeTaskUpdate(base, tt.remote, tt.req)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: merged %+v, want %+v", tt.name, got, tt.want)
+		}
+		var fields []string
+		for _, c := range conflicts {
+			fields = append(fields, c.Field)
+		}
+		if !reflect.DeepEqual(fields, tt.conflicts) {
+			t.Errorf("%s: conflicts on %v, want %v", tt.name, fields, tt.conflicts)
+		}
+	}
+}
+
+func TestMergeTaskUpdateConflictValues(t *testing.T) {
+	base := Task{Content: "a", Priority: 3}
+	remote := Task{Content: "b", Priority: 3}
+	content := "c"
+	_, conflicts := mergeTaskUpdate(base, remote, updateTaskRequest{Content: &content})
+	want := []ConflictRecord{{Field: fieldContent, Base: "a", Local: "c", Remote: "b"}}
+	if !reflect.DeepEqual(conflicts, want) {
+		t.Errorf("conflicts = %+v, want %+v", conflicts, want)
+	}
+}
+
+func TestResolveTaskUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	req := updateTaskRequest{Content: str("mine"), Priority: num(1), DueString: str("friday"), DeadlineDate: str("2026-04-01"), Labels: []string{"x"}}
+	conflicts := []ConflictRecord{{Field: fieldContent}, {Field: fieldPriority}, {Field: fieldDue}, {Field: fieldDeadline}}
+
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   updateTaskRequest
+	}{
+		{
+			name:   "keep theirs everywhere",
+			values: map[string]string{},
+			want:   updateTaskRequest{Labels: []string{"x"}},
+		},
+		{
+			name:   "mine, edited and cleared",
+			values: map[string]string{fieldContent: "mine", fieldPriority: "2", fieldDue: "next week", fieldDeadline: ""},
+			want:   updateTaskRequest{Content: str("mine"), Priority: num(2), DueString: str("next week"), ClearDeadline: true, Labels: []string{"x"}},
+		},
+		{
+			name:   "unparsable priority keeps theirs",
+			values: map[string]string{fieldPriority: "urgent", fieldDeadline: "2026-05-01"},
+			want:   updateTaskRequest{DeadlineDate: str("2026-05-01"), Labels: []string{"x"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := resolveTaskUpdate(req, conflicts, tt.values); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: resolved %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLegacyConflictRecords(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		text string
+		want []ConflictRecord
+	}{
+		{
+			`content: you changed "buy milk"→"buy oat milk", server has "buy soy milk"`,
+			[]ConflictRecord{{Field: "content", Base: "buy milk", Local: "buy oat milk", Remote: "buy soy milk", DetectedAt: at}},
+		},
+		{
+			`priority: you changed 4→1, server has 2; due: you changed ""→"friday", server has "say \"when\"; later"`,
+			[]ConflictRecord{
+				{Field: "priority", Base: "4", Local: "1", Remote: "2", DetectedAt: at},
+				{Field: "due", Base: "", Local: "friday", Remote: `say "when"; later`, DetectedAt: at},
+			},
+		},
+		{
+			"task was deleted on the server",
+			[]ConflictRecord{{Reason: "task was deleted on the server", DetectedAt: at}},
+		},
+		{
+			// One unparsable part keeps the whole text as the reason.
+			`content: you changed "a"→"b", server has "c"; something else`,
+			[]ConflictRecord{{Reason: `content: you changed "a"→"b", server has "c"; something else`, DetectedAt: at}},
+		},
+	}
+	for _, tt := range tests {
+		if got := legacyConflictRecords(tt.text, at); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("legacyConflictRecords(%q) =\n%+v\nwant\n%+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMutationConflictsReadsBothFormats(t *testing.T) {
+	at := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	structured := Mutation{Conflict: `[{"reason":"invalid argument","http_status":400,"detected_at":"2026-01-02T00:00:00Z"}]`}
+	if got := structured.Conflicts(); len(got) != 1 || got[0].Reason != "invalid argument" || got[0].HTTPStatus != 400 {
+		t.Errorf("structured conflicts = %+v", got)
+	}
+	legacy := Mutation{Conflict: "server rejected the update", CreatedAt: at}
+	if got := legacy.Conflicts(); len(got) != 1 || got[0].Reason != "server rejected the update" || !got[0].DetectedAt.Equal(at) {
+		t.Errorf("legacy conflicts = %+v", got)
+	}
+	if got := (Mutation{}).Conflicts(); got != nil {
+		t.Errorf("no conflict = %+v", got)
+	}
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// schemaMigration is one step of the cache schema. Steps run in version
+// order, each in its own transaction, and schema_version records the last
+// one applied. Steps must also apply cleanly to caches created before
+// versioning, which start at version 0 with some of the later changes in place.
+type schemaMigration struct {
+	version int
+	name    string
+	apply   func(tx *sql.Tx) error
+}
+
+var schemaMigrations = []schemaMigration{
+	{1, "base tables", createBaseTables},
+	{2, "mutation command UUIDs", addMutationUUIDs},
+	{3, "mutation dependencies", func(tx *sql.Tx) error {
+		return addColumnIfMissing(tx, "mutation_queue", "depends_on", "INTEGER NOT NULL DEFAULT 0")
+	}},
+	{4, "bulk edit groups", func(tx *sql.Tx) error {
+		return addColumnIfMissing(tx, "mutation_queue", "group_id", "TEXT NOT NULL DEFAULT ''")
+	}},
+	{5, "conflict server copies", func(tx *sql.Tx) error {
+		return addColumnIfMissing(tx, "mutation_queue", "remote", "TEXT NOT NULL DEFAULT ''")
+	}},
+	{6, "structured conflict records", migrateConflictRecords},
+	{7, "mutation claim times", func(tx *sql.Tx) error {
+		return addColumnIfMissing(tx, "mutation_queue", "claimed_at", "INTEGER NOT NULL DEFAULT 0")
+	}},
+}
+
+// errSchemaMismatch marks a cache written by a newer build, whose schema this
+// build can't migrate.
+var errSchemaMismatch = errors.New("cache schema mismatch")
+
+// migrate brings the cache schema up to date. A cache from a newer build is
+// rebuilt from scratch, keeping the mutation queue so no local change is lost.
+// Any other failure is returned as is: startup fails rather than dropping a
+// cache that a fixed build could still migrate.
+func migrate(db *sql.DB) error {
+	err := runMigrations(db)
+	if !errors.Is(err, errSchemaMismatch) {
+		return err
+	}
+	if rerr := rebuildCache(db); rerr != nil {
+		return fmt.Errorf("%w; rebuild: %v", err, rerr)
+	}
+	return nil
+}
+
+func runMigrations(db *sql.DB) error {
+	if _, err := db.Exec("CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)"); err != nil {
+		return err
+	}
+	var current int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&current); err != nil {
+		return err
+	}
+	latest := schemaMigrations[len(schemaMigrations)-1].version
+	if current > latest {
+		return fmt.Errorf("%w: version %d is newer than this build's %d", errSchemaMismatch, current, latest)
+	}
+
+	for _, step := range schemaMigrations {
+		if step.version <= current {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if err := step.apply(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s): %w", step.version, step.name, err)
+		}
+		if _, err := tx.Exec("DELETE FROM schema_version"); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO schema_version (version) VALUES (?)", step.version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildCache drops every table and recreates the schema, carrying the
+// mutation queue across. The sync token goes with sync_state, so the next
+// sync is a full one.
+func rebuildCache(db *sql.DB) error {
+	columns, saved, err := savedMutationRows(db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var tables []string
+	rows, err := tx.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	for _, name := range tables {
+		if _, err := tx.Exec(fmt.Sprintf("DROP TABLE %q", name)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if err := runMigrations(db); err != nil {
+		return err
+	}
+	return restoreMutationRows(db, columns, saved)
+}
+
+// savedMutationRows reads the mutation queue as raw rows, whatever columns the
+// old schema has. A cache without a queue table yields no rows; any other
+// error aborts the rebuild, so the queue is never dropped unread.
+func savedMutationRows(db *sql.DB) ([]string, [][]any, error) {
+	rows, err := db.Query("SELECT * FROM mutation_queue")
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	var saved [][]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, err
+		}
+		saved = append(saved, values)
+	}
+	return columns, saved, rows.Err()
+}
+
+// restoreMutationRows re-inserts saved queue rows into the rebuilt table,
+// keeping the columns both schemas share. Rows from a queue without command
+// UUIDs get fresh ones, and mutations caught mid-flush go back to pending.
+func restoreMutationRows(db *sql.DB, columns []string, saved [][]any) error {
+	if len(saved) == 0 {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current := make(map[string]bool)
+	names, err := tableColumns(tx, "mutation_queue")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		current[name] = true
+	}
+	var keep []int
+	var kept []string
+	for i, name := range columns {
+		if current[name] {
+			keep = append(keep, i)
+			kept = append(kept, name)
+		}
+	}
+	stmt := fmt.Sprintf("INSERT INTO mutation_queue (%s) VALUES (%s)",
+		strings.Join(kept, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(kept)), ", "))
+	for _, row := range saved {
+		args := make([]any, len(keep))
+		for i, col := range keep {
+			args[i] = row[col]
+		}
+		if _, err := tx.Exec(stmt, args...); err != nil {
+			return err
+		}
+	}
+	if err := addMutationUUIDs(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE mutation_queue SET status = 'pending' WHERE status = 'flushing'"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func createBaseTables(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+DROP TABLE IF EXISTS sync_meta;
+CREATE TABLE IF NOT EXISTS sync_state (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS projects (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS tasks (
+	id         TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sections (
+	id         TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS labels (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS filters (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS collapsed_projects (
+	id TEXT PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS user_names (
+	user_id    TEXT PRIMARY KEY,
+	full_name  TEXT NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS mutation_queue (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	entity_type TEXT NOT NULL,
+	entity_id   TEXT NOT NULL,
+	action      TEXT NOT NULL,
+	payload     TEXT NOT NULL DEFAULT '',
+	snapshot    TEXT NOT NULL DEFAULT '',
+	status      TEXT NOT NULL DEFAULT 'pending',
+	conflict    TEXT NOT NULL DEFAULT '',
+	created_at  INTEGER NOT NULL,
+	attempts    INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS completed_tasks (
+	id            TEXT PRIMARY KEY,
+	project_id    TEXT NOT NULL,
+	project_name  TEXT NOT NULL DEFAULT '',
+	data          TEXT NOT NULL,
+	completed_at  INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS comments (
+	id      TEXT PRIMARY KEY,
+	task_id TEXT NOT NULL,
+	data    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS completion_history (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id      TEXT NOT NULL,
+	due_date     TEXT NOT NULL DEFAULT '',
+	data         TEXT NOT NULL,
+	completed_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS archived_projects (
+	id          TEXT PRIMARY KEY,
+	data        TEXT NOT NULL,
+	archived_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);
+CREATE INDEX IF NOT EXISTS idx_sections_project ON sections(project_id);
+CREATE INDEX IF NOT EXISTS idx_completed_project ON completed_tasks(project_id);
+CREATE INDEX IF NOT EXISTS idx_completion_history_task ON completion_history(task_id);
+CREATE INDEX IF NOT EXISTS idx_comments_task ON comments(task_id);
+`)
+	return err
+}
+
+// addMutationUUIDs gives every queued mutation a stable Sync command UUID.
+func addMutationUUIDs(tx *sql.Tx) error {
+	if err := addColumnIfMissing(tx, "mutation_queue", "uuid", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("UPDATE mutation_queue SET uuid = lower(hex(randomblob(16))) WHERE uuid = ''")
+	return err
+}
+
+// migrateConflictRecords rewrites free-text conflict descriptions from older
+// queues as JSON conflict records.
+func migrateConflictRecords(tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT id, conflict, created_at FROM mutation_queue WHERE conflict <> '' AND NOT json_valid(conflict)")
+	if err != nil {
+		return err
+	}
+	type legacy struct {
+		id        int64
+		text      string
+		createdAt int64
+	}
+	var pending []legacy
+	for rows.Next() {
+		var l legacy
+		if err := rows.Scan(&l.id, &l.text, &l.createdAt); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, l := range pending {
+		blob, err := json.Marshal(legacyConflictRecords(l.text, time.Unix(l.createdAt, 0).UTC()))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE mutation_queue SET conflict = ? WHERE id = ?", string(blob), l.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tableColumns(tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func addColumnIfMissing(tx *sql.Tx, table, column, decl string) error {
+	names, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == column {
+			return nil
+		}
+	}
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl))
+	return err
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// preVersioningSchema is the cache schema from before schema_version existed:
+// sync_meta instead of sync_state, and a queue without command UUIDs,
+// dependencies, groups or server copies.
+const preVersioningSchema = `
+CREATE TABLE sync_meta (
+	resource_type TEXT,
+	scope_id      TEXT DEFAULT '',
+	last_synced   INTEGER,
+	PRIMARY KEY (resource_type, scope_id)
+);
+CREATE TABLE projects (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+CREATE TABLE tasks (id TEXT PRIMARY KEY, project_id TEXT NOT NULL, data TEXT NOT NULL);
+CREATE TABLE sections (id TEXT PRIMARY KEY, project_id TEXT NOT NULL, data TEXT NOT NULL);
+CREATE TABLE labels (id TEXT PRIMARY KEY, data TEXT NOT NULL);
+CREATE TABLE user_names (user_id TEXT PRIMARY KEY, full_name TEXT NOT NULL, updated_at INTEGER NOT NULL);
+CREATE TABLE mutation_queue (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	entity_type TEXT NOT NULL,
+	entity_id   TEXT NOT NULL,
+	action      TEXT NOT NULL,
+	payload     TEXT NOT NULL DEFAULT '',
+	snapshot    TEXT NOT NULL DEFAULT '',
+	status      TEXT NOT NULL DEFAULT 'pending',
+	conflict    TEXT NOT NULL DEFAULT '',
+	created_at  INTEGER NOT NULL,
+	attempts    INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE completed_tasks (
+	id           TEXT PRIMARY KEY,
+	project_id   TEXT NOT NULL,
+	project_name TEXT NOT NULL DEFAULT '',
+	data         TEXT NOT NULL,
+	completed_at INTEGER NOT NULL
+);
+CREATE TABLE archived_projects (id TEXT PRIMARY KEY, data TEXT NOT NULL, archived_at INTEGER NOT NULL);
+INSERT INTO sync_meta (resource_type, scope_id, last_synced) VALUES ('tasks', '', 1700000000);
+`
+
+const legacyConflictText = `content: you changed "a"→"b", server has "c"`
+
+func openFixture(t *testing.T) (string, *sql.DB) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cache.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return path, db
+}
+
+func mustExec(t *testing.T, db *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+// seedFixture adds a cached task and two queued mutations: a pending update
+// and a conflicted one with a free-text conflict, as older builds wrote them.
+func seedFixture(t *testing.T, db *sql.DB) {
+	t.Helper()
+	mustExec(t, db, `INSERT INTO tasks (id, project_id, data) VALUES ('t1', 'p1', '{"id":"t1","project_id":"p1","content":"cached"}')`)
+	mustExec(t, db, `INSERT INTO mutation_queue (entity_type, entity_id, action, payload, status, created_at)
+		VALUES ('task', 't1', 'update', '{"content":"x"}', 'pending', 1700000000)`)
+	mustExec(t, db, `INSERT INTO mutation_queue (entity_type, entity_id, action, payload, status, conflict, created_at)
+		VALUES ('task', 't2', 'update', '{"content":"b"}', 'conflicted', ?, 1700000100)`, legacyConflictText)
+	// Builds that had command UUIDs set one on every mutation they queued.
+	var hasUUID bool
+	_ = db.QueryRow("SELECT COUNT(*) > 0 FROM pragma_table_info('mutation_queue') WHERE name = 'uuid'").Scan(&hasUUID)
+	if hasUUID {
+		mustExec(t, db, "UPDATE mutation_queue SET uuid = 'uuid-' || id")
+	}
+}
+
+// checkMigrated opens the fixture through NewStore and checks the result is
+// the current schema with the fixture's cache and queue intact.
+func checkMigrated(t *testing.T, path string) {
+	t.Helper()
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+
+	var version int
+	if err := s.db.QueryRow("SELECT version FROM schema_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if latest := schemaMigrations[len(schemaMigrations)-1].version; version != latest {
+		t.Errorf("schema version = %d, want %d", version, latest)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns, err := tableColumns(tx, "mutation_queue")
+	tx.Rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, col := range []string{"uuid", "depends_on", "group_id", "remote"} {
+		if !slices.Contains(columns, col) {
+			t.Errorf("mutation_queue lacks %s: %v", col, columns)
+		}
+	}
+	var legacyTables int
+	_ = s.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'sync_meta'").Scan(&legacyTables)
+	if legacyTables != 0 {
+		t.Error("sync_meta survived the migration")
+	}
+	if task, _ := s.GetTaskByID("t1"); task == nil || task.Content != "cached" {
+		t.Errorf("cached task lost: %+v", task)
+	}
+
+	muts, err := s.GetAllMutations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(muts) != 2 {
+		t.Fatalf("queue has %d mutations, want 2", len(muts))
+	}
+	if muts[0].UUID == "" || muts[1].UUID == "" || muts[0].UUID == muts[1].UUID {
+		t.Errorf("command UUIDs = %q, %q, want distinct", muts[0].UUID, muts[1].UUID)
+	}
+	if muts[0].Status != MutationPending || muts[0].Conflict != "" {
+		t.Errorf("pending mutation = %s %q", muts[0].Status, muts[0].Conflict)
+	}
+	records := muts[1].Conflicts()
+	want := ConflictRecord{Field: "content", Base: "a", Local: "b", Remote: "c"}
+	if muts[1].Status != MutationConflicted || len(records) != 1 ||
+		records[0].Field != want.Field || records[0].Base != want.Base || records[0].Local != want.Local || records[0].Remote != want.Remote {
+		t.Errorf("conflicted mutation = %s %s", muts[1].Status, muts[1].Conflict)
+	}
+	if at := records[0].DetectedAt.Unix(); at != 1700000100 {
+		t.Errorf("legacy conflict detected at %d, want the mutation's creation time", at)
+	}
+}
+
+func TestMigratePreVersioningCache(t *testing.T) {
+	path, db := openFixture(t)
+	mustExec(t, db, preVersioningSchema)
+	seedFixture(t, db)
+	db.Close()
+	checkMigrated(t, path)
+}
+
+// TestMigrateFromEachVersion builds a cache at every intermediate schema
+// version and migrates it to the latest.
+func TestMigrateFromEachVersion(t *testing.T) {
+	for _, upTo := range schemaMigrations[:len(schemaMigrations)-1] {
+		t.Run(upTo.name, func(t *testing.T) {
+			path, db := openFixture(t)
+			mustExec(t, db, "CREATE TABLE schema_version (version INTEGER NOT NULL)")
+			for _, step := range schemaMigrations {
+				if step.version > upTo.version {
+					break
+				}
+				tx, err := db.Begin()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := step.apply(tx); err != nil {
+					t.Fatalf("migration %d: %v", step.version, err)
+				}
+				if err := tx.Commit(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			mustExec(t, db, "INSERT INTO schema_version (version) VALUES (?)", upTo.version)
+			seedFixture(t, db)
+			db.Close()
+			checkMigrated(t, path)
+		})
+	}
+}
+
+func TestMigrateNewerCacheRebuildsKeepingQueue(t *testing.T) {
+	path, db := openFixture(t)
+	// A newer build's cache: unknown version, and a queue table whose shape
+	// this build doesn't know (here, without command UUIDs).
+	mustExec(t, db, preVersioningSchema)
+	mustExec(t, db, "CREATE TABLE schema_version (version INTEGER NOT NULL); INSERT INTO schema_version VALUES (99)")
+	seedFixture(t, db)
+	mustExec(t, db, "UPDATE mutation_queue SET status = 'flushing' WHERE entity_id = 't1'")
+	db.Close()
+
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer s.Close()
+	if task, _ := s.GetTaskByID("t1"); task != nil {
+		t.Error("rebuild kept cached tasks from the newer schema")
+	}
+	muts, err := s.GetAllMutations()
+	if err != nil || len(muts) != 2 {
+		t.Fatalf("queue after rebuild: %d mutations (%v), want 2", len(muts), err)
+	}
+	for _, m := range muts {
+		if m.UUID == "" {
+			t.Errorf("mutation %d restored without a command UUID", m.ID)
+		}
+	}
+	if muts[0].Status != MutationPending {
+		t.Errorf("mid-flush mutation restored as %s, want pending", muts[0].Status)
+	}
+}
+
+func TestMigrateFailureKeepsCache(t *testing.T) {
+	path, db := openFixture(t)
+	// Version 1 claims the base tables exist, but the queue is missing, so
+	// migration 2 fails. That is a bug to fix, not a reason to drop the cache.
+	mustExec(t, db, preVersioningSchema)
+	mustExec(t, db, "DROP TABLE mutation_queue")
+	mustExec(t, db, "CREATE TABLE schema_version (version INTEGER NOT NULL); INSERT INTO schema_version VALUES (1)")
+	mustExec(t, db, `INSERT INTO tasks (id, project_id, data) VALUES ('t1', 'p1', '{"id":"t1"}')`)
+	db.Close()
+
+	if s, err := NewStore(path); err == nil {
+		s.Close()
+		t.Fatal("NewStore succeeded on a cache whose migration fails")
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&n); err != nil || n != 1 {
+		t.Errorf("cache was dropped after a failed migration: %d tasks (%v)", n, err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -8,10 +9,19 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// ProjectsView is the sidebar project list.
-// cursor=0 is the virtual "Today" entry; cursor>=1 maps to projects[cursor-1].
+// ProjectsView is the sidebar project list, rendered as the project tree.
+// cursor=0 is the virtual "Today" entry; cursor>=1 maps to projects[cursor-1]
+// (the visible projects, skipping collapsed subtrees),
+// followed by the "Filters" group at filters[cursor-1-len(projects)] and the
+// "Labels" group after it.
 type ProjectsView struct {
 	projects []Project
+	tree     []Project       // every project in tree order, including hidden ones
+	depth    map[string]int  // nesting level by project ID
+	parents  map[string]bool // projects that have subprojects
+	folded   map[string]bool // collapsed project IDs, persisted in the store
+	filters  []SavedFilter
+	labels   []Label
 	cursor   int
 	width    int
 	height   int
@@ -36,7 +46,7 @@ func NewProjectsView(repo *Repository) ProjectsView {
 }
 
 func (v ProjectsView) Init() tea.Cmd {
-	return v.repo.FetchProjects()
+	return v.repo.LoadCachedProjects()
 }
 
 // IsTodaySelected returns true when the virtual Today entry is selected.
@@ -46,18 +56,15 @@ func (v ProjectsView) IsTodaySelected() bool {
 
 func (v ProjectsView) Update(msg tea.Msg) (ProjectsView, tea.Cmd) {
 	switch msg := msg.(type) {
-	case cachedProjectsMsg:
-		v.projects = sortProjects(msg.projects)
-		// cursor stays at 0 (Today) on first load
-		return v, v.repo.RefreshProjects()
-
 	case projectsMsg:
 		if msg.err != nil {
 			return v, func() tea.Msg {
 				return toastMsg{text: "Failed to load projects: " + msg.err.Error(), isError: true}
 			}
 		}
-		v.projects = sortProjects(msg.projects)
+		v.setProjects(msg.projects)
+		v.filters = v.repo.GetCachedFilters()
+		v.labels = v.repo.GetCachedLabels()
 		return v, nil
 
 	case projectCreatedMsg:
@@ -67,7 +74,7 @@ func (v ProjectsView) Update(msg tea.Msg) (ProjectsView, tea.Cmd) {
 			}
 		}
 		return v, tea.Batch(
-			v.repo.RefreshProjects(),
+			v.repo.PerformSync(),
 			func() tea.Msg { return toastMsg{text: "List created", isError: false} },
 		)
 
@@ -78,15 +85,16 @@ func (v ProjectsView) Update(msg tea.Msg) (ProjectsView, tea.Cmd) {
 			}
 		}
 		// Remove archived project from local list
-		for i, p := range v.projects {
-			if p.ID == msg.projectID {
-				v.projects = append(v.projects[:i], v.projects[i+1:]...)
-				break
+		remaining := make([]Project, 0, len(v.tree))
+		for _, p := range v.tree {
+			if p.ID != msg.projectID {
+				remaining = append(remaining, p)
 			}
 		}
-		// Clamp cursor (remember: max valid is len(projects) since 0=Today)
-		if v.cursor > len(v.projects) {
-			v.cursor = len(v.projects)
+		v.setProjects(remaining)
+		// Clamp cursor (remember: 0=Today, filters and labels follow the projects)
+		if v.cursor >= v.itemCount() {
+			v.cursor = v.itemCount() - 1
 		}
 		if v.cursor < 0 {
 			v.cursor = 0
@@ -150,9 +158,10 @@ func (v ProjectsView) handleKey(msg tea.KeyMsg) (ProjectsView, tea.Cmd) {
 		return v, nil
 	}
 
-	// Normal mode — bounds: 0 (Today) to len(projects) inclusive
-	maxCursor := len(v.projects) // cursor=len(projects) maps to projects[len-1]
-	switch ResolveAction(ContextMainSidebar, msg.String()) {
+	// Normal mode — bounds: 0 (Today) to the last label inclusive
+	maxCursor := v.itemCount() - 1
+	action := ResolveAction(ContextMainSidebar, msg.String())
+	switch action {
 	case ActionNavDown:
 		if v.cursor < maxCursor {
 			v.cursor++
@@ -175,75 +184,254 @@ func (v ProjectsView) handleKey(msg tea.KeyMsg) (ProjectsView, tea.Cmd) {
 		v.addInput.Focus()
 		return v, textinput.Blink
 	case ActionArchiveProject:
-		// No-op for Today (cursor=0) or Inbox
-		if v.cursor == 0 {
-			return v, nil
-		}
+		// No-op for Today (cursor=0), labels or Inbox
 		p := v.SelectedProject()
 		if p != nil && !p.InboxProject {
 			v.mode = "archive"
 		}
 		return v, nil
+	case ActionToggleCollapse:
+		if p := v.SelectedProject(); p != nil && v.parents[p.ID] {
+			v.setFolded(p.ID, !v.folded[p.ID])
+		}
+		return v, nil
+	case ActionIndent:
+		// Nest under the sibling above, as for tasks.
+		p := v.SelectedProject()
+		if p == nil || p.InboxProject {
+			return v, nil
+		}
+		siblings := v.siblings(*p)
+		i := slices.IndexFunc(siblings, func(s Project) bool { return s.ID == p.ID })
+		if i <= 0 {
+			return v, nil
+		}
+		parentID := siblings[i-1].ID
+		v.setFolded(parentID, false)
+		return v, v.repo.MoveProject(p.ID, &parentID)
+	case ActionOutdent:
+		p := v.SelectedProject()
+		if p == nil || p.ParentID == nil || *p.ParentID == "" {
+			return v, nil
+		}
+		var grandparent *string
+		for _, q := range v.tree {
+			if q.ID == *p.ParentID {
+				grandparent = q.ParentID
+			}
+		}
+		return v, v.repo.MoveProject(p.ID, grandparent)
+	case ActionMoveDown, ActionMoveUp:
+		p := v.SelectedProject()
+		if p == nil || p.InboxProject {
+			return v, nil
+		}
+		delta := 1
+		if action == ActionMoveUp {
+			delta = -1
+		}
+		return v, v.repo.ReorderProject(p.ID, delta)
 	}
 
 	return v, nil
 }
 
+// setProjects rebuilds the tree from the cached projects and hides the
+// subtrees of collapsed projects.
+func (v *ProjectsView) setProjects(projects []Project) {
+	v.tree = sortProjects(projects)
+	v.depth = projectDepths(v.tree)
+	v.parents = make(map[string]bool)
+	for _, p := range v.tree {
+		if p.ParentID != nil {
+			v.parents[*p.ParentID] = true
+		}
+	}
+	if v.folded == nil {
+		v.folded = v.repo.GetCollapsedProjects()
+	}
+	v.applyFolds()
+}
+
+func (v *ProjectsView) applyFolds() {
+	v.projects = v.projects[:0:0]
+	hideBelow := -1
+	for _, p := range v.tree {
+		d := v.depth[p.ID]
+		if hideBelow >= 0 && d > hideBelow {
+			continue
+		}
+		hideBelow = -1
+		v.projects = append(v.projects, p)
+		if v.folded[p.ID] && v.parents[p.ID] {
+			hideBelow = d
+		}
+	}
+}
+
+// setFolded collapses or expands a project, keeping the cursor on the
+// selected entry.
+func (v *ProjectsView) setFolded(id string, folded bool) {
+	if v.folded[id] == folded {
+		return
+	}
+	selected := v.SelectionKey()
+	if v.folded == nil {
+		v.folded = make(map[string]bool)
+	}
+	if folded {
+		v.folded[id] = true
+	} else {
+		delete(v.folded, id)
+	}
+	v.repo.SetProjectCollapsed(id, folded)
+	v.applyFolds()
+	if selected != "" {
+		v.selectByKey(selected)
+	}
+}
+
+// siblings returns the projects sharing p's parent, in sidebar order.
+func (v ProjectsView) siblings(p Project) []Project {
+	var out []Project
+	for _, q := range v.tree {
+		if sameParent(q.ParentID, p.ParentID) && !q.InboxProject {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
 func (v ProjectsView) handlesInput() bool {
 	return v.mode != ""
 }
 
-func (v ProjectsView) View() string {
-	if len(v.projects) == 0 {
-		return emptyStyle.Render("No projects")
-	}
+// itemCount is the number of selectable sidebar entries: Today, projects,
+// filters, labels.
+func (v ProjectsView) itemCount() int {
+	return 1 + len(v.projects) + len(v.filters) + len(v.labels)
+}
 
-	var b strings.Builder
-	b.WriteString(sidebarTitleStyle.Render("Projects"))
-	b.WriteString("\n")
+// Sidebar group headers are rendered rows with no cursor position.
+const (
+	sidebarLabelsHeader  = -1
+	sidebarFiltersHeader = -2
+)
 
-	// Total items = 1 (Today) + len(projects)
-	totalItems := 1 + len(v.projects)
+// sidebarRows maps rendered rows to cursor positions; negative entries mark
+// the non-selectable group headers.
+func (v ProjectsView) sidebarRows() []int {
+	rows := make([]int, 0, v.itemCount()+2)
+	for i := 0; i <= len(v.projects); i++ {
+		rows = append(rows, i)
+	}
+	next := len(v.projects) + 1
+	if len(v.filters) > 0 {
+		rows = append(rows, sidebarFiltersHeader)
+		for range v.filters {
+			rows = append(rows, next)
+			next++
+		}
+	}
+	if len(v.labels) > 0 {
+		rows = append(rows, sidebarLabelsHeader)
+		for range v.labels {
+			rows = append(rows, next)
+			next++
+		}
+	}
+	return rows
+}
 
+// visibleRows returns the scrolling window over sidebarRows that keeps the
+// cursor in view.
+func (v ProjectsView) visibleRows() (rows []int, start, end int) {
+	rows = v.sidebarRows()
 	maxVisible := v.height - 3
 	if maxVisible < 1 {
 		maxVisible = 1
 	}
-
-	// Scrolling window over the combined list
-	start := 0
-	if v.cursor >= maxVisible {
-		start = v.cursor - maxVisible + 1
+	cursorRow := 0
+	for r, item := range rows {
+		if item == v.cursor {
+			cursorRow = r
+		}
+	}
+	if cursorRow >= maxVisible {
+		start = cursorRow - maxVisible + 1
 	}
-	end := start + maxVisible
-	if end > totalItems {
-		end = totalItems
+	end = start + maxVisible
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return rows, start, end
+}
+
+func (v ProjectsView) View() string {
+	if len(v.projects) == 0 {
+		return emptyStyle.Render("No projects")
 	}
 
-	for i := start; i < end; i++ {
+	var b strings.Builder
+	b.WriteString(sidebarTitleStyle.Render("Projects"))
+	b.WriteString("\n")
+
+	rows, start, end := v.visibleRows()
+	for r := start; r < end; r++ {
+		i := rows[r]
 		selected := i == v.cursor
 
-		if i == 0 {
+		switch {
+		case i == sidebarLabelsHeader:
+			b.WriteString(lipgloss.NewStyle().Foreground(colorTextDim).Bold(true).Render("Labels"))
+		case i == sidebarFiltersHeader:
+			b.WriteString(lipgloss.NewStyle().Foreground(colorTextDim).Bold(true).Render("Filters"))
+		case i == 0:
 			// Virtual "Today" entry
 			line := "☀ Today"
 			if selected {
-				if v.focused {
-					b.WriteString(projectSelectedStyle.Width(v.width - 2).Render(line))
-				} else {
-					b.WriteString(lipgloss.NewStyle().
-						Foreground(colorBright).
-						Padding(0, 1).
-						Width(v.width - 2).
-						Render(line))
-				}
+				b.WriteString(v.renderSelected(line))
 			} else {
 				b.WriteString(projectNormalStyle.Width(v.width - 2).Render(
 					lipgloss.NewStyle().Foreground(colorYellow).Render("☀") + " Today"))
 			}
-		} else {
+		case i > len(v.projects)+len(v.filters):
+			l := v.labels[i-1-len(v.projects)-len(v.filters)]
+			name := truncate(l.Name, v.width-7)
+			if selected {
+				b.WriteString(v.renderSelected("@ " + name))
+			} else {
+				dot := lipgloss.NewStyle().Foreground(projectColor(l.Color)).Render("@")
+				b.WriteString(projectNormalStyle.Width(v.width - 2).Render(dot + " " + name))
+			}
+		case i > len(v.projects):
+			f := v.filters[i-1-len(v.projects)]
+			name := truncate(f.Name, v.width-8)
+			if selected {
+				line := "⚲ " + name
+				if f.IsFavorite {
+					line += " ★"
+				}
+				b.WriteString(v.renderSelected(line))
+			} else {
+				line := lipgloss.NewStyle().Foreground(projectColor(f.Color)).Render("⚲") + " " + name
+				if f.IsFavorite {
+					line += " " + projectFavStyle.Render("★")
+				}
+				b.WriteString(projectNormalStyle.Width(v.width - 2).Render(line))
+			}
+		default:
 			// Real project at projects[i-1]
 			p := v.projects[i-1]
-			name := truncate(p.Name, v.width-6)
+			indent := strings.Repeat("  ", v.depth[p.ID])
+			fold := ""
+			if v.parents[p.ID] {
+				fold = "▾ "
+				if v.folded[p.ID] {
+					fold = "▸ "
+				}
+			}
+			name := truncate(p.Name, v.width-6-len(indent)-lipgloss.Width(fold))
 
 			dotChar := "●"
 			if p.InboxProject {
@@ -251,32 +439,24 @@ func (v ProjectsView) View() string {
 			}
 
 			if selected {
-				line := dotChar + " " + name
+				line := indent + fold + dotChar + " " + name
 				if p.IsFavorite {
 					line += " ★"
 				}
-				if v.focused {
-					b.WriteString(projectSelectedStyle.Width(v.width - 2).Render(line))
-				} else {
-					b.WriteString(lipgloss.NewStyle().
-						Foreground(colorBright).
-						Padding(0, 1).
-						Width(v.width - 2).
-						Render(line))
-				}
+				b.WriteString(v.renderSelected(line))
 			} else {
 				dot := lipgloss.NewStyle().Foreground(projectColor(p.Color)).Render(dotChar)
 				if p.InboxProject {
 					dot = lipgloss.NewStyle().Foreground(colorBlue).Render(dotChar)
 				}
-				line := dot + " " + name
+				line := indent + lipgloss.NewStyle().Foreground(colorTextDim).Render(fold) + dot + " " + name
 				if p.IsFavorite {
 					line += " " + projectFavStyle.Render("★")
 				}
 				b.WriteString(projectNormalStyle.Width(v.width - 2).Render(line))
 			}
 		}
-		if i < end-1 {
+		if r < end-1 {
 			b.WriteString("\n")
 		}
 	}
@@ -301,6 +481,18 @@ func (v ProjectsView) View() string {
 	return b.String()
 }
 
+// renderSelected renders the cursor row, highlighted only while focused.
+func (v ProjectsView) renderSelected(line string) string {
+	if v.focused {
+		return projectSelectedStyle.Width(v.width - 2).Render(line)
+	}
+	return lipgloss.NewStyle().
+		Foreground(colorBright).
+		Padding(0, 1).
+		Width(v.width - 2).
+		Render(line)
+}
+
 func (v *ProjectsView) SetSize(width, height int) {
 	v.width = width
 	v.height = height
@@ -327,6 +519,38 @@ func (v ProjectsView) SelectedProject() *Project {
 	return nil
 }
 
+// SelectedFilter returns the selected saved filter, or nil if the cursor is
+// not in the Filters group.
+func (v ProjectsView) SelectedFilter() *SavedFilter {
+	idx := v.cursor - 1 - len(v.projects)
+	if idx >= 0 && idx < len(v.filters) {
+		return &v.filters[idx]
+	}
+	return nil
+}
+
+// SelectedLabel returns the selected label, or nil if the cursor is not in
+// the Labels group.
+func (v ProjectsView) SelectedLabel() *Label {
+	idx := v.cursor - 1 - len(v.projects) - len(v.filters)
+	if idx >= 0 && idx < len(v.labels) {
+		return &v.labels[idx]
+	}
+	return nil
+}
+
+// SelectionKey identifies the selected entry: "" for Today, a project ID,
+// "filter:" plus a filter ID, or "@" plus a label name.
+func (v ProjectsView) SelectionKey() string {
+	if f := v.SelectedFilter(); f != nil {
+		return "filter:" + f.ID
+	}
+	if l := v.SelectedLabel(); l != nil {
+		return "@" + l.Name
+	}
+	return v.SelectedProjectID()
+}
+
 // SelectedProjectID returns the ID of the selected project, or "" if Today is selected.
 func (v ProjectsView) SelectedProjectID() string {
 	if p := v.SelectedProject(); p != nil {
@@ -341,12 +565,9 @@ func (v ProjectsView) HandleMouse(m tea.MouseEvent, yOffset int) (ProjectsView,
 		return v, nil
 	}
 
-	// Total items = 1 (Today) + len(projects)
-	totalItems := 1 + len(v.projects)
-
 	// Scroll wheel
 	if m.Button == tea.MouseButtonWheelDown {
-		if v.cursor < totalItems-1 {
+		if v.cursor < v.itemCount()-1 {
 			v.cursor++
 		}
 		return v, nil
@@ -367,29 +588,53 @@ func (v ProjectsView) HandleMouse(m tea.MouseEvent, yOffset int) (ProjectsView,
 	itemOffset := localY - 2 // subtract title + margin
 
 	// Recompute scroll window (same as View)
-	maxVisible := v.height - 3
-	if maxVisible < 1 {
-		maxVisible = 1
-	}
-	start := 0
-	if v.cursor >= maxVisible {
-		start = v.cursor - maxVisible + 1
-	}
-	end := start + maxVisible
-	if end > totalItems {
-		end = totalItems
+	rows, start, end := v.visibleRows()
+	clicked := start + itemOffset
+	if clicked >= start && clicked < end && rows[clicked] >= 0 {
+		v.cursor = rows[clicked]
 	}
 
-	clickedIndex := start + itemOffset
-	if clickedIndex >= start && clickedIndex < end {
-		v.cursor = clickedIndex
+	return v, nil
+}
+
+// Reload re-reads the project, filter and label lists from the cache, keeping
+// the selected entry under the cursor when it still exists.
+func (v *ProjectsView) Reload() {
+	selected := v.SelectionKey()
+	v.setProjects(v.repo.GetCachedProjects())
+	v.filters = v.repo.GetCachedFilters()
+	v.labels = v.repo.GetCachedLabels()
+	if selected == "" || !v.selectByKey(selected) {
+		if v.cursor >= v.itemCount() {
+			v.cursor = v.itemCount() - 1
+		}
 	}
+}
 
-	return v, nil
+func (v *ProjectsView) selectByKey(key string) bool {
+	if id, ok := strings.CutPrefix(key, "filter:"); ok {
+		for i, f := range v.filters {
+			if f.ID == id {
+				v.cursor = 1 + len(v.projects) + i
+				return true
+			}
+		}
+		return false
+	}
+	if name, ok := strings.CutPrefix(key, "@"); ok {
+		for i, l := range v.labels {
+			if l.Name == name {
+				v.cursor = 1 + len(v.projects) + len(v.filters) + i
+				return true
+			}
+		}
+		return false
+	}
+	return v.SelectProjectByID(key)
 }
 
-// SelectProjectByID moves the cursor to the project with the given ID.
-// Returns true if found, false if not found.
+// SelectProjectByID moves the cursor to the project with the given ID,
+// expanding collapsed ancestors that hide it. Returns true if found.
 func (v *ProjectsView) SelectProjectByID(id string) bool {
 	for i, p := range v.projects {
 		if p.ID == id {
@@ -397,6 +642,28 @@ func (v *ProjectsView) SelectProjectByID(id string) bool {
 			return true
 		}
 	}
+	byID := make(map[string]Project, len(v.tree))
+	for _, p := range v.tree {
+		byID[p.ID] = p
+	}
+	p, ok := byID[id]
+	if !ok {
+		return false
+	}
+	for p.ParentID != nil {
+		parent, ok := byID[*p.ParentID]
+		if !ok {
+			break
+		}
+		v.setFolded(parent.ID, false)
+		p = parent
+	}
+	for i, p := range v.projects {
+		if p.ID == id {
+			v.cursor = i + 1
+			return true
+		}
+	}
 	return false
 }
 
@@ -404,29 +671,71 @@ func (v *ProjectsView) SelectToday() {
 	v.cursor = 0
 }
 
-// sortProjects puts Inbox first, then favorites, then the rest by order
+// sortProjects puts Inbox first, then the project tree depth-first with
+// siblings by ChildOrder. Projects whose parent is not cached count as roots.
 func sortProjects(projects []Project) []Project {
+	ids := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		ids[p.ID] = true
+	}
+	children := make(map[string][]Project)
 	var inbox []Project
-	var favs []Project
-	var rest []Project
-
 	for _, p := range projects {
-		if p.InboxProject {
+		switch {
+		case p.InboxProject:
 			inbox = append(inbox, p)
-		} else if p.IsFavorite {
-			favs = append(favs, p)
-		} else {
-			rest = append(rest, p)
+		case p.ParentID != nil && ids[*p.ParentID]:
+			children[*p.ParentID] = append(children[*p.ParentID], p)
+		default:
+			children[""] = append(children[""], p)
 		}
 	}
 
 	result := make([]Project, 0, len(projects))
 	result = append(result, inbox...)
-	result = append(result, favs...)
-	result = append(result, rest...)
+	seen := make(map[string]bool, len(projects))
+	var walk func(parentID string)
+	walk = func(parentID string) {
+		kids := children[parentID]
+		slices.SortStableFunc(kids, func(a, b Project) int { return a.ChildOrder - b.ChildOrder })
+		for _, p := range kids {
+			if seen[p.ID] {
+				continue
+			}
+			seen[p.ID] = true
+			result = append(result, p)
+			walk(p.ID)
+		}
+	}
+	walk("")
 	return result
 }
 
+// projectDepths returns each project's nesting level; roots are 0. Parents
+// that are not in the list end the chain, as in sortProjects.
+func projectDepths(projects []Project) map[string]int {
+	parent := make(map[string]string, len(projects))
+	for _, p := range projects {
+		parent[p.ID] = ""
+	}
+	for _, p := range projects {
+		if p.ParentID != nil {
+			if _, ok := parent[*p.ParentID]; ok {
+				parent[p.ID] = *p.ParentID
+			}
+		}
+	}
+	depths := make(map[string]int, len(projects))
+	for _, p := range projects {
+		d := 0
+		for id := parent[p.ID]; id != "" && d < len(projects); id = parent[id] {
+			d++
+		}
+		depths[p.ID] = d
+	}
+	return depths
+}
+
 // projectColor maps Todoist color names to lipgloss colors
 func projectColor(name string) lipgloss.Color {
 	if hex, ok := colorHex[name]; ok {
@@ -3,34 +3,128 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// QueueView displays pending and conflicted mutations
+// QueueView displays pending and conflicted mutations. Mutations queued by
+// one bulk edit are listed, retried and dismissed as a single unit.
 type QueueView struct {
 	repo         *Repository
 	mutations    []Mutation
-	cursor       int
+	units        []queueUnit
+	cursor       int    // index into units
 	confirmClear string // "", "conflicts", "all"
+
+	// Resolution dialog for a conflicted task update: one choice per
+	// conflicting field.
+	resolving   *Mutation
+	conflicts   []ConflictRecord
+	choices     []string // "mine", "theirs" or "edit"
+	edits       []string
+	fieldCursor int
+	editInput   textinput.Model
+}
+
+// queueUnit is one selectable row: a single mutation, or the mutations of a
+// bulk edit that share a status.
+type queueUnit struct {
+	mutations  []Mutation
+	conflicted bool
+}
+
+func (u queueUnit) ids() []int64 {
+	ids := make([]int64, len(u.mutations))
+	for i, m := range u.mutations {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func (u queueUnit) grouped() bool {
+	return len(u.mutations) > 1
+}
+
+// queueRow is one rendered line; unit is -1 for headers and spacing.
+type queueRow struct {
+	text string
+	unit int
 }
 
 func NewQueueView(repo *Repository) QueueView {
-	return QueueView{repo: repo}
+	ei := textinput.New()
+	ei.CharLimit = 500
+	return QueueView{repo: repo, editInput: ei}
+}
+
+func (v QueueView) handlesInput() bool {
+	return v.confirmClear != "" || v.resolving != nil
+}
+
+// inputContext returns the key context for the active dialog.
+func (v QueueView) inputContext() InputContext {
+	switch {
+	case v.editInput.Focused():
+		return ContextMainTasksSearch
+	case v.resolving != nil:
+		return ContextQueueResolve
+	case v.confirmClear != "":
+		return ContextMainSidebarDialog
+	}
+	return ContextQueueOverlay
 }
 
 func (v *QueueView) Refresh() {
-	v.mutations = v.repo.GetAllMutations()
-	if v.cursor >= len(v.mutations) {
-		v.cursor = len(v.mutations) - 1
+	v.setMutations(v.repo.GetAllMutations())
+}
+
+func (v *QueueView) setMutations(muts []Mutation) {
+	v.mutations = muts
+	v.units = buildQueueUnits(muts)
+	if v.cursor >= len(v.units) {
+		v.cursor = len(v.units) - 1
 	}
 	if v.cursor < 0 {
 		v.cursor = 0
 	}
 }
 
+// buildQueueUnits splits mutations into pending then conflicted units,
+// folding the members of each bulk edit into one unit per status.
+func buildQueueUnits(muts []Mutation) []queueUnit {
+	var units []queueUnit
+	for _, conflicted := range []bool{false, true} {
+		groups := make(map[string]int)
+		for _, m := range muts {
+			if (m.Status == MutationConflicted) != conflicted {
+				continue
+			}
+			if m.GroupID != "" {
+				if i, ok := groups[m.GroupID]; ok {
+					units[i].mutations = append(units[i].mutations, m)
+					continue
+				}
+				groups[m.GroupID] = len(units)
+			}
+			units = append(units, queueUnit{mutations: []Mutation{m}, conflicted: conflicted})
+		}
+	}
+	return units
+}
+
+func (v QueueView) selectedUnit() (queueUnit, bool) {
+	if v.cursor < 0 || v.cursor >= len(v.units) {
+		return queueUnit{}, false
+	}
+	return v.units[v.cursor], true
+}
+
 func (v QueueView) Update(msg tea.Msg) (QueueView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.MouseMsg:
@@ -40,7 +134,7 @@ func (v QueueView) Update(msg tea.Msg) (QueueView, tea.Cmd) {
 		}
 		// Scroll wheel
 		if m.Button == tea.MouseButtonWheelDown {
-			if v.cursor < len(v.mutations)-1 {
+			if v.cursor < len(v.units)-1 {
 				v.cursor++
 			}
 			return v, nil
@@ -55,56 +149,17 @@ func (v QueueView) Update(msg tea.Msg) (QueueView, tea.Cmd) {
 		if m.Button == tea.MouseButtonLeft {
 			// helpStyle has Padding(1,2): 1 row top pad, then title, MarginBottom(1), blank line
 			contentY := m.Y - 4
-			if contentY < 0 {
-				return v, nil
-			}
-
-			// Walk groups same as View() to build line-to-index map
-			var pending, conflicted []indexedMutation
-			for i, mt := range v.mutations {
-				im := indexedMutation{index: i, mutation: mt}
-				if mt.Status == MutationConflicted {
-					conflicted = append(conflicted, im)
-				} else {
-					pending = append(pending, im)
-				}
-			}
-
-			line := 0
-			if len(pending) > 0 {
-				line++ // section header
-				for _, im := range pending {
-					if line == contentY {
-						v.cursor = im.index
-						return v, nil
-					}
-					line++ // item line
-					if im.index == v.cursor {
-						// selected items don't have extra lines in pending
-					}
-				}
-				line++ // blank line after section
-			}
-			if len(conflicted) > 0 {
-				line++ // section header
-				for _, im := range conflicted {
-					if line == contentY {
-						v.cursor = im.index
-						return v, nil
-					}
-					line++ // item line
-					if im.mutation.Conflict != "" {
-						line++ // conflict detail line
-					}
-					if im.index == v.cursor {
-						line++ // action hints line
-					}
-				}
+			rows := v.rows(0)
+			if contentY >= 0 && contentY < len(rows) && rows[contentY].unit >= 0 {
+				v.cursor = rows[contentY].unit
 			}
 		}
 		return v, nil
 
 	case tea.KeyMsg:
+		if v.resolving != nil {
+			return v.handleResolveKey(msg)
+		}
 		if v.confirmClear != "" {
 			switch ResolveAction(ContextMainSidebarDialog, msg.String()) {
 			case ActionConfirm:
@@ -127,7 +182,7 @@ func (v QueueView) Update(msg tea.Msg) (QueueView, tea.Cmd) {
 		}
 		switch ResolveAction(ContextQueueOverlay, msg.String()) {
 		case ActionNavDown:
-			if v.cursor < len(v.mutations)-1 {
+			if v.cursor < len(v.units)-1 {
 				v.cursor++
 			}
 			return v, nil
@@ -137,27 +192,37 @@ func (v QueueView) Update(msg tea.Msg) (QueueView, tea.Cmd) {
 			}
 			return v, nil
 		case ActionRetry:
-			if v.cursor >= 0 && v.cursor < len(v.mutations) {
-				m := v.mutations[v.cursor]
-				if m.Status == MutationConflicted || m.Status == MutationFlushing {
-					return v, v.repo.RetryMutation(m.ID)
+			if u, ok := v.selectedUnit(); ok {
+				var ids []int64
+				for _, m := range u.mutations {
+					if m.Status == MutationConflicted || m.Status == MutationFlushing {
+						ids = append(ids, m.ID)
+					}
+				}
+				if len(ids) > 0 {
+					return v, v.repo.RetryMutation(ids...)
 				}
 			}
 			return v, nil
 		case ActionDismiss:
-			if v.cursor >= 0 && v.cursor < len(v.mutations) {
-				m := v.mutations[v.cursor]
-				cmd := v.repo.DismissMutation(m.ID)
-				v.mutations = append(v.mutations[:v.cursor], v.mutations[v.cursor+1:]...)
-				if v.cursor >= len(v.mutations) {
-					v.cursor = len(v.mutations) - 1
-				}
-				if v.cursor < 0 {
-					v.cursor = 0
+			if u, ok := v.selectedUnit(); ok {
+				ids := u.ids()
+				cmd := v.repo.DismissMutation(ids...)
+				remaining := make([]Mutation, 0, len(v.mutations))
+				for _, m := range v.mutations {
+					if !slices.Contains(ids, m.ID) {
+						remaining = append(remaining, m)
+					}
 				}
+				v.setMutations(remaining)
 				return v, cmd
 			}
 			return v, nil
+		case ActionResolveConflict:
+			if u, ok := v.selectedUnit(); ok && u.conflicted && !u.grouped() {
+				return v.openResolve(u.mutations[0])
+			}
+			return v, nil
 		case ActionClearConflicts:
 			v.confirmClear = "conflicts"
 			return v, nil
@@ -169,6 +234,76 @@ func (v QueueView) Update(msg tea.Msg) (QueueView, tea.Cmd) {
 	return v, nil
 }
 
+// rows lays out the pending and conflict sections line by line. View renders
+// them and mouse clicks map back through them.
+func (v QueueView) rows(width int) []queueRow {
+	var rows []queueRow
+	add := func(text string, unit int) {
+		rows = append(rows, queueRow{text: text, unit: unit})
+	}
+
+	var pending, conflicted int
+	for _, m := range v.mutations {
+		if m.Status == MutationConflicted {
+			conflicted++
+		} else {
+			pending++
+		}
+	}
+
+	for _, section := range []bool{false, true} {
+		count, title := pending, "Pending"
+		if section {
+			count, title = conflicted, "Conflicts"
+		}
+		if count == 0 {
+			continue
+		}
+		add(queueTitleStyle.Render(fmt.Sprintf("━━ %s (%d)", title, count)), -1)
+		for i, u := range v.units {
+			if u.conflicted != section {
+				continue
+			}
+			selected := i == v.cursor
+			itemStyle := queueItemStyle
+			if u.conflicted {
+				itemStyle = queueConflictStyle
+			}
+			line := "  " + renderUnitLine(u)
+			if selected {
+				add(queueSelectedStyle.Width(width-4).Render(line), i)
+			} else {
+				add(itemStyle.Render(line), i)
+			}
+			if !u.grouped() {
+				if u.conflicted {
+					for _, l := range conflictLines(u.mutations[0], "    ", width) {
+						add(l, i)
+					}
+				}
+			} else if selected {
+				for _, m := range u.mutations {
+					add(itemStyle.Render("      "+renderMutationLine(m)), i)
+					for _, l := range conflictLines(m, "        ", width) {
+						add(l, i)
+					}
+				}
+			}
+			if selected && u.conflicted {
+				hint := "    " + footerKeyStyle.Render("r") + " retry  " + footerKeyStyle.Render("d") + " dismiss"
+				if !u.grouped() && resolvable(u.mutations[0]) {
+					hint += "  " + footerKeyStyle.Render("enter") + " resolve"
+				}
+				add(hint, i)
+			}
+		}
+		if !section {
+			add("", -1)
+		}
+	}
+	return rows
+}
+
 func (v QueueView) View(width, height int) string {
 	var b strings.Builder
 
@@ -179,6 +314,11 @@ func (v QueueView) View(width, height int) string {
 		Render("Sync Queue"))
 	b.WriteString("\n\n")
 
+	if v.resolving != nil {
+		b.WriteString(v.resolveView())
+		return helpStyle.Width(width).Height(height).Render(b.String())
+	}
+
 	if len(v.mutations) == 0 {
 		b.WriteString(emptyStyle.Render("No pending mutations"))
 		b.WriteString("\n\n")
@@ -186,55 +326,9 @@ func (v QueueView) View(width, height int) string {
 		return helpStyle.Width(width).Height(height).Render(b.String())
 	}
 
-	// Group by status
-	var pending, conflicted []indexedMutation
-	for i, m := range v.mutations {
-		im := indexedMutation{index: i, mutation: m}
-		switch m.Status {
-		case MutationConflicted:
-			conflicted = append(conflicted, im)
-		default:
-			pending = append(pending, im)
-		}
-	}
-
-	if len(pending) > 0 {
-		b.WriteString(queueTitleStyle.Render(fmt.Sprintf("━━ Pending (%d)", len(pending))))
-		b.WriteString("\n")
-		for _, im := range pending {
-			selected := im.index == v.cursor
-			line := "  " + renderMutationLine(im.mutation)
-			if selected {
-				b.WriteString(queueSelectedStyle.Width(width - 4).Render(line))
-			} else {
-				b.WriteString(queueItemStyle.Render(line))
-			}
-			b.WriteString("\n")
-		}
-		b.WriteString("\n")
-	}
-
-	if len(conflicted) > 0 {
-		b.WriteString(queueTitleStyle.Render(fmt.Sprintf("━━ Conflicts (%d)", len(conflicted))))
+	for _, row := range v.rows(width) {
+		b.WriteString(row.text)
 		b.WriteString("\n")
-		for _, im := range conflicted {
-			selected := im.index == v.cursor
-			line := "  " + renderMutationLine(im.mutation)
-			if selected {
-				b.WriteString(queueSelectedStyle.Width(width - 4).Render(line))
-			} else {
-				b.WriteString(queueConflictStyle.Render(line))
-			}
-			b.WriteString("\n")
-			if im.mutation.Conflict != "" {
-				b.WriteString(queueConflictStyle.Render("    " + im.mutation.Conflict))
-				b.WriteString("\n")
-			}
-			if selected {
-				b.WriteString("    " + footerKeyStyle.Render("r") + " retry  " + footerKeyStyle.Render("d") + " dismiss")
-				b.WriteString("\n")
-			}
-		}
 	}
 
 	b.WriteString("\n")
@@ -259,31 +353,239 @@ func (v QueueView) View(width, height int) string {
 	return helpStyle.Width(width).Height(height).Render(b.String())
 }
 
+// conflictLines renders a mutation's conflict records: field conflicts as a
+// side-by-side diff of the base, local and server values, other conflicts as
+// their reason, then when they were detected.
+func conflictLines(m Mutation, indent string, width int) []string {
+	records := m.Conflicts()
+	if len(records) == 0 {
+		return nil
+	}
+	const fieldWidth = 12
+	col := 20
+	if width > 0 {
+		col = max(10, min(40, (width-8-len(indent)-fieldWidth)/3-2))
+	}
+	cell := func(s string, w int) string {
+		return fmt.Sprintf("%-*s", w, truncate(s, w))
+	}
+
+	var lines []string
+	header := false
+	for _, c := range records {
+		if c.Field == "" {
+			lines = append(lines, queueConflictStyle.Render(indent+c.String()))
+			continue
+		}
+		if !header {
+			lines = append(lines, queueItemStyle.Render(indent+cell("", fieldWidth)+
+				cell("was", col)+"  "+cell("mine", col)+"  server"))
+			header = true
+		}
+		lines = append(lines, queueConflictStyle.Render(indent+cell(c.Field, fieldWidth)+
+			cell(strconv.Quote(c.Base), col)+"  "+cell(strconv.Quote(c.Local), col)+"  "+truncate(strconv.Quote(c.Remote), col)))
+	}
+	if detected := records[0].DetectedAt; !detected.IsZero() {
+		when := "just now"
+		if time.Since(detected) >= time.Minute {
+			when = formatAgeSince(detected) + " ago"
+		}
+		lines = append(lines, queueItemStyle.Render(indent+"detected "+when))
+	}
+	return lines
+}
+
+// resolvable reports whether m is a task update with field conflicts the
+// resolution dialog can settle.
+func resolvable(m Mutation) bool {
+	_, _, _, conflicts, err := taskUpdateConflicts(m)
+	return err == nil && len(conflicts) > 0
+}
+
+// openResolve starts the resolution dialog for a conflicted update, with
+// every field defaulting to the local value.
+func (v QueueView) openResolve(m Mutation) (QueueView, tea.Cmd) {
+	_, _, _, conflicts, err := taskUpdateConflicts(m)
+	if err != nil || len(conflicts) == 0 {
+		return v, nil
+	}
+	v.resolving = &m
+	v.conflicts = conflicts
+	v.choices = make([]string, len(conflicts))
+	v.edits = make([]string, len(conflicts))
+	for i, c := range conflicts {
+		v.choices[i] = "mine"
+		v.edits[i] = c.Local
+	}
+	v.fieldCursor = 0
+	return v, nil
+}
+
+func (v QueueView) closeResolve() QueueView {
+	v.resolving = nil
+	v.conflicts, v.choices, v.edits = nil, nil, nil
+	v.editInput.Blur()
+	return v
+}
+
+func (v QueueView) handleResolveKey(msg tea.KeyMsg) (QueueView, tea.Cmd) {
+	if v.editInput.Focused() {
+		switch ResolveAction(ContextMainTasksSearch, msg.String()) {
+		case ActionConfirm:
+			value := strings.TrimSpace(v.editInput.Value())
+			if v.conflicts[v.fieldCursor].Field == fieldPriority && !slices.Contains([]string{"1", "2", "3", "4"}, value) {
+				return v, nil
+			}
+			v.edits[v.fieldCursor] = value
+			v.choices[v.fieldCursor] = "edit"
+			v.editInput.Blur()
+			return v, nil
+		case ActionCancel:
+			v.editInput.Blur()
+			return v, nil
+		}
+		var cmd tea.Cmd
+		v.editInput, cmd = v.editInput.Update(msg)
+		return v, cmd
+	}
+
+	switch ResolveAction(ContextQueueResolve, msg.String()) {
+	case ActionNavDown:
+		if v.fieldCursor < len(v.conflicts)-1 {
+			v.fieldCursor++
+		}
+	case ActionNavUp:
+		if v.fieldCursor > 0 {
+			v.fieldCursor--
+		}
+	case ActionTakeMine:
+		v.choices[v.fieldCursor] = "mine"
+	case ActionTakeTheirs:
+		v.choices[v.fieldCursor] = "theirs"
+	case ActionEditField:
+		v.editInput.SetValue(v.edits[v.fieldCursor])
+		v.editInput.CursorEnd()
+		v.editInput.Focus()
+		return v, textinput.Blink
+	case ActionConfirm:
+		values := make(map[string]string)
+		for i, c := range v.conflicts {
+			switch v.choices[i] {
+			case "mine":
+				values[c.Field] = c.Local
+			case "edit":
+				values[c.Field] = v.edits[i]
+			}
+		}
+		id := v.resolving.ID
+		v = v.closeResolve()
+		return v, v.repo.ResolveConflict(id, values)
+	case ActionCancel:
+		return v.closeResolve(), nil
+	}
+	return v, nil
+}
+
+// resolveView lists each conflicting field with the local, server and
+// edited values, marking the one picked.
+func (v QueueView) resolveView() string {
+	var b strings.Builder
+	b.WriteString(queueTitleStyle.Render(fmt.Sprintf("━━ Resolve %q", truncate(taskNameFromSnapshot(*v.resolving), 40))))
+	b.WriteString("\n\n")
+	for i, c := range v.conflicts {
+		header := "  " + c.Field
+		if i == v.fieldCursor {
+			header = queueSelectedStyle.Render("▸ " + c.Field)
+		}
+		b.WriteString(header + "\n")
+		b.WriteString(queueItemStyle.Render(fmt.Sprintf("      was     %q", truncate(c.Base, 50))) + "\n")
+		for _, opt := range []struct{ choice, value string }{
+			{"mine", c.Local},
+			{"theirs", c.Remote},
+			{"edit", v.edits[i]},
+		} {
+			mark := "○"
+			if v.choices[i] == opt.choice {
+				mark = "●"
+			}
+			value := fmt.Sprintf("%q", truncate(opt.value, 50))
+			if opt.choice == "edit" && i == v.fieldCursor && v.editInput.Focused() {
+				value = v.editInput.View()
+			}
+			line := fmt.Sprintf("    %s %-7s %s", mark, opt.choice, value)
+			if v.choices[i] == opt.choice {
+				b.WriteString(line + "\n")
+			} else {
+				b.WriteString(queueItemStyle.Render(line) + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+	if v.editInput.Focused() {
+		b.WriteString(footerKeyStyle.Render("enter") + " save  " + footerKeyStyle.Render("esc") + " cancel")
+	} else {
+		b.WriteString(strings.Join(HintsForContext(ContextQueueResolve), "  "))
+	}
+	return b.String()
+}
+
 func (v *QueueView) clearConflictsLocal() {
-	filtered := v.mutations[:0]
+	var filtered []Mutation
 	for _, m := range v.mutations {
 		if m.Status == MutationConflicted {
 			continue
 		}
 		filtered = append(filtered, m)
 	}
-	v.mutations = filtered
-	if v.cursor >= len(v.mutations) {
-		v.cursor = len(v.mutations) - 1
-	}
-	if v.cursor < 0 {
-		v.cursor = 0
-	}
+	v.setMutations(filtered)
 }
 
 func (v *QueueView) clearAllLocal() {
-	v.mutations = nil
+	v.setMutations(nil)
 	v.cursor = 0
 }
 
-type indexedMutation struct {
-	index    int
-	mutation Mutation
+// renderUnitLine summarises a queue unit; a bulk edit reads like
+// "Close 5 tasks" when its members share an action.
+func renderUnitLine(u queueUnit) string {
+	if !u.grouped() {
+		return renderMutationLine(u.mutations[0])
+	}
+	icon := "↑"
+	if u.conflicted {
+		icon = "⚠"
+	}
+	first := u.mutations[0]
+	same := true
+	for _, m := range u.mutations[1:] {
+		if m.Action != first.Action {
+			same = false
+			break
+		}
+	}
+	n := len(u.mutations)
+	if !same {
+		return fmt.Sprintf("%s Bulk edit of %d tasks", icon, n)
+	}
+	switch first.Action {
+	case MutationClose:
+		return fmt.Sprintf("%s Close %d tasks", icon, n)
+	case MutationDelete:
+		return fmt.Sprintf("%s Delete %d tasks", icon, n)
+	case MutationMove:
+		var req moveTaskRequest
+		if json.Unmarshal([]byte(first.Payload), &req) == nil && req.Destination != "" {
+			return fmt.Sprintf("%s Move %d tasks to %s", icon, n, truncate(req.Destination, 40))
+		}
+		return fmt.Sprintf("%s Move %d tasks", icon, n)
+	case MutationUpdate:
+		desc := describeUpdate(first)
+		if _, changes, ok := strings.Cut(desc, " — "); ok {
+			return fmt.Sprintf("%s Update %d tasks — %s", icon, n, changes)
+		}
+		return fmt.Sprintf("%s Update %d tasks", icon, n)
+	}
+	return fmt.Sprintf("%s Bulk edit of %d tasks", icon, n)
 }
 
 func renderMutationLine(m Mutation) string {
@@ -309,6 +611,137 @@ func renderMutationLine(m Mutation) string {
 		desc = describeFromSnapshot(m, "Delete")
 	case MutationReopen:
 		desc = describeFromSnapshot(m, "Reopen")
+	case MutationReorder:
+		desc = "Reorder tasks"
+		var siblings []Task
+		if json.Unmarshal([]byte(m.Snapshot), &siblings) == nil {
+			for _, t := range siblings {
+				if t.ID == m.EntityID {
+					desc = fmt.Sprintf("Reorder tasks, moving %q", truncate(t.Content, 40))
+				}
+			}
+		}
+	case MutationMove:
+		desc = describeFromSnapshot(m, "Move")
+		var req moveTaskRequest
+		if json.Unmarshal([]byte(m.Payload), &req) == nil && req.Destination != "" {
+			desc += " to " + truncate(req.Destination, 40)
+		}
+	case MutationAddComment:
+		var p commentPayload
+		if json.Unmarshal([]byte(m.Payload), &p) == nil {
+			desc = fmt.Sprintf("Comment %q", truncate(p.Content, 40))
+		} else {
+			desc = "Add comment"
+		}
+	case MutationUpdateComment:
+		var p commentPayload
+		if json.Unmarshal([]byte(m.Payload), &p) == nil {
+			desc = fmt.Sprintf("Edit comment %q", truncate(p.Content, 40))
+		} else {
+			desc = "Edit comment"
+		}
+	case MutationDeleteComment:
+		var c Comment
+		if json.Unmarshal([]byte(m.Snapshot), &c) == nil {
+			desc = fmt.Sprintf("Delete comment %q", truncate(c.Content, 40))
+		} else {
+			desc = "Delete comment"
+		}
+	case MutationCreateLabel:
+		var p labelPayload
+		if json.Unmarshal([]byte(m.Payload), &p) == nil {
+			desc = "New label @" + p.Name
+		} else {
+			desc = "New label"
+		}
+	case MutationUpdateLabel:
+		desc = describeLabelUpdate(m)
+	case MutationDeleteLabel:
+		var snap labelSnapshot
+		if json.Unmarshal([]byte(m.Snapshot), &snap) == nil {
+			desc = "Delete label @" + snap.Name
+		} else {
+			desc = "Delete label"
+		}
+	case MutationCreateFilter:
+		var p filterPayload
+		if json.Unmarshal([]byte(m.Payload), &p) == nil {
+			desc = fmt.Sprintf("New filter %q", truncate(p.Name, 40))
+		} else {
+			desc = "New filter"
+		}
+	case MutationUpdateFilter:
+		desc = describeFilterUpdate(m)
+	case MutationDeleteFilter, MutationReorderFilters:
+		verb := "Delete filter"
+		if m.Action == MutationReorderFilters {
+			verb = "Reorder filters, moving"
+		}
+		desc = verb
+		if name := filterNameFromSnapshot(m); name != "" {
+			desc = fmt.Sprintf("%s %q", verb, truncate(name, 40))
+		}
+	case MutationMoveProject:
+		var p Project
+		var req projectMovePayload
+		if json.Unmarshal([]byte(m.Snapshot), &p) == nil && json.Unmarshal([]byte(m.Payload), &req) == nil {
+			if req.ParentID == nil {
+				desc = fmt.Sprintf("Move project %q to top level", truncate(p.Name, 40))
+			} else {
+				desc = fmt.Sprintf("Move project %q under %q", truncate(p.Name, 30), truncate(req.ParentName, 30))
+			}
+		} else {
+			desc = "Move project"
+		}
+	case MutationReorderProjects:
+		desc = "Reorder projects"
+		var siblings []Project
+		if json.Unmarshal([]byte(m.Snapshot), &siblings) == nil {
+			for _, p := range siblings {
+				if p.ID == m.EntityID {
+					desc = fmt.Sprintf("Reorder projects, moving %q", truncate(p.Name, 40))
+				}
+			}
+		}
+	case MutationCreateSection:
+		var p sectionPayload
+		if json.Unmarshal([]byte(m.Payload), &p) == nil {
+			desc = fmt.Sprintf("New section %q", truncate(p.Name, 40))
+		} else {
+			desc = "New section"
+		}
+	case MutationRenameSection:
+		var sec Section
+		var p sectionPayload
+		if json.Unmarshal([]byte(m.Snapshot), &sec) == nil && json.Unmarshal([]byte(m.Payload), &p) == nil {
+			desc = fmt.Sprintf("Rename section %q → %q", truncate(sec.Name, 30), truncate(p.Name, 30))
+		} else {
+			desc = "Rename section"
+		}
+	case MutationArchiveSection, MutationDeleteSection:
+		verb := "Delete section"
+		if m.Action == MutationArchiveSection {
+			verb = "Archive section"
+		}
+		desc = verb
+		var snap sectionSnapshot
+		if json.Unmarshal([]byte(m.Snapshot), &snap) == nil {
+			desc = fmt.Sprintf("%s %q", verb, truncate(snap.Name, 40))
+			if n := len(snap.Tasks); n > 0 {
+				desc += fmt.Sprintf(" with %d task(s)", n)
+			}
+		}
+	case MutationReorderSections:
+		desc = "Reorder sections"
+		var sections []Section
+		if json.Unmarshal([]byte(m.Snapshot), &sections) == nil {
+			for _, sec := range sections {
+				if sec.ID == m.EntityID {
+					desc = fmt.Sprintf("Reorder sections, moving %q", truncate(sec.Name, 40))
+				}
+			}
+		}
 	case MutationQuickAdd:
 		var req quickAddMutationPayload
 		if json.Unmarshal([]byte(m.Payload), &req) == nil {
@@ -318,6 +751,10 @@ func renderMutationLine(m Mutation) string {
 		}
 	}
 
+	if m.DependsOn != 0 && m.Status != MutationConflicted {
+		desc += " (waiting for create)"
+	}
+
 	return fmt.Sprintf("%s %s", icon, desc)
 }
 
@@ -356,6 +793,74 @@ func describeUpdate(m Mutation) string {
 	return fmt.Sprintf("Update %q", truncate(name, 40))
 }
 
+func describeLabelUpdate(m Mutation) string {
+	var p labelPayload
+	var snap labelSnapshot
+	if json.Unmarshal([]byte(m.Payload), &p) != nil || json.Unmarshal([]byte(m.Snapshot), &snap) != nil {
+		return "Update label"
+	}
+	var parts []string
+	if p.Name != "" && p.Name != snap.Name {
+		parts = append(parts, "name→@"+p.Name)
+	}
+	if p.Color != "" && p.Color != snap.Color {
+		parts = append(parts, "color→"+p.Color)
+	}
+	if len(parts) == 0 {
+		return "Update label @" + snap.Name
+	}
+	return fmt.Sprintf("Update @%s — %s", snap.Name, strings.Join(parts, ", "))
+}
+
+func describeFilterUpdate(m Mutation) string {
+	var p filterPayload
+	var snap SavedFilter
+	if json.Unmarshal([]byte(m.Payload), &p) != nil || json.Unmarshal([]byte(m.Snapshot), &snap) != nil {
+		return "Update filter"
+	}
+	var parts []string
+	if p.Name != "" && p.Name != snap.Name {
+		parts = append(parts, fmt.Sprintf("name→%q", p.Name))
+	}
+	if p.Query != "" && p.Query != snap.Query {
+		parts = append(parts, fmt.Sprintf("query→%q", truncate(p.Query, 30)))
+	}
+	if p.Color != "" && p.Color != snap.Color {
+		parts = append(parts, "color→"+p.Color)
+	}
+	if p.IsFavorite != nil && *p.IsFavorite != snap.IsFavorite {
+		if *p.IsFavorite {
+			parts = append(parts, "favorite")
+		} else {
+			parts = append(parts, "unfavorite")
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("Update filter %q", snap.Name)
+	}
+	return fmt.Sprintf("Update %q — %s", snap.Name, strings.Join(parts, ", "))
+}
+
+// filterNameFromSnapshot finds the mutated filter's name in a single-filter
+// snapshot or in the full list a reorder records.
+func filterNameFromSnapshot(m Mutation) string {
+	var filters []SavedFilter
+	if m.Action == MutationReorderFilters {
+		_ = json.Unmarshal([]byte(m.Snapshot), &filters)
+	} else {
+		var f SavedFilter
+		if json.Unmarshal([]byte(m.Snapshot), &f) == nil {
+			filters = append(filters, f)
+		}
+	}
+	for _, f := range filters {
+		if f.ID == m.EntityID {
+			return f.Name
+		}
+	}
+	return ""
+}
+
 func describeFromSnapshot(m Mutation, verb string) string {
 	name := taskNameFromSnapshot(m)
 	if name != "" {
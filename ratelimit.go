@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Todoist allows 1000 requests per user per 15 minutes. The REST and Sync
+// clients draw from one bucket, since the server counts them together.
+const (
+	rateLimitBurst    = 100
+	rateLimitRequests = 1000
+	rateLimitWindow   = 15 * time.Minute
+
+	// maxRateLimitWait is the longest a request blocks for a token; past it,
+	// the request fails with a retry hint instead of hanging the command.
+	maxRateLimitWait = 5 * time.Second
+)
+
+// apiLimiter is the rate limiter shared by every API client.
+var apiLimiter = newRateLimiter(rateLimitBurst, float64(rateLimitRequests)/rateLimitWindow.Seconds())
+
+// rateLimiter is a token bucket that also honours the server's Retry-After.
+type rateLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	rate     float64 // tokens per second
+	tokens   float64 // negative while callers are waiting on tokens
+	last     time.Time
+	retryAt  time.Time // no requests before this, per Retry-After
+}
+
+func newRateLimiter(capacity int, perSecond float64) *rateLimiter {
+	return &rateLimiter{
+		capacity: float64(capacity),
+		rate:     perSecond,
+		tokens:   float64(capacity),
+		last:     time.Now(),
+	}
+}
+
+func (l *rateLimiter) refill(now time.Time) {
+	l.tokens = min(l.capacity, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// wait takes a token, blocking until one is available and any Retry-After
+// has passed. A wait longer than maxRateLimitWait fails with a local 429.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	wait := l.retryAt.Sub(now)
+	if l.tokens < 1 {
+		wait = max(wait, time.Duration((1-l.tokens)/l.rate*float64(time.Second)))
+	}
+	if wait > maxRateLimitWait {
+		l.mu.Unlock()
+		return &APIError{
+			StatusCode: http.StatusTooManyRequests,
+			Body:       "client rate limit reached, retry in " + wait.Round(time.Second).String(),
+			RetryAfter: wait,
+		}
+	}
+	l.tokens--
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttled records a rate-limit response: requests hold off until its
+// Retry-After, and a 429 without one empties the bucket.
+func (l *rateLimiter) throttled(err *APIError) {
+	if err.StatusCode != http.StatusTooManyRequests && err.RetryAfter <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.refill(now)
+	if err.RetryAfter > 0 {
+		if until := now.Add(err.RetryAfter); until.After(l.retryAt) {
+			l.retryAt = until
+		}
+	} else {
+		l.tokens = min(l.tokens, 0)
+	}
+}
+
+// rateBudget is a snapshot of the limiter for display.
+type rateBudget struct {
+	Remaining int
+	Capacity  int
+	RetryAt   time.Time // zero unless the server asked us to back off
+}
+
+func (l *rateLimiter) budget() rateBudget {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.refill(now)
+	b := rateBudget{Remaining: max(0, int(l.tokens)), Capacity: int(l.capacity)}
+	if l.retryAt.After(now) {
+		b.RetryAt = l.retryAt
+	}
+	return b
+}
+
+// String describes a budget worth showing: a server back-off, or fewer than
+// a fifth of the requests left. It is empty otherwise.
+func (b rateBudget) String() string {
+	switch {
+	case !b.RetryAt.IsZero():
+		return "rate limited · " + time.Until(b.RetryAt).Round(time.Second).String()
+	case b.Remaining*5 < b.Capacity:
+		return fmt.Sprintf("%d/%d requests left", b.Remaining, b.Capacity)
+	}
+	return ""
+}
+
+// parseRetryAfter reads a Retry-After header: delay seconds or an HTTP date.
+func parseRetryAfter(h string) time.Duration {
+	h = strings.TrimSpace(h)
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(h); err == nil {
+		return max(0, time.Duration(secs)*time.Second)
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return max(0, time.Until(t))
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstThenLocal429(t *testing.T) {
+	l := newRateLimiter(3, 0.01) // one token per 100s
+	for i := 0; i < 3; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("request %d within the burst: %v", i+1, err)
+		}
+	}
+	err := l.wait(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter <= maxRateLimitWait {
+		t.Fatalf("request past the burst = %v, want a local 429 with a retry hint", err)
+	}
+	if !isRetriableMutationError(err) || isNetworkError(err) {
+		t.Error("local 429 should back off without going offline")
+	}
+	if b := l.budget(); b.Remaining != 0 || b.Capacity != 3 {
+		t.Errorf("budget = %+v", b)
+	}
+}
+
+func TestRateLimiterWaitsForRefill(t *testing.T) {
+	l := newRateLimiter(1, 20) // a token every 50ms
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited < 30*time.Millisecond {
+		t.Errorf("second request waited %v, want about 50ms", waited)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled wait = %v", err)
+	}
+}
+
+func TestRateLimiterHonoursRetryAfter(t *testing.T) {
+	l := newRateLimiter(100, 100)
+	l.throttled(&APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute})
+	if b := l.budget(); b.RetryAt.IsZero() || !strings.HasPrefix(b.String(), "rate limited") {
+		t.Errorf("budget after Retry-After = %+v %q", b, b.String())
+	}
+	err := l.wait(context.Background())
+	if retryHint(err) < 50*time.Second {
+		t.Errorf("request during Retry-After = %v, want a 429 hinting the remaining minute", err)
+	}
+
+	// A later, shorter Retry-After doesn't cut the back-off short.
+	l.throttled(&APIError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Second})
+	if retryHint(l.wait(context.Background())) < 50*time.Second {
+		t.Error("shorter Retry-After replaced the longer one")
+	}
+}
+
+func TestRateLimiter429WithoutRetryAfterEmptiesBucket(t *testing.T) {
+	l := newRateLimiter(10, 0.01)
+	l.throttled(&APIError{StatusCode: http.StatusInternalServerError})
+	if b := l.budget(); b.Remaining != 10 {
+		t.Errorf("a 500 without Retry-After changed the budget: %+v", b)
+	}
+	l.throttled(&APIError{StatusCode: http.StatusTooManyRequests})
+	if b := l.budget(); b.Remaining != 0 || !b.RetryAt.IsZero() {
+		t.Errorf("budget after a bare 429 = %+v, want an empty bucket", b)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"", 0, 0},
+		{"30", 30 * time.Second, 30 * time.Second},
+		{" 5 ", 5 * time.Second, 5 * time.Second},
+		{"-3", 0, 0},
+		{"soon", 0, 0},
+		{time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat), 80 * time.Second, 90 * time.Second},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header); got < tt.min || got > tt.max {
+			t.Errorf("parseRetryAfter(%q) = %v, want between %v and %v", tt.header, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRateBudgetString(t *testing.T) {
+	tests := []struct {
+		budget rateBudget
+		want   string
+	}{
+		{rateBudget{Remaining: 100, Capacity: 100}, ""},
+		{rateBudget{Remaining: 20, Capacity: 100}, ""},
+		{rateBudget{Remaining: 19, Capacity: 100}, "19/100 requests left"},
+		{rateBudget{Remaining: 50, Capacity: 100, RetryAt: time.Now().Add(30 * time.Second)}, "rate limited · 30s"},
+	}
+	for _, tt := range tests {
+		if got := tt.budget.String(); got != tt.want {
+			t.Errorf("%+v: String = %q, want %q", tt.budget, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recurrenceRule is a parsed subset of Todoist's natural-language recurrence.
+type recurrenceRule struct {
+	unit           string // "day", "weekday", "week", "month", "year", "days_of_week", "day_of_month"
+	every          int
+	weekdays       map[time.Weekday]bool
+	dayOfMonth     int
+	anchorDay      int  // day of month "month" and "year" steps aim for; 0 uses the date's own
+	fromCompletion bool // "every!" schedules from the completion date
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var (
+	recurIntervalRe   = regexp.MustCompile(`^(\d+) (day|week|month|year)s?$`)
+	recurDayOfMonthRe = regexp.MustCompile(`^(?:month on (?:the )?(\d{1,2})(?:st|nd|rd|th)?|(\d{1,2})(?:st|nd|rd|th))$`)
+	recurTimeSuffixRe = regexp.MustCompile(` at .*$`)
+)
+
+// parseRecurrence parses due strings such as "every day", "every weekday",
+// "every monday", "every 2 weeks" or "every month on the 15th".
+func parseRecurrence(s string) (recurrenceRule, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = recurTimeSuffixRe.ReplaceAllString(s, "")
+
+	var rule recurrenceRule
+	switch s {
+	case "daily":
+		return recurrenceRule{unit: "day", every: 1}, true
+	case "weekly":
+		return recurrenceRule{unit: "week", every: 1}, true
+	case "monthly":
+		return recurrenceRule{unit: "month", every: 1}, true
+	case "yearly", "annually":
+		return recurrenceRule{unit: "year", every: 1}, true
+	}
+
+	switch {
+	case strings.HasPrefix(s, "every! "):
+		rule.fromCompletion = true
+		s = strings.TrimPrefix(s, "every! ")
+	case strings.HasPrefix(s, "every "):
+		s = strings.TrimPrefix(s, "every ")
+	default:
+		return rule, false
+	}
+	s = strings.TrimSpace(s)
+
+	switch s {
+	case "day":
+		rule.unit, rule.every = "day", 1
+		return rule, true
+	case "weekday", "workday":
+		rule.unit, rule.every = "weekday", 1
+		return rule, true
+	case "week":
+		rule.unit, rule.every = "week", 1
+		return rule, true
+	case "month":
+		rule.unit, rule.every = "month", 1
+		return rule, true
+	case "year":
+		rule.unit, rule.every = "year", 1
+		return rule, true
+	case "other day":
+		rule.unit, rule.every = "day", 2
+		return rule, true
+	case "other week":
+		rule.unit, rule.every = "week", 2
+		return rule, true
+	}
+
+	if m := recurIntervalRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 {
+			return rule, false
+		}
+		rule.unit, rule.every = m[2], n
+		return rule, true
+	}
+
+	if m := recurDayOfMonthRe.FindStringSubmatch(s); m != nil {
+		d, _ := strconv.Atoi(m[1] + m[2])
+		if d < 1 || d > 31 {
+			return rule, false
+		}
+		rule.unit, rule.dayOfMonth = "day_of_month", d
+		return rule, true
+	}
+
+	// "monday", "mon, thu", "tuesday and friday"
+	days := make(map[time.Weekday]bool)
+	for _, part := range strings.FieldsFunc(strings.ReplaceAll(s, " and ", ","), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		wd, ok := weekdayNames[part]
+		if !ok {
+			return rule, false
+		}
+		days[wd] = true
+	}
+	if len(days) == 0 {
+		return rule, false
+	}
+	rule.unit, rule.weekdays = "days_of_week", days
+	return rule, true
+}
+
+// step returns the first occurrence strictly after d.
+func (r recurrenceRule) step(d time.Time) time.Time {
+	switch r.unit {
+	case "day":
+		return d.AddDate(0, 0, r.every)
+	case "week":
+		return d.AddDate(0, 0, 7*r.every)
+	case "month":
+		return addMonthsClamped(d, r.every, r.anchor(d))
+	case "year":
+		return addMonthsClamped(d, 12*r.every, r.anchor(d))
+	case "weekday":
+		next := d.AddDate(0, 0, 1)
+		for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case "days_of_week":
+		next := d.AddDate(0, 0, 1)
+		for !r.weekdays[next.Weekday()] {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case "day_of_month":
+		next := clampDay(d.Year(), d.Month(), r.dayOfMonth, d.Location())
+		if !next.After(d) {
+			next = addMonthsClamped(time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, d.Location()), 1, r.dayOfMonth)
+		}
+		return next
+	}
+	return d
+}
+
+func (r recurrenceRule) anchor(d time.Time) int {
+	if r.anchorDay > 0 {
+		return r.anchorDay
+	}
+	return d.Day()
+}
+
+// addMonthsClamped moves d forward n months and pins the day to day, clamped
+// to the length of the target month (Jan 31 + 1 month = Feb 28).
+func addMonthsClamped(d time.Time, n, day int) time.Time {
+	first := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, d.Location()).AddDate(0, n, 0)
+	return clampDay(first.Year(), first.Month(), day, d.Location())
+}
+
+func clampDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// nextDue computes the due date a recurring task moves to when completed at
+// now, carrying the anchor day forward. ok is false for patterns the local
+// engine cannot evaluate.
+func nextDue(due *Due, now time.Time) (Due, bool) {
+	if due == nil || !due.IsRecurring || due.Date == "" {
+		return Due{}, false
+	}
+	rule, ok := parseRecurrence(due.String)
+	if !ok {
+		return Due{}, false
+	}
+
+	datePart, timePart, _ := strings.Cut(due.Date, "T")
+	base, err := time.ParseInLocation("2006-01-02", datePart, now.Location())
+	if err != nil {
+		return Due{}, false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if rule.fromCompletion {
+		base = today
+	}
+	// The stored anchor only holds if the date is where it would clamp to;
+	// otherwise the date was set afresh and anchors itself.
+	rule.anchorDay = base.Day()
+	if a := due.AnchorDay; a > 0 && !rule.fromCompletion && clampDay(base.Year(), base.Month(), a, base.Location()).Equal(base) {
+		rule.anchorDay = a
+	}
+
+	next := rule.step(base)
+	// Catch up overdue tasks so the next occurrence is not already in the past.
+	for next.Before(today) {
+		next = rule.step(next)
+	}
+
+	out := *due
+	out.Date = next.Format("2006-01-02")
+	if timePart != "" {
+		out.Date += "T" + timePart
+	}
+	out.AnchorDay = 0
+	if rule.unit == "month" || rule.unit == "year" {
+		out.AnchorDay = rule.anchorDay
+	}
+	return out, true
+}
+
+// recurringCompletedToast announces the next occurrence of a completed recurring task.
+func recurringCompletedToast(next *Task) tea.Cmd {
+	date, _, _ := strings.Cut(next.Due.Date, "T")
+	return func() tea.Msg {
+		return toastMsg{text: "Task completed · next " + date, isError: false}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.ParseInLocation("2006-01-02", s, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestRecurrenceStep(t *testing.T) {
+	tests := []struct {
+		due  string
+		from string
+		want []string // successive occurrences
+	}{
+		{"every day", "2025-12-30", []string{"2025-12-31", "2026-01-01"}},
+		{"every 3 days", "2026-02-27", []string{"2026-03-02", "2026-03-05"}},
+		{"every other week", "2026-01-05", []string{"2026-01-19", "2026-02-02"}},
+		{"every weekday", "2026-01-09", []string{"2026-01-12", "2026-01-13"}}, // Fri → Mon
+		{"every mon, thu", "2026-01-05", []string{"2026-01-08", "2026-01-12"}},
+		{"every month", "2026-01-31", []string{"2026-02-28", "2026-03-31", "2026-04-30", "2026-05-31"}},
+		{"every 2 months", "2025-12-31", []string{"2026-02-28", "2026-04-30", "2026-06-30", "2026-08-31"}},
+		{"every year", "2024-02-29", []string{"2025-02-28", "2026-02-28", "2027-02-28", "2028-02-29"}},
+		{"every month on the 30th", "2026-01-30", []string{"2026-02-28", "2026-03-30"}},
+		{"every 15th", "2026-01-20", []string{"2026-02-15", "2026-03-15"}},
+	}
+	for _, tt := range tests {
+		rule, ok := parseRecurrence(tt.due)
+		if !ok {
+			t.Errorf("parseRecurrence(%q) failed", tt.due)
+			continue
+		}
+		d := date(tt.from)
+		rule.anchorDay = d.Day()
+		for i, want := range tt.want {
+			d = rule.step(d)
+			if got := d.Format("2006-01-02"); got != want {
+				t.Errorf("%q from %s: occurrence %d = %s, want %s", tt.due, tt.from, i+1, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseRecurrenceRejects(t *testing.T) {
+	for _, s := range []string{"", "tomorrow", "every", "every 0 days", "every 32nd", "every funday"} {
+		if _, ok := parseRecurrence(s); ok {
+			t.Errorf("parseRecurrence(%q) accepted", s)
+		}
+	}
+}
+
+func TestNextDueKeepsAnchorAcrossCompletions(t *testing.T) {
+	due := &Due{Date: "2026-01-31", String: "every month", IsRecurring: true}
+	now := date("2026-01-31").Add(12 * time.Hour)
+	for _, want := range []string{"2026-02-28", "2026-03-31", "2026-04-30", "2026-05-31"} {
+		next, ok := nextDue(due, now)
+		if !ok {
+			t.Fatal("nextDue failed")
+		}
+		if next.Date != want {
+			t.Fatalf("next = %s, want %s", next.Date, want)
+		}
+		due = &next
+		now = date(want).Add(12 * time.Hour)
+	}
+}
+
+func TestNextDue(t *testing.T) {
+	tests := []struct {
+		name string
+		due  Due
+		now  string
+		want string
+	}{
+		{"keeps time of day", Due{Date: "2026-03-02T09:00:00", String: "every day at 9am", IsRecurring: true}, "2026-03-02", "2026-03-03T09:00:00"},
+		{"catches up overdue", Due{Date: "2026-01-01", String: "every week", IsRecurring: true}, "2026-01-20", "2026-01-22"},
+		{"every! counts from completion", Due{Date: "2026-01-01", String: "every! 3 days", IsRecurring: true}, "2026-01-10", "2026-01-13"},
+		{"stale anchor ignored after reschedule", Due{Date: "2026-03-10", String: "every month", IsRecurring: true, AnchorDay: 31}, "2026-03-10", "2026-04-10"},
+	}
+	for _, tt := range tests {
+		next, ok := nextDue(&tt.due, date(tt.now).Add(8*time.Hour))
+		if !ok || next.Date != tt.want {
+			t.Errorf("%s: nextDue = %q (ok %v), want %q", tt.name, next.Date, ok, tt.want)
+		}
+	}
+
+	if _, ok := nextDue(&Due{Date: "2026-01-01", String: "every full moon", IsRecurring: true}, date("2026-01-01")); ok {
+		t.Error("nextDue evaluated an unsupported pattern")
+	}
+}
@@ -5,21 +5,34 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
 )
 
 // Repository orchestrates cache-first reads and write-through mutations.
 type Repository struct {
-	client *Client
-	store  *Store
+	client     *Client
+	syncClient *SyncClient
+	store      *Store
+
+	// syncMu serializes Sync API round-trips so sync tokens are applied in order.
+	syncMu sync.Mutex
+
+	// history records user actions for undo and redo; undoMu serializes
+	// undo and redo so each sees the queue the last one left.
+	history undoHistory
+	undoMu  sync.Mutex
 }
 
-// NewRepository creates a Repository. store may be nil (falls back to direct API).
-func NewRepository(client *Client, store *Store) *Repository {
-	return &Repository{client: client, store: store}
+// NewRepository creates a Repository. store may be nil (mutations go straight to the API).
+func NewRepository(client *Client, syncClient *SyncClient, store *Store) *Repository {
+	return &Repository{client: client, syncClient: syncClient, store: store}
 }
 
 // --- Synchronous cache access ---
@@ -42,6 +55,16 @@ func (r *Repository) GetCachedSections(projectID string) []Section {
 	return sections
 }
 
+// GetAllCachedSections returns every cached section, in section order within
+// each project.
+func (r *Repository) GetAllCachedSections() []Section {
+	if r.store == nil {
+		return nil
+	}
+	sections, _ := r.store.GetAllSections()
+	return sections
+}
+
 // GetCachedProjects returns all projects from cache synchronously.
 func (r *Repository) GetCachedProjects() []Project {
 	if r.store == nil {
@@ -82,298 +105,435 @@ func (r *Repository) GetAssigneeNameMap() map[string]string {
 	return names
 }
 
-// --- Two-phase reads ---
-
-// FetchProjects returns cached projects instantly if available (stale or not),
-// then triggers a background refresh. If the cache is fresh, returns projectsMsg directly.
-func (r *Repository) FetchProjects() tea.Cmd {
-	return func() tea.Msg {
-		if r.store != nil {
-			stale := r.store.IsStale("projects", "")
-			lastSynced, _ := r.store.LastSynced("projects", "")
-			if !stale {
-				projects, err := r.store.GetProjects()
-				if err == nil {
-					return projectsMsg{
-						projects:   projects,
-						fromCache:  true,
-						stale:      false,
-						lastSynced: lastSynced,
-					}
-				}
-			}
-
-			// Stale but has data — return cached, view will trigger RefreshProjects
-			projects, err := r.store.GetProjects()
-			if err == nil && len(projects) > 0 {
-				return cachedProjectsMsg{
-					projects:   projects,
-					stale:      true,
-					lastSynced: lastSynced,
-				}
+// FilterTasks evaluates a Todoist filter query against the cached tasks and
+// returns the union of matches, so filters work offline.
+func (r *Repository) FilterTasks(query string) ([]Task, error) {
+	groups, err := r.FilterTaskGroups(query)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var out []Task
+	for _, g := range groups {
+		for _, t := range g {
+			if !seen[t.ID] {
+				seen[t.ID] = true
+				out = append(out, t)
 			}
 		}
-
-		// Empty cache — block on API
-		return r.fetchProjectsFromAPI()
 	}
+	return out, nil
 }
 
-// RefreshProjects forces an API fetch and updates the cache.
-func (r *Repository) RefreshProjects() tea.Cmd {
-	return func() tea.Msg {
-		return r.fetchProjectsFromAPI()
+// FilterTaskGroups is like FilterTasks but keeps one group per
+// comma-separated sub-query.
+func (r *Repository) FilterTaskGroups(query string) ([][]Task, error) {
+	f, err := ParseFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	if r.store == nil {
+		return make([][]Task, len(f.Queries)), nil
 	}
+	tasks, err := r.store.GetAllTasks()
+	if err != nil {
+		return nil, err
+	}
+	return f.Apply(tasks, r.newFilterEnv()), nil
 }
 
-func (r *Repository) fetchProjectsFromAPI() projectsMsg {
-	projects, err := r.client.GetProjects(context.Background())
+// TaskMatchesFilter reports whether a task belongs in a filter view; an
+// unparseable query matches nothing.
+func (r *Repository) TaskMatchesFilter(query string, t Task) bool {
+	f, err := ParseFilter(query)
 	if err != nil {
-		return projectsMsg{err: err}
+		return false
 	}
-	if r.store != nil {
-		_ = r.store.ReplaceProjects(projects)
+	return f.Match(&t, r.newFilterEnv())
+}
+
+// newFilterEnv snapshots the cache state filters are evaluated against.
+func (r *Repository) newFilterEnv() *filterEnv {
+	env := &filterEnv{
+		now:       time.Now(),
+		projects:  make(map[string]Project),
+		sections:  make(map[string]Section),
+		userNames: r.GetAssigneeNameMap(),
 	}
-	now := time.Now()
-	return projectsMsg{projects: projects, fromCache: false, stale: false, lastSynced: &now}
+	if r.store == nil {
+		return env
+	}
+	projects, _ := r.store.GetProjects()
+	for _, p := range projects {
+		env.projects[p.ID] = p
+	}
+	sections, _ := r.store.GetAllSections()
+	for _, s := range sections {
+		env.sections[s.ID] = s
+	}
+	env.userID = r.store.CurrentUserID()
+	return env
 }
 
-// FetchTasks returns cached tasks instantly if available, then triggers refresh.
-func (r *Repository) FetchTasks(projectID string) tea.Cmd {
+// --- Sync ---
+
+// LoadCachedProjects returns the cached project list without touching the network.
+func (r *Repository) LoadCachedProjects() tea.Cmd {
 	return func() tea.Msg {
-		if r.store != nil {
-			stale := r.store.IsStale("tasks", projectID)
-			lastSynced, _ := r.store.LastSynced("tasks", projectID)
-			if !stale {
-				tasks, err := r.store.GetTasks(projectID)
-				if err == nil {
-					return tasksMsg{
-						projectID:  projectID,
-						tasks:      tasks,
-						fromCache:  true,
-						stale:      false,
-						lastSynced: lastSynced,
-					}
-				}
-			}
+		return projectsMsg{projects: r.GetCachedProjects()}
+	}
+}
 
-			tasks, err := r.store.GetTasks(projectID)
-			if err == nil && len(tasks) > 0 {
-				return cachedTasksMsg{
-					projectID:  projectID,
-					tasks:      tasks,
-					stale:      true,
-					lastSynced: lastSynced,
-				}
-			}
+// LastSynced returns the time of the last successful sync, or nil if never synced.
+func (r *Repository) LastSynced() *time.Time {
+	if r.store == nil {
+		return nil
+	}
+	t, _ := r.store.LastSynced()
+	return t
+}
+
+// PerformSync runs one Sync API round-trip using the persisted sync_token and
+// merges the projects/items/sections/labels deltas into the cache.
+func (r *Repository) PerformSync() tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return syncDoneMsg{err: errors.New("cache unavailable")}
 		}
+		r.syncMu.Lock()
+		defer r.syncMu.Unlock()
 
-		return r.fetchTasksFromAPI(projectID)
+		resp, err := r.syncClient.Sync(context.Background(), r.store.GetSyncToken(), syncResourceTypes)
+		if err != nil {
+			return syncDoneMsg{lastSynced: r.LastSynced(), err: err}
+		}
+		if err := r.store.MergeSyncResponse(resp); err != nil {
+			return syncDoneMsg{lastSynced: r.LastSynced(), err: fmt.Errorf("merge sync: %w", err)}
+		}
+		return syncDoneMsg{fullSync: resp.FullSync, lastSynced: r.LastSynced()}
 	}
 }
 
-// RefreshTasks forces an API fetch for tasks.
-func (r *Repository) RefreshTasks(projectID string) tea.Cmd {
+// --- Optimistic mutations ---
+
+// CloseTaskTree closes a task's descendants (deepest first) and then the task itself.
+func (r *Repository) CloseTaskTree(taskID string, descendantIDs []string) tea.Cmd {
 	return func() tea.Msg {
-		return r.fetchTasksFromAPI(projectID)
+		var msg taskClosedMsg
+		r.grouped(func() {
+			for i := len(descendantIDs) - 1; i >= 0; i-- {
+				r.CloseTask(descendantIDs[i])()
+			}
+			msg, _ = r.CloseTask(taskID)().(taskClosedMsg)
+		})
+		msg.subtaskIDs = descendantIDs
+		return msg
 	}
 }
 
-func (r *Repository) fetchTasksFromAPI(projectID string) tasksMsg {
-	tasks, err := r.client.GetTasks(context.Background(), projectID)
-	if err != nil {
-		return tasksMsg{projectID: projectID, err: err}
+// CloseTask optimistically removes a task from cache, saves to completed, and enqueues a close mutation.
+// Recurring tasks stay in the cache and advance to their next occurrence instead.
+func (r *Repository) CloseTask(taskID string) tea.Cmd {
+	return func() tea.Msg { return r.closeTask(taskID, r.history.record) }
+}
+
+// closeTask does the work of CloseTask, passing its queued mutation to record.
+func (r *Repository) closeTask(taskID string, record func(Mutation)) tea.Msg {
+	snapshot := r.snapshotTask(taskID)
+	if r.store == nil {
+		return taskClosedMsg{taskID: taskID, err: nil}
 	}
+	task, err := r.store.GetTaskByID(taskID)
+	if err == nil && task != nil && task.Due != nil && task.Due.IsRecurring {
+		return r.closeRecurring(*task, snapshot, record)
+	}
+	// Save to completed_tasks before deleting
+	if err == nil && task != nil {
+		projectName := r.projectNameForID(task.ProjectID)
+		_ = r.store.SaveCompletedTask(*task, projectName)
+	}
+	_ = r.store.DeleteTask(taskID)
+	r.enqueueWith(record, Mutation{
+		EntityType: "task",
+		EntityID:   taskID,
+		Action:     MutationClose,
+		Snapshot:   snapshot,
+		Status:     MutationPending,
+		CreatedAt:  time.Now(),
+	})
+	return taskClosedMsg{taskID: taskID, err: nil}
+}
+
+// closeRecurring records the completed occurrence and moves the cached task to
+// its next due date. Patterns the local engine can't evaluate drop the task
+// from the cache until the flush brings back the server's next occurrence.
+func (r *Repository) closeRecurring(task Task, snapshot string, record func(Mutation)) tea.Msg {
+	now := time.Now()
+	_ = r.store.RecordCompletion(task, now)
+
+	msg := taskClosedMsg{taskID: task.ID}
+	if due, ok := nextDue(task.Due, now); ok {
+		task.Due = &due
+		_ = r.store.UpsertTask(task)
+		msg.next = &task
+	} else {
+		_ = r.store.DeleteTask(task.ID)
+	}
+	r.enqueueWith(record, Mutation{
+		EntityType: "task",
+		EntityID:   task.ID,
+		Action:     MutationClose,
+		Snapshot:   snapshot,
+		Status:     MutationPending,
+		CreatedAt:  now,
+	})
+	return msg
+}
+
+// ReopenTask optimistically moves a task from completed back to active cache and enqueues a reopen mutation.
+func (r *Repository) ReopenTask(task Task) tea.Cmd {
+	return func() tea.Msg { return r.reopenTask(task, r.history.record) }
+}
+
+// reopenTask does the work of ReopenTask, passing its queued mutation to record.
+func (r *Repository) reopenTask(task Task, record func(Mutation)) tea.Msg {
+	snapshotBlob, _ := json.Marshal(task)
 	if r.store != nil {
-		_ = r.store.ReplaceTasks(projectID, tasks)
+		_ = r.store.UpsertTask(task)
+		_ = r.store.DeleteCompletedTask(task.ID)
+		r.enqueueWith(record, Mutation{
+			EntityType: "task",
+			EntityID:   task.ID,
+			Action:     MutationReopen,
+			Snapshot:   string(snapshotBlob),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
 	}
-	now := time.Now()
-	return tasksMsg{projectID: projectID, tasks: tasks, fromCache: false, stale: false, lastSynced: &now}
+	return taskReopenedMsg{task: task, err: nil}
 }
 
-// FetchSections returns cached sections instantly if available.
-func (r *Repository) FetchSections(projectID string) tea.Cmd {
-	return func() tea.Msg {
-		if r.store != nil {
-			stale := r.store.IsStale("sections", projectID)
-			lastSynced, _ := r.store.LastSynced("sections", projectID)
-			if !stale {
-				sections, err := r.store.GetSections(projectID)
-				if err == nil {
-					return sectionsMsg{
-						projectID:  projectID,
-						sections:   sections,
-						fromCache:  true,
-						stale:      false,
-						lastSynced: lastSynced,
-					}
-				}
-			}
+// DeleteTask optimistically removes a task from cache and enqueues a delete mutation.
+func (r *Repository) DeleteTask(taskID string) tea.Cmd {
+	return func() tea.Msg { return r.deleteTask(taskID, r.history.record) }
+}
 
-			sections, err := r.store.GetSections(projectID)
-			if err == nil && len(sections) > 0 {
-				return cachedSectionsMsg{
-					projectID:  projectID,
-					sections:   sections,
-					stale:      true,
-					lastSynced: lastSynced,
-				}
-			}
-		}
+// deleteTaskPayload notes what an item_delete takes with it on the server, so
+// undo can tell whether re-creating the task alone would bring it all back.
+type deleteTaskPayload struct {
+	Subtasks int `json:"subtasks,omitempty"`
+	Comments int `json:"comments,omitempty"`
+}
 
-		return r.fetchSectionsFromAPI(projectID)
+// deleteTask does the work of DeleteTask, passing its queued mutation to record.
+func (r *Repository) deleteTask(taskID string, record func(Mutation)) tea.Msg {
+	snapshot := r.snapshotTask(taskID)
+	if r.store != nil {
+		all, _ := r.store.GetAllTasks()
+		comments, _ := r.store.GetComments(taskID)
+		payload, _ := json.Marshal(deleteTaskPayload{
+			Subtasks: len(descendantsOf(all, taskID)),
+			Comments: len(comments),
+		})
+		_ = r.store.DeleteTask(taskID)
+		r.enqueueWith(record, Mutation{
+			EntityType: "task",
+			EntityID:   taskID,
+			Action:     MutationDelete,
+			Payload:    string(payload),
+			Snapshot:   snapshot,
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
 	}
+	return taskDeletedMsg{taskID: taskID, err: nil}
 }
 
-// RefreshSections forces an API fetch for sections.
-func (r *Repository) RefreshSections(projectID string) tea.Cmd {
-	return func() tea.Msg {
-		return r.fetchSectionsFromAPI(projectID)
-	}
+// CreateTask optimistically inserts a temp task into cache and enqueues a create mutation.
+func (r *Repository) CreateTask(req createTaskRequest) tea.Cmd {
+	return func() tea.Msg { return r.createTask(req, r.history.record) }
 }
 
-func (r *Repository) fetchSectionsFromAPI(projectID string) sectionsMsg {
-	sections, err := r.client.GetSections(context.Background(), projectID)
-	if err != nil {
-		return sectionsMsg{projectID: projectID, err: err}
+// createTask does the work of CreateTask, passing its queued mutation to record.
+func (r *Repository) createTask(req createTaskRequest, record func(Mutation)) tea.Msg {
+	tempID := NewPendingID()
+	tempTask := Task{
+		ID:        tempID,
+		Content:   req.Content,
+		ProjectID: req.ProjectID,
+		SectionID: req.SectionID,
+		Priority:  req.Priority,
+		Labels:    req.Labels,
+	}
+	if req.DueString != "" {
+		tempTask.Due = &Due{String: req.DueString}
+	}
+	if req.DeadlineDate != "" {
+		tempTask.Deadline = &Deadline{Date: req.DeadlineDate}
 	}
 	if r.store != nil {
-		_ = r.store.ReplaceSections(projectID, sections)
+		_ = r.store.UpsertTask(tempTask)
+		payload, _ := json.Marshal(req)
+		r.enqueueWith(record, Mutation{
+			EntityType: "task",
+			EntityID:   tempID,
+			Action:     MutationCreate,
+			Payload:    string(payload),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
 	}
-	now := time.Now()
-	return sectionsMsg{projectID: projectID, sections: sections, fromCache: false, stale: false, lastSynced: &now}
+	return taskCreatedMsg{task: tempTask, err: nil}
 }
 
-// --- Optimistic mutations ---
+// UpdateTask optimistically updates cache and enqueues an update mutation.
+func (r *Repository) UpdateTask(taskID string, req updateTaskRequest) tea.Cmd {
+	return func() tea.Msg { return r.updateTask(taskID, req, r.history.record) }
+}
 
-// CloseTask optimistically removes a task from cache, saves to completed, and enqueues a close mutation.
-func (r *Repository) CloseTask(taskID string) tea.Cmd {
+// updateTask does the work of UpdateTask, passing its queued mutation to record.
+func (r *Repository) updateTask(taskID string, req updateTaskRequest, record func(Mutation)) tea.Msg {
+	snapshot := r.snapshotTask(taskID)
+	updated := r.applyUpdateToCache(taskID, req)
+	payload, _ := json.Marshal(req)
+	if r.store != nil {
+		r.enqueueWith(record, Mutation{
+			EntityType: "task",
+			EntityID:   taskID,
+			Action:     MutationUpdate,
+			Payload:    string(payload),
+			Snapshot:   snapshot,
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+	}
+	return taskUpdatedMsg{task: updated, err: nil}
+}
+
+// BulkEdit applies edit to every task in taskIDs and tags the queued
+// mutations with a shared group ID, so the queue shows them as one unit.
+// verb describes the edit for the confirmation toast.
+func (r *Repository) BulkEdit(verb string, taskIDs []string, edit func(taskID string) tea.Cmd) tea.Cmd {
 	return func() tea.Msg {
-		if IsPendingID(taskID) {
-			return toastMsg{text: "Task is still syncing, please wait", isError: true}
-		}
-		snapshot := r.snapshotTask(taskID)
-		if r.store != nil {
-			// Save to completed_tasks before deleting
-			if task, err := r.store.GetTaskByID(taskID); err == nil && task != nil {
-				projectName := r.projectNameForID(task.ProjectID)
-				_ = r.store.SaveCompletedTask(*task, projectName)
+		r.grouped(func() {
+			for _, id := range taskIDs {
+				edit(id)()
 			}
-			_ = r.store.DeleteTask(taskID)
-			_, _ = r.store.EnqueueMutation(Mutation{
-				EntityType: "task",
-				EntityID:   taskID,
-				Action:     MutationClose,
-				Snapshot:   snapshot,
-				Status:     MutationPending,
-				CreatedAt:  time.Now(),
-			})
-		}
-		return taskClosedMsg{taskID: taskID, err: nil}
+		})
+		return bulkEditMsg{verb: verb, count: len(taskIDs)}
 	}
 }
 
-// ReopenTask optimistically moves a task from completed back to active cache and enqueues a reopen mutation.
-func (r *Repository) ReopenTask(task Task) tea.Cmd {
-	return func() tea.Msg {
-		if IsPendingID(task.ID) {
-			return toastMsg{text: "Task is still syncing, please wait", isError: true}
-		}
-		snapshotBlob, _ := json.Marshal(task)
-		if r.store != nil {
-			_ = r.store.UpsertTask(task)
-			_ = r.store.DeleteCompletedTask(task.ID)
-			_, _ = r.store.EnqueueMutation(Mutation{
-				EntityType: "task",
-				EntityID:   task.ID,
-				Action:     MutationReopen,
-				Snapshot:   string(snapshotBlob),
-				Status:     MutationPending,
-				CreatedAt:  time.Now(),
-			})
-		}
-		return taskReopenedMsg{task: task, err: nil}
+// grouped runs fn and ties the mutations it queues into one unit: they share
+// a group ID in the queue and are undone together.
+func (r *Repository) grouped(fn func()) {
+	if r.store == nil {
+		fn()
+		return
 	}
+	after := r.store.LastMutationID()
+	fn()
+	_ = r.store.GroupMutationsAfter(after, uuid.New().String())
+	r.history.mergeAfter(after)
 }
 
-// DeleteTask optimistically removes a task from cache and enqueues a delete mutation.
-func (r *Repository) DeleteTask(taskID string) tea.Cmd {
-	return func() tea.Msg {
-		if IsPendingID(taskID) {
-			return toastMsg{text: "Task is still syncing, please wait", isError: true}
+// moveTaskRequest is the queued payload for item_move. Exactly one field is set:
+// a parent to nest under, or a section/project to move to the top level of.
+// Destination names the target for display only.
+type moveTaskRequest struct {
+	ParentID    *string `json:"parent_id,omitempty"`
+	SectionID   *string `json:"section_id,omitempty"`
+	ProjectID   *string `json:"project_id,omitempty"`
+	Destination string  `json:"destination,omitempty"`
+}
+
+// MoveTask optimistically re-parents a task in the cache and enqueues a move mutation.
+func (r *Repository) MoveTask(taskID string, req moveTaskRequest) tea.Cmd {
+	return func() tea.Msg { return r.moveTask(taskID, req, r.history.record) }
+}
+
+// moveTask does the work of MoveTask, passing its queued mutation to record.
+func (r *Repository) moveTask(taskID string, req moveTaskRequest, record func(Mutation)) tea.Msg {
+	snapshot := r.snapshotTask(taskID)
+	moved := r.applyMoveToCache(taskID, req)
+	payload, _ := json.Marshal(req)
+	if r.store != nil {
+		var refs []string
+		if req.ParentID != nil {
+			refs = append(refs, *req.ParentID)
 		}
-		snapshot := r.snapshotTask(taskID)
-		if r.store != nil {
-			_ = r.store.DeleteTask(taskID)
-			_, _ = r.store.EnqueueMutation(Mutation{
-				EntityType: "task",
-				EntityID:   taskID,
-				Action:     MutationDelete,
-				Snapshot:   snapshot,
-				Status:     MutationPending,
-				CreatedAt:  time.Now(),
-			})
+		if req.SectionID != nil {
+			refs = append(refs, *req.SectionID)
 		}
-		return taskDeletedMsg{taskID: taskID, err: nil}
+		r.enqueueWith(record, Mutation{
+			EntityType: "task",
+			EntityID:   taskID,
+			Action:     MutationMove,
+			Payload:    string(payload),
+			Snapshot:   snapshot,
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		}, refs...)
 	}
+	return taskMovedMsg{task: moved, err: nil}
 }
 
-// CreateTask optimistically inserts a temp task into cache and enqueues a create mutation.
-func (r *Repository) CreateTask(req createTaskRequest) tea.Cmd {
+// ReorderTask moves a task delta places among its siblings (same parent and
+// section), renumbers their ChildOrder in the cache and queues the whole
+// sibling order as one item_reorder.
+func (r *Repository) ReorderTask(taskID string, delta int) tea.Cmd {
 	return func() tea.Msg {
-		tempID := NewPendingID()
-		tempTask := Task{
-			ID:        tempID,
-			Content:   req.Content,
-			ProjectID: req.ProjectID,
-			SectionID: req.SectionID,
-			Priority:  req.Priority,
-			Labels:    req.Labels,
-		}
-		if req.DueString != "" {
-			tempTask.Due = &Due{String: req.DueString}
+		if r.store == nil {
+			return taskMovedMsg{err: fmt.Errorf("no cache")}
 		}
-		if req.DeadlineDate != "" {
-			tempTask.Deadline = &Deadline{Date: req.DeadlineDate}
+		t, err := r.store.GetTaskByID(taskID)
+		if err != nil || t == nil {
+			return taskMovedMsg{err: fmt.Errorf("task not found")}
 		}
-		if r.store != nil {
-			_ = r.store.UpsertTask(tempTask)
-			payload, _ := json.Marshal(req)
-			_, _ = r.store.EnqueueMutation(Mutation{
-				EntityType: "task",
-				EntityID:   tempID,
-				Action:     MutationCreate,
-				Payload:    string(payload),
-				Status:     MutationPending,
-				CreatedAt:  time.Now(),
-			})
+		all, _ := r.store.GetTasks(t.ProjectID)
+		siblings := taskSiblings(all, *t)
+		i := slices.IndexFunc(siblings, func(s Task) bool { return s.ID == taskID })
+		j := max(0, min(i+delta, len(siblings)-1))
+		if i < 0 || i == j {
+			return noopMsg{}
 		}
-		return taskCreatedMsg{task: tempTask, err: nil}
+		snapshot, _ := json.Marshal(siblings)
+		moved := siblings[i]
+		siblings = slices.Delete(siblings, i, i+1)
+		siblings = slices.Insert(siblings, j, moved)
+		order := make(map[string]int, len(siblings))
+		refs := make([]string, 0, len(siblings))
+		for k := range siblings {
+			siblings[k].ChildOrder = k + 1
+			order[siblings[k].ID] = k + 1
+			refs = append(refs, siblings[k].ID)
+			_ = r.store.UpsertTask(siblings[k])
+		}
+		payload, _ := json.Marshal(order)
+		r.enqueue(Mutation{
+			EntityType: "task",
+			EntityID:   taskID,
+			Action:     MutationReorder,
+			Payload:    string(payload),
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		}, refs...)
+		return taskMovedMsg{task: siblings[j]}
 	}
 }
 
-// UpdateTask optimistically updates cache and enqueues an update mutation.
-func (r *Repository) UpdateTask(taskID string, req updateTaskRequest) tea.Cmd {
-	return func() tea.Msg {
-		if IsPendingID(taskID) {
-			return toastMsg{text: "Task is still syncing, please wait", isError: true}
-		}
-		snapshot := r.snapshotTask(taskID)
-		updated := r.applyUpdateToCache(taskID, req)
-		payload, _ := json.Marshal(req)
-		if r.store != nil {
-			_, _ = r.store.EnqueueMutation(Mutation{
-				EntityType: "task",
-				EntityID:   taskID,
-				Action:     MutationUpdate,
-				Payload:    string(payload),
-				Snapshot:   snapshot,
-				Status:     MutationPending,
-				CreatedAt:  time.Now(),
-			})
+// taskSiblings returns the tasks sharing t's parent and section, t included,
+// in display order.
+func taskSiblings(tasks []Task, t Task) []Task {
+	var out []Task
+	for _, s := range tasks {
+		if s.SectionID == t.SectionID && sameParent(s.ParentID, t.ParentID) {
+			out = append(out, s)
 		}
-		return taskUpdatedMsg{task: updated, err: nil}
 	}
+	slices.SortStableFunc(out, func(a, b Task) int { return a.ChildOrder - b.ChildOrder })
+	return out
 }
 
 type quickAddMutationPayload struct {
@@ -384,43 +544,169 @@ type quickAddMutationPayload struct {
 
 // QuickAdd creates a task via natural language and optimistically inserts a temp task when project context is known.
 func (r *Repository) QuickAdd(text string, defaultProjectID string) tea.Cmd {
+	return func() tea.Msg { return r.quickAdd(text, defaultProjectID, r.history.record) }
+}
+
+// quickAdd does the work of QuickAdd, passing its queued mutation to record.
+func (r *Repository) quickAdd(text string, defaultProjectID string, record func(Mutation)) tea.Msg {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return quickAddMsg{err: nil}
+	}
+
+	if r.store == nil {
+		_, err := r.client.QuickAdd(context.Background(), trimmed)
+		return quickAddMsg{err: err, projectID: defaultProjectID}
+	}
+
+	payload := quickAddMutationPayload{Text: trimmed, ProjectID: defaultProjectID}
+	var tempTask *Task
+	if defaultProjectID != "" {
+		temp := Task{
+			ID:        NewPendingID(),
+			Content:   trimmed,
+			ProjectID: defaultProjectID,
+			Priority:  4,
+		}
+		_ = r.store.UpsertTask(temp)
+		payload.TempID = temp.ID
+		tempTask = &temp
+	}
+
+	body, _ := json.Marshal(payload)
+	r.enqueueWith(record, Mutation{
+		EntityType: "task",
+		EntityID:   payload.TempID,
+		Action:     MutationQuickAdd,
+		Payload:    string(body),
+		Status:     MutationPending,
+		CreatedAt:  time.Now(),
+	})
+
+	return quickAddMsg{err: nil, task: tempTask, projectID: defaultProjectID}
+}
+
+// enqueue records m in the mutation queue and the undo history. Mutations that
+// target or reference (refs) a task still waiting on its create are held until
+// the create has flushed.
+func (r *Repository) enqueue(m Mutation, refs ...string) {
+	r.enqueueWith(r.history.record, m, refs...)
+}
+
+// enqueueWith is enqueue with the history step supplied by the caller: undo
+// and redo collect the mutations they queue rather than recording them as
+// new user actions.
+func (r *Repository) enqueueWith(record func(Mutation), m Mutation, refs ...string) {
+	for _, id := range append([]string{m.EntityID}, refs...) {
+		if !IsPendingID(id) {
+			continue
+		}
+		if createID := r.store.CreateMutationID(id); createID > m.DependsOn {
+			m.DependsOn = createID
+		}
+	}
+	id, err := r.store.EnqueueMutation(m)
+	if err != nil {
+		return
+	}
+	m.ID = id
+	record(m)
+}
+
+// commentPayload is the queued payload for a new task comment.
+type commentPayload struct {
+	TaskID  string `json:"task_id"`
+	Content string `json:"content"`
+}
+
+// AddComment optimistically posts a comment on a task through the mutation queue.
+func (r *Repository) AddComment(taskID, content string) tea.Cmd {
 	return func() tea.Msg {
-		trimmed := strings.TrimSpace(text)
-		if trimmed == "" {
-			return quickAddMsg{err: nil}
+		tid := taskID
+		comment := Comment{
+			ID:       NewPendingID(),
+			TaskID:   &tid,
+			Content:  content,
+			PostedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+		if r.store != nil {
+			_ = r.store.UpsertComment(comment)
+			payload, _ := json.Marshal(commentPayload{TaskID: taskID, Content: content})
+			r.enqueue(Mutation{
+				EntityType: "comment",
+				EntityID:   comment.ID,
+				Action:     MutationAddComment,
+				Payload:    string(payload),
+				Status:     MutationPending,
+				CreatedAt:  time.Now(),
+			}, taskID)
 		}
+		return commentAddedMsg{comment: comment}
+	}
+}
 
+// UpdateComment optimistically edits a cached comment and enqueues an update mutation.
+func (r *Repository) UpdateComment(commentID, content string) tea.Cmd {
+	return func() tea.Msg {
 		if r.store == nil {
-			_, err := r.client.QuickAdd(context.Background(), trimmed)
-			return quickAddMsg{err: err, projectID: defaultProjectID}
-		}
-
-		payload := quickAddMutationPayload{Text: trimmed, ProjectID: defaultProjectID}
-		var tempTask *Task
-		if defaultProjectID != "" {
-			temp := Task{
-				ID:        NewPendingID(),
-				Content:   trimmed,
-				ProjectID: defaultProjectID,
-				Priority:  4,
-			}
-			_ = r.store.UpsertTask(temp)
-			payload.TempID = temp.ID
-			tempTask = &temp
+			return noopMsg{}
 		}
-
-		body, _ := json.Marshal(payload)
-		_, _ = r.store.EnqueueMutation(Mutation{
-			EntityType: "task",
-			EntityID:   payload.TempID,
-			Action:     MutationQuickAdd,
-			Payload:    string(body),
+		c, err := r.store.GetCommentByID(commentID)
+		if err != nil || c == nil {
+			return toastMsg{text: "Comment not found", isError: true}
+		}
+		snapshot, _ := json.Marshal(c)
+		updated := *c
+		updated.Content = content
+		_ = r.store.UpsertComment(updated)
+		payload, _ := json.Marshal(commentPayload{TaskID: *c.TaskID, Content: content})
+		r.enqueue(Mutation{
+			EntityType: "comment",
+			EntityID:   commentID,
+			Action:     MutationUpdateComment,
+			Payload:    string(payload),
+			Snapshot:   string(snapshot),
 			Status:     MutationPending,
 			CreatedAt:  time.Now(),
 		})
+		return commentUpdatedMsg{comment: updated}
+	}
+}
+
+// DeleteComment optimistically removes a cached comment and enqueues a delete mutation.
+func (r *Repository) DeleteComment(commentID string) tea.Cmd {
+	return func() tea.Msg { return r.deleteComment(commentID, r.history.record) }
+}
+
+// deleteComment does the work of DeleteComment, passing its queued mutation to record.
+func (r *Repository) deleteComment(commentID string, record func(Mutation)) tea.Msg {
+	if r.store == nil {
+		return noopMsg{}
+	}
+	c, err := r.store.GetCommentByID(commentID)
+	if err != nil || c == nil {
+		return toastMsg{text: "Comment not found", isError: true}
+	}
+	snapshot, _ := json.Marshal(c)
+	_ = r.store.DeleteComment(commentID)
+	r.enqueueWith(record, Mutation{
+		EntityType: "comment",
+		EntityID:   commentID,
+		Action:     MutationDeleteComment,
+		Snapshot:   string(snapshot),
+		Status:     MutationPending,
+		CreatedAt:  time.Now(),
+	})
+	return commentDeletedMsg{commentID: commentID}
+}
 
-		return quickAddMsg{err: nil, task: tempTask, projectID: defaultProjectID}
+// GetCachedComments returns a task's comment thread from cache, oldest first.
+func (r *Repository) GetCachedComments(taskID string) []Comment {
+	if r.store == nil {
+		return nil
 	}
+	comments, _ := r.store.GetComments(taskID)
+	return comments
 }
 
 // --- Sync count helpers ---
@@ -439,207 +725,994 @@ func (r *Repository) ConflictCount() int {
 	return r.store.ConflictCount()
 }
 
-// --- Flush logic ---
+// RateBudget reports the API request budget left before the client throttles.
+func (r *Repository) RateBudget() rateBudget {
+	if r.client == nil {
+		return rateBudget{}
+	}
+	return r.client.Budget()
+}
 
-// FlushNext picks the oldest pending mutation and flushes it to the API.
-func (r *Repository) FlushNext() tea.Cmd {
-	return func() tea.Msg {
-		if r.store == nil {
-			return noopMsg{}
-		}
-		m, err := r.store.NextPendingMutation()
-		if m == nil || err != nil {
-			return noopMsg{}
-		}
+// --- Labels ---
 
-		_ = r.store.UpdateMutationStatus(m.ID, MutationFlushing, "")
-		_ = r.store.IncrementMutationAttempts(m.ID)
+// labelPayload is the queued payload for a label create or update. Empty
+// fields are left unchanged on update.
+type labelPayload struct {
+	Name  string `json:"name,omitempty"`
+	Color string `json:"color,omitempty"`
+}
 
-		switch m.Action {
-		case MutationCreate:
-			return r.flushCreate(*m)
-		case MutationQuickAdd:
-			return r.flushQuickAdd(*m)
-		case MutationUpdate:
-			return r.flushUpdate(*m)
-		case MutationClose:
-			return r.flushClose(*m)
-		case MutationDelete:
-			return r.flushDelete(*m)
-		case MutationReopen:
-			return r.flushReopen(*m)
-		}
-		return noopMsg{}
-	}
+// labelSnapshot records a label and the tasks that carried it, so a rejected
+// rename or delete can be undone on the cached tasks too.
+type labelSnapshot struct {
+	Label
+	TaskIDs []string `json:"task_ids,omitempty"`
 }
 
-func (r *Repository) flushCreate(m Mutation) tea.Msg {
-	var req createTaskRequest
-	if err := json.Unmarshal([]byte(m.Payload), &req); err != nil {
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "invalid payload: "+err.Error())
-		return mutationConflictMsg{mutation: m, conflict: "invalid payload"}
+// GetCachedLabels returns all personal labels from cache.
+func (r *Repository) GetCachedLabels() []Label {
+	if r.store == nil {
+		return nil
 	}
+	labels, _ := r.store.GetLabels()
+	return labels
+}
 
-	task, err := r.client.CreateTask(context.Background(), req)
-	if err != nil {
-		if isRetriableMutationError(err) {
-			_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
-			return mutationFlushedMsg{mutation: m, err: err}
+// GetCachedLabelTasks returns open cached tasks carrying the label, across projects.
+func (r *Repository) GetCachedLabelTasks(name string) []Task {
+	var out []Task
+	for _, t := range r.GetAllCachedTasks() {
+		if slices.Contains(t.Labels, name) {
+			out = append(out, t)
 		}
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "API error: "+err.Error())
-		return mutationConflictMsg{mutation: m, conflict: err.Error()}
-	}
-
-	// Replace temp task with real task in cache
-	if r.store != nil {
-		_ = r.store.DeleteTask(m.EntityID) // remove temp
-		_ = r.store.UpsertTask(task)       // insert real
 	}
-	_ = r.store.DeleteMutation(m.ID)
-	return mutationFlushedMsg{mutation: m, err: nil}
+	return out
 }
 
-func (r *Repository) flushQuickAdd(m Mutation) tea.Msg {
-	var payload quickAddMutationPayload
-	if err := json.Unmarshal([]byte(m.Payload), &payload); err != nil {
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "invalid payload: "+err.Error())
-		return mutationConflictMsg{mutation: m, conflict: "invalid payload"}
+// GetLabelTaskCounts returns the number of open cached tasks per label name.
+func (r *Repository) GetLabelTaskCounts() map[string]int {
+	if r.store == nil {
+		return nil
 	}
+	counts, _ := r.store.LabelTaskCounts()
+	return counts
+}
 
-	task, err := r.client.QuickAdd(context.Background(), payload.Text)
-	if err != nil {
-		if isRetriableMutationError(err) {
-			_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
-			return mutationFlushedMsg{mutation: m, err: err}
+// CreateLabel optimistically adds a personal label through the mutation queue.
+func (r *Repository) CreateLabel(name, color string) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return labelCreatedMsg{err: fmt.Errorf("no cache")}
 		}
-		// For quick add, promote to conflict so the user gets explicit visibility and can retry/dismiss.
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "API error: "+err.Error())
-		// Remove temp placeholder if we inserted one.
-		if payload.TempID != "" && r.store != nil {
-			_ = r.store.DeleteTask(payload.TempID)
+		for _, l := range r.GetCachedLabels() {
+			if strings.EqualFold(l.Name, name) {
+				return labelCreatedMsg{err: fmt.Errorf("label @%s already exists", l.Name)}
+			}
 		}
-		return mutationConflictMsg{mutation: m, conflict: err.Error()}
+		label := Label{ID: NewPendingID(), Name: name, Color: color, Order: len(r.GetCachedLabels()) + 1}
+		_ = r.store.UpsertLabel(label)
+		payload, _ := json.Marshal(labelPayload{Name: name, Color: color})
+		r.enqueue(Mutation{
+			EntityType: "label",
+			EntityID:   label.ID,
+			Action:     MutationCreateLabel,
+			Payload:    string(payload),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return labelCreatedMsg{label: label}
 	}
+}
 
-	if r.store != nil {
-		if payload.TempID != "" {
-			_ = r.store.DeleteTask(payload.TempID)
+// UpdateLabel optimistically renames and/or recolors a label. A rename is
+// applied to every cached task carrying the old name.
+func (r *Repository) UpdateLabel(labelID string, req labelPayload) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return labelUpdatedMsg{err: fmt.Errorf("no cache")}
 		}
-		_ = r.store.UpsertTask(task)
+		l, err := r.store.GetLabelByID(labelID)
+		if err != nil || l == nil {
+			return labelUpdatedMsg{err: fmt.Errorf("label not found")}
+		}
+		if req.Name != "" && !strings.EqualFold(req.Name, l.Name) {
+			for _, other := range r.GetCachedLabels() {
+				if strings.EqualFold(other.Name, req.Name) {
+					return labelUpdatedMsg{err: fmt.Errorf("label @%s already exists", other.Name)}
+				}
+			}
+		}
+		snap := labelSnapshot{Label: *l}
+		updated := *l
+		if req.Name != "" && req.Name != l.Name {
+			snap.TaskIDs, _ = r.store.ReplaceTaskLabel(l.Name, req.Name)
+			updated.Name = req.Name
+		}
+		if req.Color != "" {
+			updated.Color = req.Color
+		}
+		_ = r.store.UpsertLabel(updated)
+		snapshot, _ := json.Marshal(snap)
+		payload, _ := json.Marshal(req)
+		r.enqueue(Mutation{
+			EntityType: "label",
+			EntityID:   labelID,
+			Action:     MutationUpdateLabel,
+			Payload:    string(payload),
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return labelUpdatedMsg{label: updated}
 	}
-	_ = r.store.DeleteMutation(m.ID)
-	return mutationFlushedMsg{mutation: m, err: nil}
 }
 
-func (r *Repository) flushUpdate(m Mutation) tea.Msg {
-	var req updateTaskRequest
-	if err := json.Unmarshal([]byte(m.Payload), &req); err != nil {
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "invalid payload: "+err.Error())
-		return mutationConflictMsg{mutation: m, conflict: "invalid payload"}
+// DeleteLabel optimistically deletes a label and strips it from cached tasks,
+// matching the server's cascade.
+func (r *Repository) DeleteLabel(labelID string) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return labelDeletedMsg{err: fmt.Errorf("no cache")}
+		}
+		l, err := r.store.GetLabelByID(labelID)
+		if err != nil || l == nil {
+			return labelDeletedMsg{err: fmt.Errorf("label not found")}
+		}
+		snap := labelSnapshot{Label: *l}
+		snap.TaskIDs, _ = r.store.ReplaceTaskLabel(l.Name, "")
+		_ = r.store.DeleteLabel(labelID)
+		snapshot, _ := json.Marshal(snap)
+		r.enqueue(Mutation{
+			EntityType: "label",
+			EntityID:   labelID,
+			Action:     MutationDeleteLabel,
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return labelDeletedMsg{labelID: labelID}
 	}
+}
 
-	// Get current server state for conflict detection
-	serverTask, err := r.client.GetTask(context.Background(), m.EntityID)
-	if err != nil {
-		if isNotFoundError(err) {
-			_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "task deleted on server")
-			return mutationConflictMsg{mutation: m, conflict: "task deleted on server"}
+// --- Saved filters ---
+
+// filterPayload is the queued payload for a filter create or update. Empty
+// fields are left unchanged on update.
+type filterPayload struct {
+	Name       string `json:"name,omitempty"`
+	Query      string `json:"query,omitempty"`
+	Color      string `json:"color,omitempty"`
+	IsFavorite *bool  `json:"is_favorite,omitempty"`
+}
+
+// GetCachedFilters returns all saved filters from cache, in list order.
+func (r *Repository) GetCachedFilters() []SavedFilter {
+	if r.store == nil {
+		return nil
+	}
+	filters, _ := r.store.GetFilters()
+	return filters
+}
+
+// CreateFilter optimistically adds a saved filter through the mutation queue.
+func (r *Repository) CreateFilter(req filterPayload) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return filterCreatedMsg{err: fmt.Errorf("no cache")}
 		}
-		if isRetriableMutationError(err) {
-			_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
-			return mutationFlushedMsg{mutation: m, err: err}
+		filters := r.GetCachedFilters()
+		order := 1
+		if len(filters) > 0 {
+			order = filters[len(filters)-1].ItemOrder + 1
+		}
+		f := SavedFilter{ID: NewPendingID(), Name: req.Name, Query: req.Query, Color: req.Color, ItemOrder: order}
+		if req.IsFavorite != nil {
+			f.IsFavorite = *req.IsFavorite
 		}
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "API error: "+err.Error())
-		return mutationConflictMsg{mutation: m, conflict: err.Error()}
+		_ = r.store.UpsertFilter(f)
+		payload, _ := json.Marshal(req)
+		r.enqueue(Mutation{
+			EntityType: "filter",
+			EntityID:   f.ID,
+			Action:     MutationCreateFilter,
+			Payload:    string(payload),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return filterCreatedMsg{filter: f}
 	}
+}
 
-	// Snapshot-based conflict detection
-	var snapshotTask Task
-	if m.Snapshot != "" {
-		_ = json.Unmarshal([]byte(m.Snapshot), &snapshotTask)
+// UpdateFilter optimistically edits a saved filter's name, query, color or
+// favorite state.
+func (r *Repository) UpdateFilter(filterID string, req filterPayload) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return filterUpdatedMsg{err: fmt.Errorf("no cache")}
+		}
+		f, err := r.store.GetFilterByID(filterID)
+		if err != nil || f == nil {
+			return filterUpdatedMsg{err: fmt.Errorf("filter not found")}
+		}
+		snapshot, _ := json.Marshal(f)
+		updated := *f
+		if req.Name != "" {
+			updated.Name = req.Name
+		}
+		if req.Query != "" {
+			updated.Query = req.Query
+		}
+		if req.Color != "" {
+			updated.Color = req.Color
+		}
+		if req.IsFavorite != nil {
+			updated.IsFavorite = *req.IsFavorite
+		}
+		_ = r.store.UpsertFilter(updated)
+		payload, _ := json.Marshal(req)
+		r.enqueue(Mutation{
+			EntityType: "filter",
+			EntityID:   filterID,
+			Action:     MutationUpdateFilter,
+			Payload:    string(payload),
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return filterUpdatedMsg{filter: updated}
 	}
+}
 
-	if conflict := r.detectConflict(snapshotTask, serverTask, req); conflict != nil {
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, *conflict)
-		return mutationConflictMsg{mutation: m, conflict: *conflict}
+// DeleteFilter optimistically deletes a saved filter.
+func (r *Repository) DeleteFilter(filterID string) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return filterDeletedMsg{err: fmt.Errorf("no cache")}
+		}
+		f, err := r.store.GetFilterByID(filterID)
+		if err != nil || f == nil {
+			return filterDeletedMsg{err: fmt.Errorf("filter not found")}
+		}
+		snapshot, _ := json.Marshal(f)
+		_ = r.store.DeleteFilter(filterID)
+		r.enqueue(Mutation{
+			EntityType: "filter",
+			EntityID:   filterID,
+			Action:     MutationDeleteFilter,
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return filterDeletedMsg{filterID: filterID}
 	}
+}
 
-	// No conflict — apply update
-	task, err := r.client.UpdateTask(context.Background(), m.EntityID, req)
-	if err != nil {
-		if isRetriableMutationError(err) {
-			_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
-			return mutationFlushedMsg{mutation: m, err: err}
+// MoveFilter swaps a saved filter with its neighbour delta places away and
+// queues the new order for both.
+func (r *Repository) MoveFilter(filterID string, delta int) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return filterUpdatedMsg{err: fmt.Errorf("no cache")}
 		}
-		_ = r.restoreTaskFromSnapshot(m)
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "API error: "+err.Error())
-		return mutationConflictMsg{mutation: m, conflict: err.Error()}
+		filters := r.GetCachedFilters()
+		i := slices.IndexFunc(filters, func(f SavedFilter) bool { return f.ID == filterID })
+		j := i + delta
+		if i < 0 || j < 0 || j >= len(filters) {
+			return noopMsg{}
+		}
+		snapshot, _ := json.Marshal(filters)
+		filters[i], filters[j] = filters[j], filters[i]
+		// Renumber densely so ties left by the server cannot undo the swap.
+		order := make(map[string]int, len(filters))
+		for k := range filters {
+			filters[k].ItemOrder = k + 1
+			order[filters[k].ID] = k + 1
+			_ = r.store.UpsertFilter(filters[k])
+		}
+		payload, _ := json.Marshal(order)
+		refs := make([]string, 0, len(filters))
+		for _, f := range filters {
+			refs = append(refs, f.ID)
+		}
+		r.enqueue(Mutation{
+			EntityType: "filter",
+			EntityID:   filterID,
+			Action:     MutationReorderFilters,
+			Payload:    string(payload),
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		}, refs...)
+		return filterUpdatedMsg{filter: filters[j]}
 	}
+}
 
-	if r.store != nil {
-		_ = r.store.UpsertTask(task)
+// --- Flush logic ---
+
+// maxSyncCommands is the Sync API limit on commands per request.
+const maxSyncCommands = 100
+
+// FlushPending sends queued mutations to the Sync API as one batch of commands,
+// then reconciles the cache from sync_status, temp_id_mapping and the item deltas.
+func (r *Repository) FlushPending() tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return noopMsg{}
+		}
+		r.syncMu.Lock()
+		defer r.syncMu.Unlock()
+		defer r.store.BlockDependents(encodeConflicts(ConflictRecord{Reason: blockedByCreateReason}))
+
+		muts, err := r.store.ClaimMutations(maxSyncCommands)
+		if err != nil || len(muts) == 0 {
+			return noopMsg{}
+		}
+
+		var done flushDoneMsg
+		ctx := context.Background()
+
+		// Quick add parses natural language server-side and has no Sync command,
+		// so those go through REST first; their real IDs feed the batch below.
+		var batch []Mutation
+		remaps := make(map[string]string)
+		released := make(map[int64]bool)
+		for _, m := range muts {
+			if m.Action != MutationQuickAdd {
+				batch = append(batch, m)
+				continue
+			}
+			if done.err != nil {
+				_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
+				continue
+			}
+			if tempID, realID := r.flushQuickAdd(ctx, m, &done); tempID != "" && realID != "" {
+				remaps[tempID] = realID
+				released[m.ID] = true
+			}
+		}
+		for i := range batch {
+			for tempID, realID := range remaps {
+				batch[i] = remapMutation(batch[i], tempID, realID)
+			}
+			if released[batch[i].DependsOn] {
+				batch[i].DependsOn = 0
+			}
+		}
+		if done.err != nil {
+			r.requeue(batch)
+			return done
+		}
+
+		batch = r.rejectConflictingUpdates(ctx, batch, &done)
+		if done.err != nil {
+			r.requeue(batch)
+			return done
+		}
+		if len(batch) == 0 {
+			return done
+		}
+
+		// A mutation queued against a pending task rides along only when its
+		// create goes out earlier in the same request; temp IDs resolve in order.
+		cmds := make([]SyncCommand, 0, len(batch))
+		sent := make([]Mutation, 0, len(batch))
+		sentIDs := make(map[int64]bool, len(batch))
+		for _, m := range batch {
+			if m.DependsOn != 0 && !sentIDs[m.DependsOn] {
+				_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
+				continue
+			}
+			cmd, err := mutationToCommand(m)
+			if err != nil {
+				r.markConflicted(m, ConflictRecord{Reason: "invalid payload: " + err.Error()}, &done)
+				continue
+			}
+			cmds = append(cmds, cmd)
+			sent = append(sent, m)
+			sentIDs[m.ID] = true
+		}
+		if len(cmds) == 0 {
+			return done
+		}
+
+		resp, err := r.syncClient.SyncWithCommands(ctx, r.store.GetSyncToken(), syncResourceTypes, cmds)
+		if err != nil {
+			if isRetriableMutationError(err) {
+				r.requeue(sent)
+				done.err = err
+				return done
+			}
+			status := apiErrorStatus(err)
+			for _, m := range sent {
+				r.rollbackMutation(m)
+				r.markConflicted(m, ConflictRecord{Reason: "API error: " + err.Error(), HTTPStatus: status}, &done)
+			}
+			return done
+		}
+
+		unflushedCreates := make(map[int64]bool)
+		for _, m := range sent {
+			cmdErr, reported := resp.CommandResult(m.UUID)
+			if isCreateAction(m.Action) && (!reported || cmdErr != nil) {
+				unflushedCreates[m.ID] = true
+			}
+			switch {
+			case unflushedCreates[m.DependsOn]:
+				// The server could not resolve the temp ID; wait for the create.
+				_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
+			case !reported:
+				_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
+			case cmdErr == nil:
+				_ = r.store.DeleteMutation(m.ID)
+				if isCreateAction(m.Action) {
+					_ = r.store.ReleaseDependents(m.ID)
+					if realID := resp.TempIDMap[m.EntityID]; realID != "" {
+						done.addCreated(m.ID, realID)
+					}
+				}
+				done.flushed++
+			case cmdErr.Retriable():
+				// Report it so the scheduler backs off and retries.
+				_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
+				if done.err == nil {
+					done.err = cmdErr
+				}
+			case cmdErr.HTTPCode == http.StatusNotFound && !isUpdateAction(m.Action):
+				// Already closed/deleted/reopened elsewhere — nothing left to do.
+				_ = r.store.DiscardMutation(m.ID)
+				done.flushed++
+			case cmdErr.HTTPCode == http.StatusNotFound:
+				r.rollbackMutation(m)
+				r.markConflicted(m, ConflictRecord{Reason: m.EntityType + " deleted on server", HTTPStatus: cmdErr.HTTPCode}, &done)
+			default:
+				r.rollbackMutation(m)
+				r.markConflicted(m, ConflictRecord{Reason: cmdErr.Error(), HTTPStatus: cmdErr.HTTPCode}, &done)
+			}
+		}
+
+		for tempID, realID := range resp.TempIDMap {
+			_ = r.store.RemapPendingID(tempID, realID)
+			r.history.remap(tempID, realID)
+		}
+		if err := r.store.MergeSyncResponse(resp); err != nil {
+			done.err = fmt.Errorf("merge sync: %w", err)
+		}
+		return done
 	}
-	_ = r.store.DeleteMutation(m.ID)
-	return mutationFlushedMsg{mutation: m, err: nil}
 }
 
-func (r *Repository) flushClose(m Mutation) tea.Msg {
-	err := r.client.CloseTask(context.Background(), m.EntityID)
+// rejectConflictingUpdates pulls remote changes made since the last sync and
+// marks queued updates conflicted when the server changed the same fields, or
+// queued moves when the task was moved elsewhere on the server.
+func (r *Repository) rejectConflictingUpdates(ctx context.Context, batch []Mutation, done *flushDoneMsg) []Mutation {
+	hasUpdates := false
+	for _, m := range batch {
+		if m.Action == MutationUpdate || m.Action == MutationUpdateComment || m.Action == MutationMove {
+			hasUpdates = true
+			break
+		}
+	}
+	if !hasUpdates {
+		return batch
+	}
+
+	resp, err := r.syncClient.Sync(ctx, r.store.GetSyncToken(), syncResourceTypes)
 	if err != nil {
-		if isNotFoundError(err) {
-			// Task already gone — not a conflict
-			_ = r.store.DeleteMutation(m.ID)
-			return mutationFlushedMsg{mutation: m, err: nil}
+		done.err = err
+		return batch
+	}
+	server := make(map[string]Task, len(resp.Items))
+	for _, t := range resp.Items {
+		server[t.ID] = t
+	}
+	serverNotes := make(map[string]Comment, len(resp.Notes))
+	for _, n := range resp.Notes {
+		serverNotes[n.ID] = n.comment()
+	}
+
+	kept := batch[:0]
+	for _, m := range batch {
+		if m.Action == MutationUpdateComment {
+			if conflict := commentConflict(m, serverNotes); conflict != "" {
+				if conflict == "comment deleted on server" {
+					r.rollbackMutation(m)
+				}
+				r.markConflicted(m, ConflictRecord{Reason: conflict}, done)
+				continue
+			}
+			kept = append(kept, m)
+			continue
 		}
-		if isRetriableMutationError(err) {
-			_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
-			return mutationFlushedMsg{mutation: m, err: err}
+		serverTask, changed := server[m.EntityID]
+		if (m.Action != MutationUpdate && m.Action != MutationMove) || !changed {
+			kept = append(kept, m)
+			continue
 		}
-		// Close is user-visible state. Roll back and mark conflicted so it is explicit.
-		_ = r.restoreTaskFromSnapshot(m)
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "API error: "+err.Error())
-		return mutationConflictMsg{mutation: m, conflict: err.Error()}
+		if serverTask.IsDeleted {
+			r.rollbackMutation(m)
+			r.markConflicted(m, ConflictRecord{Reason: "task deleted on server"}, done)
+			continue
+		}
+		if m.Action == MutationMove {
+			var snapshot Task
+			if m.Snapshot != "" && json.Unmarshal([]byte(m.Snapshot), &snapshot) == nil && movedOnServer(snapshot, serverTask) {
+				r.markConflicted(m, ConflictRecord{Reason: "task moved on server"}, done)
+				continue
+			}
+			kept = append(kept, m)
+			continue
+		}
+		var req updateTaskRequest
+		if err := json.Unmarshal([]byte(m.Payload), &req); err != nil {
+			r.markConflicted(m, ConflictRecord{Reason: "invalid payload: " + err.Error()}, done)
+			continue
+		}
+		var snapshot Task
+		if m.Snapshot != "" {
+			_ = json.Unmarshal([]byte(m.Snapshot), &snapshot)
+		}
+		merged, conflicts := mergeTaskUpdate(snapshot, serverTask, req)
+		if len(conflicts) > 0 {
+			remote, _ := json.Marshal(serverTask)
+			_ = r.store.MarkMutationConflicted(m.ID, encodeConflicts(conflicts...), string(remote))
+			done.conflicts++
+			continue
+		}
+		kept = append(kept, r.rebaseUpdate(m, snapshot, serverTask, merged))
 	}
-	_ = r.store.DeleteMutation(m.ID)
-	return mutationFlushedMsg{mutation: m, err: nil}
+
+	// Remote changes to entities without queued mutations land now; the
+	// conflicted ones stay optimistic until the user resolves them.
+	if err := r.store.MergeSyncResponse(resp); err != nil {
+		done.err = fmt.Errorf("merge sync: %w", err)
+	}
+	return kept
 }
 
-func (r *Repository) flushDelete(m Mutation) tea.Msg {
-	err := r.client.DeleteTask(context.Background(), m.EntityID)
+// rebaseUpdate moves a cleanly merged update onto the server copy of its
+// task: the queued payload becomes the merge, the snapshot the server copy,
+// and the cache picks up the server's changes alongside the local ones.
+func (r *Repository) rebaseUpdate(m Mutation, base, remote Task, merged updateTaskRequest) Mutation {
+	payload, err := json.Marshal(merged)
 	if err != nil {
-		if isNotFoundError(err) {
-			_ = r.store.DeleteMutation(m.ID)
-			return mutationFlushedMsg{mutation: m, err: nil}
+		return m
+	}
+	snapshot, _ := json.Marshal(remote)
+	m.Payload, m.Snapshot = string(payload), string(snapshot)
+	_ = r.store.RebaseMutation(m.ID, m.Payload, m.Snapshot)
+	if cached, err := r.store.GetTaskByID(m.EntityID); err == nil && cached != nil {
+		_ = r.store.UpsertTask(applyUpdate(withRemoteChanges(*cached, base, remote), merged))
+	}
+	return m
+}
+
+// ResolveConflict requeues a conflicted task update with values picked for
+// its conflicting fields; fields left out of values keep the server's value.
+func (r *Repository) ResolveConflict(id int64, values map[string]string) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return conflictResolvedMsg{err: fmt.Errorf("no cache")}
+		}
+		muts, err := r.store.GetAllMutations()
+		if err != nil {
+			return conflictResolvedMsg{err: err}
 		}
+		i := slices.IndexFunc(muts, func(m Mutation) bool { return m.ID == id })
+		if i < 0 || muts[i].Status != MutationConflicted {
+			return conflictResolvedMsg{err: fmt.Errorf("conflict no longer queued")}
+		}
+		m := muts[i]
+		base, remote, req, conflicts, err := taskUpdateConflicts(m)
+		if err != nil {
+			return conflictResolvedMsg{err: err}
+		}
+		req = resolveTaskUpdate(req, conflicts, values)
+		m = r.rebaseUpdate(m, base, remote, req)
+		_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
+		return conflictResolvedMsg{}
+	}
+}
+
+// taskUpdateConflicts re-runs the merge of a conflicted task update against
+// the server copy recorded when the conflict was detected.
+func taskUpdateConflicts(m Mutation) (base, remote Task, req updateTaskRequest, conflicts []ConflictRecord, err error) {
+	if m.Action != MutationUpdate || m.Remote == "" {
+		return base, remote, req, nil, fmt.Errorf("no field conflicts to resolve")
+	}
+	if err = json.Unmarshal([]byte(m.Payload), &req); err != nil {
+		return base, remote, req, nil, err
+	}
+	if err = json.Unmarshal([]byte(m.Remote), &remote); err != nil {
+		return base, remote, req, nil, err
+	}
+	if m.Snapshot != "" {
+		_ = json.Unmarshal([]byte(m.Snapshot), &base)
+	}
+	req, conflicts = mergeTaskUpdate(base, remote, req)
+	return base, remote, req, conflicts, nil
+}
+
+// movedOnServer reports whether the server copy of a task sits somewhere other
+// than where it was when a move was queued.
+func movedOnServer(snapshot, server Task) bool {
+	return server.ProjectID != snapshot.ProjectID || server.SectionID != snapshot.SectionID ||
+		!sameParent(server.ParentID, snapshot.ParentID)
+}
+
+// commentConflict reports why a queued comment edit can no longer apply
+// cleanly, or "" if the server copy is unchanged since the edit was made.
+func commentConflict(m Mutation, serverNotes map[string]Comment) string {
+	serverNote, changed := serverNotes[m.EntityID]
+	if !changed {
+		return ""
+	}
+	if serverNote.IsDeleted {
+		return "comment deleted on server"
+	}
+	var snapshot Comment
+	if m.Snapshot != "" {
+		_ = json.Unmarshal([]byte(m.Snapshot), &snapshot)
+	}
+	if serverNote.Content != snapshot.Content {
+		var p commentPayload
+		_ = json.Unmarshal([]byte(m.Payload), &p)
+		if serverNote.Content != p.Content {
+			return "comment edited on server"
+		}
+	}
+	return ""
+}
+
+func (r *Repository) flushQuickAdd(ctx context.Context, m Mutation, done *flushDoneMsg) (tempID, realID string) {
+	var payload quickAddMutationPayload
+	if err := json.Unmarshal([]byte(m.Payload), &payload); err != nil {
+		r.markConflicted(m, ConflictRecord{Reason: "invalid payload: " + err.Error()}, done)
+		return "", ""
+	}
+
+	task, err := r.client.QuickAdd(ctx, payload.Text)
+	if err != nil {
 		if isRetriableMutationError(err) {
 			_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
-			return mutationFlushedMsg{mutation: m, err: err}
+			done.err = err
+			return "", ""
 		}
-		_ = r.restoreTaskFromSnapshot(m)
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "API error: "+err.Error())
-		return mutationConflictMsg{mutation: m, conflict: err.Error()}
+		// For quick add, promote to conflict so the user gets explicit visibility and can retry/dismiss.
+		// Remove temp placeholder if we inserted one.
+		if payload.TempID != "" {
+			_ = r.store.DeleteTask(payload.TempID)
+		}
+		status := apiErrorStatus(err)
+		r.markConflicted(m, ConflictRecord{Reason: "API error: " + err.Error(), HTTPStatus: status}, done)
+		return "", ""
 	}
+
+	if payload.TempID != "" {
+		_ = r.store.RemapPendingID(payload.TempID, task.ID)
+		r.history.remap(payload.TempID, task.ID)
+	}
+	_ = r.store.UpsertTask(task)
 	_ = r.store.DeleteMutation(m.ID)
-	return mutationFlushedMsg{mutation: m, err: nil}
+	_ = r.store.ReleaseDependents(m.ID)
+	done.addCreated(m.ID, task.ID)
+	done.flushed++
+	return payload.TempID, task.ID
 }
 
-func (r *Repository) flushReopen(m Mutation) tea.Msg {
-	err := r.client.ReopenTask(context.Background(), m.EntityID)
-	if err != nil {
-		if isNotFoundError(err) {
-			_ = r.store.DeleteMutation(m.ID)
-			return mutationFlushedMsg{mutation: m, err: nil}
+// mutationToCommand translates a queued mutation into its Sync API command.
+// The mutation's UUID doubles as the command UUID so resends are idempotent.
+func mutationToCommand(m Mutation) (SyncCommand, error) {
+	cmd := SyncCommand{UUID: m.UUID, Args: map[string]any{"id": m.EntityID}}
+	switch m.Action {
+	case MutationCreate:
+		var req createTaskRequest
+		if err := json.Unmarshal([]byte(m.Payload), &req); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "item_add"
+		cmd.TempID = m.EntityID
+		cmd.Args = createTaskArgs(req)
+	case MutationUpdate:
+		var req updateTaskRequest
+		if err := json.Unmarshal([]byte(m.Payload), &req); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "item_update"
+		for k, v := range updateTaskArgs(req) {
+			cmd.Args[k] = v
 		}
-		if isRetriableMutationError(err) {
-			_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
-			return mutationFlushedMsg{mutation: m, err: err}
+	case MutationClose:
+		// item_close lets the server schedule the next occurrence of a recurring
+		// task; item_complete would check it off for good.
+		cmd.Type = "item_complete"
+		if snapshotIsRecurring(m) {
+			cmd.Type = "item_close"
+		}
+	case MutationDelete:
+		cmd.Type = "item_delete"
+	case MutationReopen:
+		cmd.Type = "item_uncomplete"
+	case MutationAddComment:
+		var p commentPayload
+		if err := json.Unmarshal([]byte(m.Payload), &p); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "note_add"
+		cmd.TempID = m.EntityID
+		cmd.Args = map[string]any{"item_id": p.TaskID, "content": p.Content}
+	case MutationUpdateComment:
+		var p commentPayload
+		if err := json.Unmarshal([]byte(m.Payload), &p); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "note_update"
+		cmd.Args["content"] = p.Content
+	case MutationDeleteComment:
+		cmd.Type = "note_delete"
+	case MutationCreateLabel:
+		var p labelPayload
+		if err := json.Unmarshal([]byte(m.Payload), &p); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "label_add"
+		cmd.TempID = m.EntityID
+		cmd.Args = map[string]any{"name": p.Name}
+		if p.Color != "" {
+			cmd.Args["color"] = p.Color
+		}
+	case MutationUpdateLabel:
+		var p labelPayload
+		if err := json.Unmarshal([]byte(m.Payload), &p); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "label_update"
+		if p.Name != "" {
+			cmd.Args["name"] = p.Name
+		}
+		if p.Color != "" {
+			cmd.Args["color"] = p.Color
+		}
+	case MutationDeleteLabel:
+		cmd.Type = "label_delete"
+		cmd.Args["cascade"] = "all"
+	case MutationCreateFilter:
+		var p filterPayload
+		if err := json.Unmarshal([]byte(m.Payload), &p); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "filter_add"
+		cmd.TempID = m.EntityID
+		cmd.Args = filterArgs(p)
+	case MutationUpdateFilter:
+		var p filterPayload
+		if err := json.Unmarshal([]byte(m.Payload), &p); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "filter_update"
+		for k, v := range filterArgs(p) {
+			cmd.Args[k] = v
+		}
+	case MutationDeleteFilter:
+		cmd.Type = "filter_delete"
+	case MutationReorderFilters:
+		var order map[string]int
+		if err := json.Unmarshal([]byte(m.Payload), &order); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "filter_update_orders"
+		cmd.Args = map[string]any{"id_order_mapping": order}
+	case MutationMoveProject:
+		var p projectMovePayload
+		if err := json.Unmarshal([]byte(m.Payload), &p); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "project_move"
+		cmd.Args["parent_id"] = p.ParentID
+	case MutationReorderProjects:
+		args, err := orderArgs(m.Payload, "projects", "child_order")
+		if err != nil {
+			return cmd, err
+		}
+		cmd.Type = "project_reorder"
+		cmd.Args = args
+	case MutationCreateSection:
+		var p sectionPayload
+		if err := json.Unmarshal([]byte(m.Payload), &p); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "section_add"
+		cmd.TempID = m.EntityID
+		cmd.Args = map[string]any{"name": p.Name, "project_id": p.ProjectID}
+	case MutationRenameSection:
+		var p sectionPayload
+		if err := json.Unmarshal([]byte(m.Payload), &p); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "section_update"
+		cmd.Args["name"] = p.Name
+	case MutationArchiveSection:
+		cmd.Type = "section_archive"
+	case MutationDeleteSection:
+		cmd.Type = "section_delete"
+	case MutationReorderSections:
+		args, err := orderArgs(m.Payload, "sections", "section_order")
+		if err != nil {
+			return cmd, err
+		}
+		cmd.Type = "section_reorder"
+		cmd.Args = args
+	case MutationReorder:
+		args, err := orderArgs(m.Payload, "items", "child_order")
+		if err != nil {
+			return cmd, err
+		}
+		cmd.Type = "item_reorder"
+		cmd.Args = args
+	case MutationMove:
+		var req moveTaskRequest
+		if err := json.Unmarshal([]byte(m.Payload), &req); err != nil {
+			return cmd, err
+		}
+		cmd.Type = "item_move"
+		switch {
+		case req.ParentID != nil:
+			cmd.Args["parent_id"] = *req.ParentID
+		case req.SectionID != nil:
+			cmd.Args["section_id"] = *req.SectionID
+		case req.ProjectID != nil:
+			cmd.Args["project_id"] = *req.ProjectID
+		default:
+			return cmd, fmt.Errorf("move without destination")
 		}
+	default:
+		return cmd, fmt.Errorf("unsupported action %q", m.Action)
+	}
+	return cmd, nil
+}
+
+// orderArgs builds the args of a reorder command from a queued order payload
+// (ID -> position): a list under listKey, in position order, each entry
+// carrying its position in orderField.
+func orderArgs(payload, listKey, orderField string) (map[string]any, error) {
+	var order map[string]int
+	if err := json.Unmarshal([]byte(payload), &order); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(order))
+	for id := range order {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b string) int { return order[a] - order[b] })
+	entries := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, map[string]any{"id": id, orderField: order[id]})
+	}
+	return map[string]any{listKey: entries}, nil
+}
+
+func snapshotIsRecurring(m Mutation) bool {
+	var t Task
+	if m.Snapshot == "" || json.Unmarshal([]byte(m.Snapshot), &t) != nil {
+		return false
+	}
+	return t.Due != nil && t.Due.IsRecurring
+}
+
+func createTaskArgs(req createTaskRequest) map[string]any {
+	args := map[string]any{"content": req.Content}
+	if req.Description != "" {
+		args["description"] = req.Description
+	}
+	if req.ProjectID != "" {
+		args["project_id"] = req.ProjectID
+	}
+	if req.SectionID != "" {
+		args["section_id"] = req.SectionID
+	}
+	if req.Priority > 0 {
+		args["priority"] = priorityToSync(req.Priority)
+	}
+	if req.DueString != "" {
+		args["due"] = map[string]any{"string": req.DueString}
+	}
+	if req.DeadlineDate != "" {
+		args["deadline"] = map[string]any{"date": req.DeadlineDate}
+	}
+	if len(req.Labels) > 0 {
+		args["labels"] = req.Labels
+	}
+	return args
+}
+
+func updateTaskArgs(req updateTaskRequest) map[string]any {
+	args := map[string]any{}
+	if req.Content != nil {
+		args["content"] = *req.Content
+	}
+	if req.Description != nil {
+		args["description"] = *req.Description
+	}
+	if req.Priority != nil {
+		args["priority"] = priorityToSync(*req.Priority)
+	}
+	if req.DueString != nil {
+		if *req.DueString == "" {
+			args["due"] = nil
+		} else {
+			args["due"] = map[string]any{"string": *req.DueString}
+		}
+	}
+	if req.ClearDeadline || (req.DeadlineDate != nil && *req.DeadlineDate == "") {
+		args["deadline"] = nil
+	} else if req.DeadlineDate != nil {
+		args["deadline"] = map[string]any{"date": *req.DeadlineDate}
+	}
+	if req.Labels != nil {
+		args["labels"] = req.Labels
+	}
+	return args
+}
+
+func filterArgs(p filterPayload) map[string]any {
+	args := map[string]any{}
+	if p.Name != "" {
+		args["name"] = p.Name
+	}
+	if p.Query != "" {
+		args["query"] = p.Query
+	}
+	if p.Color != "" {
+		args["color"] = p.Color
+	}
+	if p.IsFavorite != nil {
+		args["is_favorite"] = *p.IsFavorite
+	}
+	return args
+}
+
+// remapMutation rewrites a temporary ID inside an in-flight mutation.
+func remapMutation(m Mutation, tempID, realID string) Mutation {
+	m.EntityID = strings.ReplaceAll(m.EntityID, tempID, realID)
+	m.Payload = strings.ReplaceAll(m.Payload, tempID, realID)
+	m.Snapshot = strings.ReplaceAll(m.Snapshot, tempID, realID)
+	return m
+}
+
+func (r *Repository) requeue(muts []Mutation) {
+	for _, m := range muts {
+		_ = r.store.UpdateMutationStatus(m.ID, MutationPending, "")
+	}
+}
+
+// blockedByCreateReason is the conflict given to mutations held behind a
+// create that conflicted.
+const blockedByCreateReason = "waiting on a create that failed to sync"
+
+func (r *Repository) markConflicted(m Mutation, conflict ConflictRecord, done *flushDoneMsg) {
+	_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, encodeConflicts(conflict))
+	done.conflicts++
+}
+
+// rollbackMutation reverts the optimistic cache change made for m.
+func (r *Repository) rollbackMutation(m Mutation) {
+	switch m.Action {
+	case MutationUpdate, MutationClose, MutationDelete, MutationMove:
+		_ = r.restoreTaskFromSnapshot(m)
+	case MutationReorder:
+		_ = r.restoreTaskOrderFromSnapshot(m)
+	case MutationReopen:
 		_ = r.rollbackReopen(m)
-		_ = r.store.UpdateMutationStatus(m.ID, MutationConflicted, "API error: "+err.Error())
-		return mutationConflictMsg{mutation: m, conflict: err.Error()}
+	case MutationUpdateComment, MutationDeleteComment:
+		_ = r.restoreCommentFromSnapshot(m)
+	case MutationUpdateLabel, MutationDeleteLabel:
+		_ = r.restoreLabelFromSnapshot(m)
+	case MutationUpdateFilter, MutationDeleteFilter, MutationReorderFilters:
+		_ = r.restoreFilterFromSnapshot(m)
+	case MutationMoveProject, MutationReorderProjects:
+		_ = r.restoreProjectFromSnapshot(m)
+	case MutationRenameSection, MutationArchiveSection, MutationDeleteSection, MutationReorderSections:
+		_ = r.restoreSectionFromSnapshot(m)
 	}
-	_ = r.store.DeleteMutation(m.ID)
-	return mutationFlushedMsg{mutation: m, err: nil}
 }
 
 // --- Snapshot and conflict helpers ---
@@ -652,20 +1725,115 @@ func (r *Repository) snapshotTask(taskID string) string {
 	if err != nil || task == nil {
 		return ""
 	}
-	blob, _ := json.Marshal(task)
-	return string(blob)
+	blob, _ := json.Marshal(task)
+	return string(blob)
+}
+
+func (r *Repository) restoreTaskFromSnapshot(m Mutation) error {
+	if r.store == nil || m.Snapshot == "" {
+		return nil
+	}
+	var t Task
+	if err := json.Unmarshal([]byte(m.Snapshot), &t); err != nil {
+		return err
+	}
+	if m.Action == MutationClose && t.Due != nil && t.Due.IsRecurring {
+		_ = r.store.DeleteCompletion(t.ID, t.Due.Date)
+	}
+	_ = r.store.DeleteCompletedTask(t.ID)
+	return r.store.UpsertTask(t)
+}
+
+// restoreTaskOrderFromSnapshot puts back the sibling order a rejected reorder
+// replaced, keeping any other local edits to those tasks.
+func (r *Repository) restoreTaskOrderFromSnapshot(m Mutation) error {
+	if r.store == nil || m.Snapshot == "" {
+		return nil
+	}
+	var siblings []Task
+	if err := json.Unmarshal([]byte(m.Snapshot), &siblings); err != nil {
+		return err
+	}
+	for _, s := range siblings {
+		cur, err := r.store.GetTaskByID(s.ID)
+		if err != nil || cur == nil {
+			continue
+		}
+		cur.ChildOrder = s.ChildOrder
+		if err := r.store.UpsertTask(*cur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) restoreCommentFromSnapshot(m Mutation) error {
+	if r.store == nil || m.Snapshot == "" {
+		return nil
+	}
+	var c Comment
+	if err := json.Unmarshal([]byte(m.Snapshot), &c); err != nil {
+		return err
+	}
+	return r.store.UpsertComment(c)
 }
 
-func (r *Repository) restoreTaskFromSnapshot(m Mutation) error {
+// restoreLabelFromSnapshot puts a renamed or deleted label back, along with
+// its name on the tasks that carried it.
+func (r *Repository) restoreLabelFromSnapshot(m Mutation) error {
 	if r.store == nil || m.Snapshot == "" {
 		return nil
 	}
-	var t Task
-	if err := json.Unmarshal([]byte(m.Snapshot), &t); err != nil {
+	var snap labelSnapshot
+	if err := json.Unmarshal([]byte(m.Snapshot), &snap); err != nil {
 		return err
 	}
-	_ = r.store.DeleteCompletedTask(t.ID)
-	return r.store.UpsertTask(t)
+	if cur, err := r.store.GetLabelByID(snap.ID); err == nil && cur != nil && cur.Name != snap.Name {
+		if _, err := r.store.ReplaceTaskLabel(cur.Name, snap.Name); err != nil {
+			return err
+		}
+	}
+	if m.Action == MutationDeleteLabel {
+		if err := r.store.AddTaskLabel(snap.Name, snap.TaskIDs); err != nil {
+			return err
+		}
+	}
+	return r.store.UpsertLabel(snap.Label)
+}
+
+// restoreFilterFromSnapshot puts back an edited or deleted filter, or every
+// filter's previous order after a rejected reorder.
+func (r *Repository) restoreFilterFromSnapshot(m Mutation) error {
+	if r.store == nil || m.Snapshot == "" {
+		return nil
+	}
+	var filters []SavedFilter
+	if m.Action == MutationReorderFilters {
+		if err := json.Unmarshal([]byte(m.Snapshot), &filters); err != nil {
+			return err
+		}
+	} else {
+		var f SavedFilter
+		if err := json.Unmarshal([]byte(m.Snapshot), &f); err != nil {
+			return err
+		}
+		filters = append(filters, f)
+	}
+	for _, f := range filters {
+		if m.Action == MutationReorderFilters {
+			// Only the order was changed; keep any other local edits.
+			cur, err := r.store.GetFilterByID(f.ID)
+			if err != nil || cur == nil {
+				continue
+			}
+			cur.ItemOrder = f.ItemOrder
+			f = *cur
+		}
+		if err := r.store.UpsertFilter(f); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *Repository) rollbackReopen(m Mutation) error {
@@ -687,6 +1855,82 @@ func (r *Repository) rollbackReopen(m Mutation) error {
 	return r.store.SaveCompletedTask(t, projectName)
 }
 
+// applyMoveToCache re-parents a cached task. The task and its subtree take on
+// the destination's project and section, and it goes last among its new siblings.
+func (r *Repository) applyMoveToCache(taskID string, req moveTaskRequest) Task {
+	if r.store == nil {
+		return Task{ID: taskID}
+	}
+	t, err := r.store.GetTaskByID(taskID)
+	if err != nil || t == nil {
+		return Task{ID: taskID}
+	}
+	task := *t
+	oldProjectID := task.ProjectID
+
+	switch {
+	case req.ParentID != nil:
+		if parent, err := r.store.GetTaskByID(*req.ParentID); err == nil && parent != nil {
+			task.ProjectID = parent.ProjectID
+			task.SectionID = parent.SectionID
+		}
+		pid := *req.ParentID
+		task.ParentID = &pid
+	case req.SectionID != nil:
+		task.ParentID = nil
+		task.SectionID = *req.SectionID
+		if sec, err := r.store.GetSectionByID(*req.SectionID); err == nil && sec != nil {
+			task.ProjectID = sec.ProjectID
+		}
+	case req.ProjectID != nil:
+		task.ParentID = nil
+		task.SectionID = ""
+		task.ProjectID = *req.ProjectID
+	}
+
+	siblings, _ := r.store.GetTasks(task.ProjectID)
+	task.ChildOrder = 1
+	for _, s := range siblings {
+		if s.ID != task.ID && sameParent(s.ParentID, task.ParentID) && s.SectionID == task.SectionID && s.ChildOrder >= task.ChildOrder {
+			task.ChildOrder = s.ChildOrder + 1
+		}
+	}
+	_ = r.store.UpsertTask(task)
+
+	// Subtasks always live in their parent's project and section.
+	all, _ := r.store.GetTasks(oldProjectID)
+	for _, d := range descendantsOf(all, task.ID) {
+		d.ProjectID = task.ProjectID
+		d.SectionID = task.SectionID
+		_ = r.store.UpsertTask(d)
+	}
+	return task
+}
+
+func sameParent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// descendantsOf returns every task nested under parentID, parents before children.
+func descendantsOf(tasks []Task, parentID string) []Task {
+	var out []Task
+	queue := []string{parentID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, t := range tasks {
+			if t.ParentID != nil && *t.ParentID == id {
+				out = append(out, t)
+				queue = append(queue, t.ID)
+			}
+		}
+	}
+	return out
+}
+
 func (r *Repository) applyUpdateToCache(taskID string, req updateTaskRequest) Task {
 	var task Task
 	if r.store != nil {
@@ -694,7 +1938,15 @@ func (r *Repository) applyUpdateToCache(taskID string, req updateTaskRequest) Ta
 			task = *t
 		}
 	}
+	task = applyUpdate(task, req)
+	if r.store != nil {
+		_ = r.store.UpsertTask(task)
+	}
+	return task
+}
 
+// applyUpdate returns task with the fields req sets changed.
+func applyUpdate(task Task, req updateTaskRequest) Task {
 	if req.Content != nil {
 		task.Content = *req.Content
 	}
@@ -723,84 +1975,9 @@ func (r *Repository) applyUpdateToCache(taskID string, req updateTaskRequest) Ta
 	if req.Labels != nil {
 		task.Labels = req.Labels
 	}
-
-	if r.store != nil {
-		_ = r.store.UpsertTask(task)
-	}
 	return task
 }
 
-func (r *Repository) detectConflict(snapshot, server Task, req updateTaskRequest) *string {
-	var conflicts []string
-
-	if req.Content != nil && snapshot.Content != server.Content {
-		conflicts = append(conflicts, fmt.Sprintf(
-			"content: you changed %q→%q, server has %q",
-			snapshot.Content, *req.Content, server.Content))
-	}
-	if req.Priority != nil && snapshot.Priority != server.Priority {
-		conflicts = append(conflicts, fmt.Sprintf(
-			"priority: you changed %d→%d, server has %d",
-			snapshot.Priority, *req.Priority, server.Priority))
-	}
-	if req.Description != nil && snapshot.Description != server.Description {
-		conflicts = append(conflicts, fmt.Sprintf(
-			"description: you changed %q→%q, server has %q",
-			snapshot.Description, *req.Description, server.Description))
-	}
-	if req.DueString != nil {
-		snapshotDue := ""
-		serverDue := ""
-		if snapshot.Due != nil {
-			snapshotDue = snapshot.Due.String
-		}
-		if server.Due != nil {
-			serverDue = server.Due.String
-		}
-		if snapshotDue != serverDue {
-			conflicts = append(conflicts, fmt.Sprintf(
-				"due: you changed %q→%q, server has %q",
-				snapshotDue, *req.DueString, serverDue))
-		}
-	}
-	if req.ClearDeadline || req.DeadlineDate != nil {
-		snapshotDeadline := ""
-		serverDeadline := ""
-		targetDeadline := ""
-		if snapshot.Deadline != nil {
-			snapshotDeadline = snapshot.Deadline.Date
-		}
-		if server.Deadline != nil {
-			serverDeadline = server.Deadline.Date
-		}
-		if req.ClearDeadline {
-			targetDeadline = "<clear>"
-		} else if req.DeadlineDate != nil {
-			targetDeadline = *req.DeadlineDate
-		}
-		if snapshotDeadline != serverDeadline {
-			conflicts = append(conflicts, fmt.Sprintf(
-				"deadline: you changed %q→%q, server has %q",
-				snapshotDeadline, targetDeadline, serverDeadline))
-		}
-	}
-	if req.Labels != nil {
-		snapshotLabels := strings.Join(snapshot.Labels, ",")
-		serverLabels := strings.Join(server.Labels, ",")
-		if snapshotLabels != serverLabels {
-			conflicts = append(conflicts, fmt.Sprintf(
-				"labels: snapshot=%q, server=%q",
-				snapshotLabels, serverLabels))
-		}
-	}
-
-	if len(conflicts) == 0 {
-		return nil
-	}
-	result := strings.Join(conflicts, "; ")
-	return &result
-}
-
 // --- Mutation queue access for queue view ---
 
 func (r *Repository) GetAllMutations() []Mutation {
@@ -813,6 +1990,30 @@ func (r *Repository) GetAllMutations() []Mutation {
 
 // TaskMutationStatusMap returns the strongest mutation status for each task entity ID.
 func (r *Repository) TaskMutationStatusMap() map[string]MutationStatus {
+	return r.mutationStatusMap("task")
+}
+
+// CommentMutationStatusMap returns the strongest mutation status for each comment ID.
+func (r *Repository) CommentMutationStatusMap() map[string]MutationStatus {
+	return r.mutationStatusMap("comment")
+}
+
+// LabelMutationStatusMap returns the strongest mutation status for each label ID.
+func (r *Repository) LabelMutationStatusMap() map[string]MutationStatus {
+	return r.mutationStatusMap("label")
+}
+
+// FilterMutationStatusMap returns the strongest mutation status for each saved filter ID.
+func (r *Repository) FilterMutationStatusMap() map[string]MutationStatus {
+	return r.mutationStatusMap("filter")
+}
+
+// SectionMutationStatusMap returns the strongest mutation status for each section ID.
+func (r *Repository) SectionMutationStatusMap() map[string]MutationStatus {
+	return r.mutationStatusMap("section")
+}
+
+func (r *Repository) mutationStatusMap(entityType string) map[string]MutationStatus {
 	out := make(map[string]MutationStatus)
 	if r.store == nil {
 		return out
@@ -834,7 +2035,7 @@ func (r *Repository) TaskMutationStatusMap() map[string]MutationStatus {
 		}
 	}
 	for _, m := range mutations {
-		if m.EntityType != "task" || m.EntityID == "" {
+		if m.EntityType != entityType || m.EntityID == "" {
 			continue
 		}
 		prev, ok := out[m.EntityID]
@@ -845,14 +2046,16 @@ func (r *Repository) TaskMutationStatusMap() map[string]MutationStatus {
 	return out
 }
 
-func (r *Repository) RetryMutation(id int64) tea.Cmd {
+// RetryMutation returns the given mutations (one, or a whole bulk edit) to
+// the pending queue.
+func (r *Repository) RetryMutation(ids ...int64) tea.Cmd {
 	return func() tea.Msg {
 		if r.store == nil {
 			return noopMsg{}
 		}
 		if muts, err := r.store.GetAllMutations(); err == nil {
 			for _, m := range muts {
-				if m.ID != id {
+				if !slices.Contains(ids, m.ID) {
 					continue
 				}
 				switch m.Action {
@@ -861,38 +2064,75 @@ func (r *Repository) RetryMutation(id int64) tea.Cmd {
 				case MutationReopen:
 					_ = r.rollbackReopen(m)
 				}
-				break
 			}
 		}
-		_ = r.store.UpdateMutationStatus(id, MutationPending, "")
+		for _, id := range ids {
+			_ = r.store.UpdateMutationStatus(id, MutationPending, "")
+			_ = r.store.UnblockDependents(id, blockedByCreateReason)
+		}
 		return flushNextMsg{}
 	}
 }
 
+// restoreForDismiss puts back the cached entity a dismissed mutation changed:
+// the server's copy if a conflict recorded one, otherwise the snapshot taken
+// before the local edit.
 func (r *Repository) restoreForDismiss(m Mutation) {
-	switch m.Action {
-	case MutationClose:
-		_ = r.restoreTaskFromSnapshot(m)
-	case MutationReopen:
-		_ = r.rollbackReopen(m)
+	if m.EntityType == "task" && m.Remote != "" {
+		var remote Task
+		if err := json.Unmarshal([]byte(m.Remote), &remote); err == nil {
+			_ = r.store.UpsertTask(remote)
+			return
+		}
+	}
+	r.rollbackMutation(m)
+}
+
+// dismissMutation deletes m from the queue. Dismissing a create also drops the
+// mutations queued behind it, creates included, and the placeholder task,
+// since none of them can ever flush.
+func (r *Repository) dismissMutation(m Mutation) {
+	if isCreateAction(m.Action) {
+		if deps, err := r.store.GetDependentMutations(m.ID); err == nil {
+			for i := len(deps) - 1; i >= 0; i-- {
+				r.dismissMutation(deps[i])
+			}
+		}
+		switch {
+		case !IsPendingID(m.EntityID):
+		case m.Action == MutationAddComment:
+			_ = r.store.DeleteComment(m.EntityID)
+		case m.Action == MutationCreateLabel:
+			_ = r.store.DeleteLabel(m.EntityID)
+		case m.Action == MutationCreateFilter:
+			_ = r.store.DeleteFilter(m.EntityID)
+		case m.Action == MutationCreateSection:
+			_ = r.store.DeleteSection(m.EntityID)
+		default:
+			_ = r.store.DeleteTask(m.EntityID)
+			_ = r.store.DeleteCompletedTask(m.EntityID)
+		}
+	} else {
+		r.restoreForDismiss(m)
 	}
+	_ = r.store.DiscardMutation(m.ID)
+	r.history.forget(m.ID)
 }
 
-func (r *Repository) DismissMutation(id int64) tea.Cmd {
+// DismissMutation drops the given mutations (one, or a whole bulk edit) from the queue.
+func (r *Repository) DismissMutation(ids ...int64) tea.Cmd {
 	return func() tea.Msg {
 		if r.store == nil {
 			return noopMsg{}
 		}
 		if muts, err := r.store.GetAllMutations(); err == nil {
-			for _, m := range muts {
-				if m.ID != id {
-					continue
+			// Newest first, so each task is restored to its state before the batch.
+			for i := len(muts) - 1; i >= 0; i-- {
+				if slices.Contains(ids, muts[i].ID) {
+					r.dismissMutation(muts[i])
 				}
-				r.restoreForDismiss(m)
-				break
 			}
 		}
-		_ = r.store.DeleteMutation(id)
 		return mutationEnqueuedMsg{count: r.store.PendingCount()}
 	}
 }
@@ -907,8 +2147,7 @@ func (r *Repository) DismissConflictedMutations() tea.Cmd {
 			return noopMsg{}
 		}
 		for _, m := range muts {
-			r.restoreForDismiss(m)
-			_ = r.store.DeleteMutation(m.ID)
+			r.dismissMutation(m)
 		}
 		return mutationEnqueuedMsg{count: r.store.PendingCount()}
 	}
@@ -924,13 +2163,343 @@ func (r *Repository) DismissAllMutations() tea.Cmd {
 			return noopMsg{}
 		}
 		for _, m := range muts {
-			r.restoreForDismiss(m)
-			_ = r.store.DeleteMutation(m.ID)
+			if m.DependsOn != 0 {
+				continue // dropped along with its create
+			}
+			r.dismissMutation(m)
 		}
 		return mutationEnqueuedMsg{count: r.store.PendingCount()}
 	}
 }
 
+// --- Project hierarchy (queued) ---
+
+// projectMovePayload is the queued payload for a project_move; a nil
+// ParentID moves the project to the top level. ParentName is for display only.
+type projectMovePayload struct {
+	ParentID   *string `json:"parent_id"`
+	ParentName string  `json:"parent_name,omitempty"`
+}
+
+// GetCollapsedProjects returns the IDs of projects collapsed in the sidebar.
+func (r *Repository) GetCollapsedProjects() map[string]bool {
+	if r.store == nil {
+		return nil
+	}
+	collapsed, _ := r.store.CollapsedProjects()
+	return collapsed
+}
+
+// SetProjectCollapsed persists a sidebar collapse toggle.
+func (r *Repository) SetProjectCollapsed(projectID string, collapsed bool) {
+	if r.store == nil {
+		return
+	}
+	_ = r.store.SetProjectCollapsed(projectID, collapsed)
+}
+
+// MoveProject optimistically re-parents a project, placing it last among its
+// new siblings. A nil parentID moves it to the top level.
+func (r *Repository) MoveProject(projectID string, parentID *string) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return projectMovedMsg{err: fmt.Errorf("no cache")}
+		}
+		p, err := r.store.GetProjectByID(projectID)
+		if err != nil || p == nil {
+			return projectMovedMsg{err: fmt.Errorf("project not found")}
+		}
+		snapshot, _ := json.Marshal(p)
+		moved := *p
+		moved.ParentID = parentID
+		moved.ChildOrder = 1
+		req := projectMovePayload{ParentID: parentID}
+		for _, other := range r.GetCachedProjects() {
+			if parentID != nil && other.ID == *parentID {
+				req.ParentName = other.Name
+			}
+			if other.ID != projectID && sameParent(other.ParentID, parentID) && other.ChildOrder >= moved.ChildOrder {
+				moved.ChildOrder = other.ChildOrder + 1
+			}
+		}
+		_ = r.store.UpsertProject(moved)
+		payload, _ := json.Marshal(req)
+		r.enqueue(Mutation{
+			EntityType: "project",
+			EntityID:   projectID,
+			Action:     MutationMoveProject,
+			Payload:    string(payload),
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return projectMovedMsg{project: moved}
+	}
+}
+
+// ReorderProject swaps a project with its neighbouring sibling delta places
+// away and queues the new order for the whole sibling group.
+func (r *Repository) ReorderProject(projectID string, delta int) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return projectMovedMsg{err: fmt.Errorf("no cache")}
+		}
+		p, err := r.store.GetProjectByID(projectID)
+		if err != nil || p == nil {
+			return projectMovedMsg{err: fmt.Errorf("project not found")}
+		}
+		var siblings []Project
+		for _, sib := range sortProjects(r.GetCachedProjects()) {
+			if sameParent(sib.ParentID, p.ParentID) && !sib.InboxProject {
+				siblings = append(siblings, sib)
+			}
+		}
+		i := slices.IndexFunc(siblings, func(s Project) bool { return s.ID == projectID })
+		j := i + delta
+		if i < 0 || j < 0 || j >= len(siblings) {
+			return noopMsg{}
+		}
+		snapshot, _ := json.Marshal(siblings)
+		siblings[i], siblings[j] = siblings[j], siblings[i]
+		order := make(map[string]int, len(siblings))
+		refs := make([]string, 0, len(siblings))
+		for k := range siblings {
+			siblings[k].ChildOrder = k + 1
+			order[siblings[k].ID] = k + 1
+			refs = append(refs, siblings[k].ID)
+			_ = r.store.UpsertProject(siblings[k])
+		}
+		payload, _ := json.Marshal(order)
+		r.enqueue(Mutation{
+			EntityType: "project",
+			EntityID:   projectID,
+			Action:     MutationReorderProjects,
+			Payload:    string(payload),
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		}, refs...)
+		return projectMovedMsg{project: siblings[j]}
+	}
+}
+
+// restoreProjectFromSnapshot undoes a rejected move, or puts back the
+// sibling order a rejected reorder replaced.
+func (r *Repository) restoreProjectFromSnapshot(m Mutation) error {
+	if r.store == nil || m.Snapshot == "" {
+		return nil
+	}
+	if m.Action == MutationMoveProject {
+		var p Project
+		if err := json.Unmarshal([]byte(m.Snapshot), &p); err != nil {
+			return err
+		}
+		return r.store.UpsertProject(p)
+	}
+	var siblings []Project
+	if err := json.Unmarshal([]byte(m.Snapshot), &siblings); err != nil {
+		return err
+	}
+	for _, s := range siblings {
+		cur, err := r.store.GetProjectByID(s.ID)
+		if err != nil || cur == nil {
+			continue
+		}
+		cur.ChildOrder = s.ChildOrder
+		if err := r.store.UpsertProject(*cur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- Sections (queued) ---
+
+// sectionPayload is the queued payload for a section create or rename.
+type sectionPayload struct {
+	ProjectID string `json:"project_id,omitempty"`
+	Name      string `json:"name"`
+}
+
+// sectionSnapshot records a removed section with the tasks that went with it,
+// so a rejected archive or delete can put both back.
+type sectionSnapshot struct {
+	Section
+	Tasks []Task `json:"tasks,omitempty"`
+}
+
+// CreateSection optimistically adds a section at the end of a project.
+func (r *Repository) CreateSection(projectID, name string) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return sectionUpdatedMsg{err: fmt.Errorf("no cache")}
+		}
+		sections := r.GetCachedSections(projectID)
+		order := 1
+		if len(sections) > 0 {
+			order = sections[len(sections)-1].SectionOrder + 1
+		}
+		sec := Section{ID: NewPendingID(), ProjectID: projectID, Name: name, SectionOrder: order}
+		_ = r.store.UpsertSection(sec)
+		payload, _ := json.Marshal(sectionPayload{ProjectID: projectID, Name: name})
+		r.enqueue(Mutation{
+			EntityType: "section",
+			EntityID:   sec.ID,
+			Action:     MutationCreateSection,
+			Payload:    string(payload),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return sectionUpdatedMsg{section: sec}
+	}
+}
+
+// RenameSection optimistically renames a section.
+func (r *Repository) RenameSection(sectionID, name string) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return sectionUpdatedMsg{err: fmt.Errorf("no cache")}
+		}
+		sec, err := r.store.GetSectionByID(sectionID)
+		if err != nil || sec == nil {
+			return sectionUpdatedMsg{err: fmt.Errorf("section not found")}
+		}
+		snapshot, _ := json.Marshal(sec)
+		renamed := *sec
+		renamed.Name = name
+		_ = r.store.UpsertSection(renamed)
+		payload, _ := json.Marshal(sectionPayload{Name: name})
+		r.enqueue(Mutation{
+			EntityType: "section",
+			EntityID:   sectionID,
+			Action:     MutationRenameSection,
+			Payload:    string(payload),
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return sectionUpdatedMsg{section: renamed}
+	}
+}
+
+// ArchiveSection optimistically archives a section; its tasks are archived
+// with it and leave the project view.
+func (r *Repository) ArchiveSection(sectionID string) tea.Cmd {
+	return r.removeSection(sectionID, MutationArchiveSection)
+}
+
+// DeleteSection optimistically deletes a section and every task in it.
+func (r *Repository) DeleteSection(sectionID string) tea.Cmd {
+	return r.removeSection(sectionID, MutationDeleteSection)
+}
+
+func (r *Repository) removeSection(sectionID string, action MutationAction) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return sectionUpdatedMsg{err: fmt.Errorf("no cache")}
+		}
+		sec, err := r.store.GetSectionByID(sectionID)
+		if err != nil || sec == nil {
+			return sectionUpdatedMsg{err: fmt.Errorf("section not found")}
+		}
+		snap := sectionSnapshot{Section: *sec}
+		tasks, _ := r.store.GetTasks(sec.ProjectID)
+		for _, t := range tasks {
+			if t.SectionID == sectionID {
+				snap.Tasks = append(snap.Tasks, t)
+				_ = r.store.DeleteTask(t.ID)
+			}
+		}
+		_ = r.store.DeleteSection(sectionID)
+		snapshot, _ := json.Marshal(snap)
+		r.enqueue(Mutation{
+			EntityType: "section",
+			EntityID:   sectionID,
+			Action:     action,
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		})
+		return sectionUpdatedMsg{section: *sec}
+	}
+}
+
+// MoveSection swaps a section with its neighbour delta places away and queues
+// the new order for every section in the project.
+func (r *Repository) MoveSection(sectionID string, delta int) tea.Cmd {
+	return func() tea.Msg {
+		if r.store == nil {
+			return sectionUpdatedMsg{err: fmt.Errorf("no cache")}
+		}
+		sec, err := r.store.GetSectionByID(sectionID)
+		if err != nil || sec == nil {
+			return sectionUpdatedMsg{err: fmt.Errorf("section not found")}
+		}
+		sections := r.GetCachedSections(sec.ProjectID)
+		i := slices.IndexFunc(sections, func(s Section) bool { return s.ID == sectionID })
+		j := i + delta
+		if i < 0 || j < 0 || j >= len(sections) {
+			return noopMsg{}
+		}
+		snapshot, _ := json.Marshal(sections)
+		sections[i], sections[j] = sections[j], sections[i]
+		order := make(map[string]int, len(sections))
+		refs := make([]string, 0, len(sections))
+		for k := range sections {
+			sections[k].SectionOrder = k + 1
+			order[sections[k].ID] = k + 1
+			refs = append(refs, sections[k].ID)
+			_ = r.store.UpsertSection(sections[k])
+		}
+		payload, _ := json.Marshal(order)
+		r.enqueue(Mutation{
+			EntityType: "section",
+			EntityID:   sectionID,
+			Action:     MutationReorderSections,
+			Payload:    string(payload),
+			Snapshot:   string(snapshot),
+			Status:     MutationPending,
+			CreatedAt:  time.Now(),
+		}, refs...)
+		return sectionUpdatedMsg{section: sections[j]}
+	}
+}
+
+// restoreSectionFromSnapshot undoes a rejected rename, archive, delete or
+// reorder.
+func (r *Repository) restoreSectionFromSnapshot(m Mutation) error {
+	if r.store == nil || m.Snapshot == "" {
+		return nil
+	}
+	if m.Action == MutationReorderSections {
+		var sections []Section
+		if err := json.Unmarshal([]byte(m.Snapshot), &sections); err != nil {
+			return err
+		}
+		for _, s := range sections {
+			cur, err := r.store.GetSectionByID(s.ID)
+			if err != nil || cur == nil {
+				continue
+			}
+			cur.SectionOrder = s.SectionOrder
+			if err := r.store.UpsertSection(*cur); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var snap sectionSnapshot
+	if err := json.Unmarshal([]byte(m.Snapshot), &snap); err != nil {
+		return err
+	}
+	for _, t := range snap.Tasks {
+		if err := r.store.UpsertTask(t); err != nil {
+			return err
+		}
+	}
+	return r.store.UpsertSection(snap.Section)
+}
+
 // --- Project operations (direct API, no mutation queue) ---
 
 func (r *Repository) CreateProject(name string) tea.Cmd {
@@ -1003,6 +2572,24 @@ func (r *Repository) GetRecentlyCompleted(limit int) []CompletedTaskRow {
 	return rows
 }
 
+// GetCompletedSubtaskCounts returns completed subtask counts keyed by parent task ID.
+func (r *Repository) GetCompletedSubtaskCounts(projectID string) map[string]int {
+	if r.store == nil {
+		return nil
+	}
+	counts, _ := r.store.CompletedSubtaskCounts(projectID)
+	return counts
+}
+
+// GetCompletionHistory returns the completed occurrences of a recurring task.
+func (r *Repository) GetCompletionHistory(taskID string) []CompletionRecord {
+	if r.store == nil {
+		return nil
+	}
+	history, _ := r.store.GetCompletionHistory(taskID)
+	return history
+}
+
 func (r *Repository) GetArchivedProjects() []Project {
 	if r.store == nil {
 		return nil
@@ -1041,6 +2628,7 @@ func (r *Repository) RefreshAssigneeDirectory() tea.Cmd {
 		if me, err := r.client.GetCurrentUserDirectoryEntry(context.Background()); err != nil {
 			errs = append(errs, "user lookup failed: "+err.Error())
 		} else if me != nil && me.ID != "" && me.Name != "" {
+			_ = r.store.SetCurrentUserID(me.ID)
 			if names[me.ID] != me.Name {
 				updated++
 			}
@@ -1143,54 +2731,3 @@ func (r *Repository) projectsWithAssignees(assigneeIDs map[string]bool) []string
 	}
 	return out
 }
-
-// --- Background cache warming ---
-
-// FindStaleProjects returns a list of project IDs whose task or section cache is past TTL.
-func (r *Repository) FindStaleProjects() tea.Cmd {
-	return func() tea.Msg {
-		if r.store == nil {
-			return noopMsg{}
-		}
-		projects, err := r.store.GetProjects()
-		if err != nil || len(projects) == 0 {
-			return noopMsg{}
-		}
-		var stale []string
-		for _, p := range projects {
-			if r.store.IsStale("tasks", p.ID) || r.store.IsStale("sections", p.ID) {
-				stale = append(stale, p.ID)
-			}
-		}
-		if len(stale) == 0 {
-			return noopMsg{}
-		}
-		return backgroundRefreshMsg{staleProjects: stale}
-	}
-}
-
-// BackgroundRefreshProject warms the cache for a single project.
-// It skips projects that have become fresh since queueing (e.g. user navigated there).
-func (r *Repository) BackgroundRefreshProject(projectID string, remaining []string) tea.Cmd {
-	return func() tea.Msg {
-		if r.store == nil {
-			return backgroundRefreshDoneMsg{remaining: remaining}
-		}
-		taskStale := r.store.IsStale("tasks", projectID)
-		sectionStale := r.store.IsStale("sections", projectID)
-
-		// Skip if both are already fresh
-		if !taskStale && !sectionStale {
-			return backgroundRefreshDoneMsg{remaining: remaining}
-		}
-
-		// Refresh whichever is stale
-		if taskStale {
-			r.fetchTasksFromAPI(projectID)
-		}
-		if sectionStale {
-			r.fetchSectionsFromAPI(projectID)
-		}
-		return backgroundRefreshDoneMsg{remaining: remaining}
-	}
-}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	return NewRepository(NewClient("test"), NewSyncClient("test"), newTestStore(t))
+}
+
+// withAPIServer points the API clients at handler for the rest of the test.
+func withAPIServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	old := baseURL
+	baseURL = srv.URL
+	t.Cleanup(func() { baseURL = old })
+}
+
+// writeJSON encodes v as a test server's response.
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Error(err)
+	}
+}
+
+// syncCommands decodes the write commands of a request to the Sync endpoint.
+func syncCommands(t *testing.T, req *http.Request) []SyncCommand {
+	t.Helper()
+	var cmds []SyncCommand
+	if raw := req.FormValue("commands"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cmds); err != nil {
+			t.Errorf("commands: %v", err)
+		}
+	}
+	return cmds
+}
+
+func mutationsByID(t *testing.T, s *Store) map[int64]Mutation {
+	t.Helper()
+	muts, err := s.GetAllMutations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(map[int64]Mutation, len(muts))
+	for _, m := range muts {
+		out[m.ID] = m
+	}
+	return out
+}
+
+// queueSubtaskChain queues a pending parent, a pending subtask under it and an
+// edit to the subtask, returning the three mutation IDs.
+func queueSubtaskChain(t *testing.T, r *Repository) (parentID, childID string, ids [3]int64) {
+	t.Helper()
+	parentID, childID = NewPendingID(), NewPendingID()
+	now := time.Now()
+	r.enqueue(Mutation{EntityType: "task", EntityID: parentID, Action: MutationCreate,
+		Payload: `{"content":"parent"}`, Status: MutationPending, CreatedAt: now})
+	r.enqueue(Mutation{EntityType: "task", EntityID: childID, Action: MutationCreate,
+		Payload: `{"content":"child","parent_id":"` + parentID + `"}`, Status: MutationPending, CreatedAt: now}, parentID)
+	r.enqueue(Mutation{EntityType: "task", EntityID: childID, Action: MutationUpdate,
+		Payload: `{"content":"child v2"}`, Status: MutationPending, CreatedAt: now})
+	muts, err := r.store.GetAllMutations()
+	if err != nil || len(muts) != 3 {
+		t.Fatalf("queued %d mutations (%v), want 3", len(muts), err)
+	}
+	for i, m := range muts {
+		ids[i] = m.ID
+	}
+	return parentID, childID, ids
+}
+
+func TestDependencyChainRemap(t *testing.T) {
+	r := newTestRepository(t)
+	parentID, childID, ids := queueSubtaskChain(t, r)
+
+	muts := mutationsByID(t, r.store)
+	if got := muts[ids[1]].DependsOn; got != ids[0] {
+		t.Fatalf("subtask create depends on %d, want parent create %d", got, ids[0])
+	}
+	if got := muts[ids[2]].DependsOn; got != ids[1] {
+		t.Fatalf("subtask edit depends on %d, want subtask create %d", got, ids[1])
+	}
+
+	// The parent flushes: its temp ID is remapped and its dependents released.
+	if err := r.store.RemapPendingID(parentID, "real-parent"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.store.DeleteMutation(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.store.ReleaseDependents(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	muts = mutationsByID(t, r.store)
+	child := muts[ids[1]]
+	if child.DependsOn != 0 {
+		t.Errorf("subtask create still depends on %d after parent flushed", child.DependsOn)
+	}
+	if strings.Contains(child.Payload, parentID) || !strings.Contains(child.Payload, "real-parent") {
+		t.Errorf("subtask payload not remapped: %s", child.Payload)
+	}
+	if got := muts[ids[2]].DependsOn; got != ids[1] {
+		t.Errorf("subtask edit released early: depends on %d, want %d", got, ids[1])
+	}
+
+	// Then the subtask flushes.
+	if err := r.store.RemapPendingID(childID, "real-child"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.store.ReleaseDependents(ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	edit := mutationsByID(t, r.store)[ids[2]]
+	if edit.EntityID != "real-child" || edit.DependsOn != 0 {
+		t.Errorf("subtask edit = %s depends on %d, want real-child with no dependency", edit.EntityID, edit.DependsOn)
+	}
+}
+
+func TestConflictedCreateBlocksDependents(t *testing.T) {
+	r := newTestRepository(t)
+	_, _, ids := queueSubtaskChain(t, r)
+
+	conflict := encodeConflicts(ConflictRecord{Reason: "invalid argument"})
+	if err := r.store.UpdateMutationStatus(ids[0], MutationConflicted, conflict); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.store.BlockDependents(encodeConflicts(ConflictRecord{Reason: blockedByCreateReason})); err != nil {
+		t.Fatal(err)
+	}
+	muts := mutationsByID(t, r.store)
+	for _, id := range ids[1:] {
+		if muts[id].Status != MutationConflicted {
+			t.Errorf("dependent %d is %s, want conflicted", id, muts[id].Status)
+		}
+	}
+	if n := r.PendingCount(); n != 0 {
+		t.Errorf("PendingCount = %d with every mutation blocked", n)
+	}
+
+	// Retrying the create releases the chain behind it.
+	r.RetryMutation(ids[0])()
+	muts = mutationsByID(t, r.store)
+	for _, id := range ids {
+		if muts[id].Status != MutationPending {
+			t.Errorf("mutation %d is %s after retry, want pending", id, muts[id].Status)
+		}
+	}
+
+	// Dismissing it drops the whole chain.
+	r.DismissMutation(ids[0])()
+	if muts := mutationsByID(t, r.store); len(muts) != 0 {
+		t.Errorf("%d mutations left after dismissing the create", len(muts))
+	}
+}
+
+func TestReorderProjectHoldsSiblingsAgainstSync(t *testing.T) {
+	r := newTestRepository(t)
+	server := []Project{{ID: "a", Name: "A", ChildOrder: 1}, {ID: "b", Name: "B", ChildOrder: 2}, {ID: "c", Name: "C", ChildOrder: 3}}
+	for _, p := range server {
+		if err := r.store.UpsertProject(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.ReorderProject("a", 1)()
+
+	// A sync before the reorder flushes still reports the old order.
+	if err := r.store.MergeSyncResponse(&SyncResponse{SyncToken: "tok", Projects: server}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"a": 2, "b": 1, "c": 3}
+	for id, order := range want {
+		p, _ := r.store.GetProjectByID(id)
+		if p == nil || p.ChildOrder != order {
+			t.Errorf("project %s order = %+v, want %d", id, p, order)
+		}
+	}
+}
+
+func TestReorderTaskHoldsSiblingsAgainstSync(t *testing.T) {
+	r := newTestRepository(t)
+	server := []Task{
+		{ID: "t1", ProjectID: "p", Content: "one", ChildOrder: 1},
+		{ID: "t2", ProjectID: "p", Content: "two", ChildOrder: 2},
+		{ID: "t3", ProjectID: "p", Content: "three", ChildOrder: 3},
+	}
+	for _, task := range server {
+		if err := r.store.UpsertTask(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.ReorderTask("t3", -2)()
+
+	if err := r.store.MergeSyncResponse(&SyncResponse{SyncToken: "tok", FullSync: true, Items: server}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"t3": 1, "t1": 2, "t2": 3}
+	for id, order := range want {
+		task, _ := r.store.GetTaskByID(id)
+		if task == nil || task.ChildOrder != order {
+			t.Errorf("task %s order = %+v, want %d", id, task, order)
+		}
+	}
+}
+
+func TestDismissRestoresServerCopyOrSnapshot(t *testing.T) {
+	r := newTestRepository(t)
+	if err := r.store.UpsertTask(Task{ID: "t1", Content: "original", ProjectID: "p"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.store.UpsertTask(Task{ID: "t2", Content: "original", ProjectID: "p"}); err != nil {
+		t.Fatal(err)
+	}
+	edit := func(id string) {
+		content := "local edit"
+		r.UpdateTask(id, updateTaskRequest{Content: &content})()
+	}
+	edit("t1")
+	edit("t2")
+	muts, _ := r.store.GetAllMutations()
+	if len(muts) != 2 {
+		t.Fatalf("queued %d mutations, want 2", len(muts))
+	}
+	remote := `{"id":"t1","project_id":"p","content":"server edit"}`
+	if err := r.store.MarkMutationConflicted(muts[0].ID, encodeConflicts(ConflictRecord{Field: fieldContent}), remote); err != nil {
+		t.Fatal(err)
+	}
+
+	r.DismissMutation(muts[0].ID, muts[1].ID)()
+	for id, want := range map[string]string{"t1": "server edit", "t2": "original"} {
+		if task, _ := r.store.GetTaskByID(id); task == nil || task.Content != want {
+			t.Errorf("%s after dismiss = %+v, want content %q", id, task, want)
+		}
+	}
+}
+
+func TestFlushPendingAppliesSyncStatus(t *testing.T) {
+	r := newTestRepository(t)
+	for _, task := range []Task{
+		{ID: "gone", ProjectID: "p", Content: "closed elsewhere"},
+		{ID: "deleted", ProjectID: "p", Content: "original"},
+		{ID: "busy", ProjectID: "p", Content: "original"},
+	} {
+		if err := r.store.UpsertTask(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	edit := func(id, content string) { r.UpdateTask(id, updateTaskRequest{Content: &content})() }
+	create := func(content string) string {
+		msg := r.CreateTask(createTaskRequest{Content: content, ProjectID: "p"})().(taskCreatedMsg)
+		return msg.task.ID
+	}
+	tempA, tempB, tempC := create("A"), create("B"), create("C")
+	edit(tempA, "A v2")
+	edit(tempB, "B v2")
+	edit(tempC, "C v2")
+	r.CloseTask("gone")()
+	edit("deleted", "local edit")
+	edit("busy", "local edit")
+
+	var sent []SyncCommand
+	withAPIServer(t, func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/sync" {
+			t.Errorf("unexpected request %s", req.URL.Path)
+			http.NotFound(w, req)
+			return
+		}
+		cmds := syncCommands(t, req)
+		resp := map[string]any{"sync_token": "tok"}
+		if len(cmds) == 0 {
+			writeJSON(t, w, resp)
+			return
+		}
+		sent = cmds
+		fail := func(code int, msg string) map[string]any {
+			return map[string]any{"error_tag": "ERR", "error_code": 1, "error": msg, "http_code": code}
+		}
+		status := map[string]any{}
+		for _, cmd := range cmds {
+			id, _ := cmd.Args["id"].(string)
+			content, _ := cmd.Args["content"].(string)
+			switch {
+			case cmd.Type == "item_add" && content == "B":
+				status[cmd.UUID] = fail(http.StatusBadRequest, "Invalid argument value")
+			case cmd.Type == "item_add" && content == "C":
+				status[cmd.UUID] = fail(http.StatusServiceUnavailable, "Service unavailable")
+			case id == tempB || id == tempC:
+				status[cmd.UUID] = fail(http.StatusBadRequest, "Invalid temporary id")
+			case id == "gone" || id == "deleted":
+				status[cmd.UUID] = fail(http.StatusNotFound, "Item not found")
+			case id == "busy":
+				status[cmd.UUID] = fail(http.StatusTooManyRequests, "Too many requests")
+			default:
+				status[cmd.UUID] = "ok"
+			}
+		}
+		resp["sync_status"] = status
+		resp["temp_id_mapping"] = map[string]string{tempA: "real-a"}
+		resp["items"] = []Task{{ID: "real-a", ProjectID: "p", Content: "A v2"}}
+		writeJSON(t, w, resp)
+	})
+
+	done, ok := r.FlushPending()().(flushDoneMsg)
+	if !ok {
+		t.Fatal("FlushPending sent nothing")
+	}
+	if len(sent) != 9 {
+		t.Errorf("sent %d commands, want 9", len(sent))
+	}
+	if done.flushed != 3 || done.conflicts != 2 || done.err == nil {
+		t.Errorf("flush = %d flushed, %d conflicts, err %v; want 3, 2 and a retriable error", done.flushed, done.conflicts, done.err)
+	}
+	if len(done.created) != 1 || !slices.Contains(slices.Collect(maps.Values(done.created)), "real-a") {
+		t.Errorf("created = %v, want only the create of A as real-a", done.created)
+	}
+
+	type row struct {
+		status MutationStatus
+		reason string
+	}
+	got := map[string]row{}
+	muts, err := r.store.GetAllMutations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range muts {
+		var reason string
+		if records := m.Conflicts(); len(records) > 0 {
+			reason = records[0].Reason
+		}
+		got[string(m.Action)+" "+m.EntityID] = row{m.Status, reason}
+	}
+	want := map[string]row{
+		"create " + tempB: {MutationConflicted, "Invalid argument value"},
+		"update " + tempB: {MutationConflicted, blockedByCreateReason},
+		"create " + tempC: {MutationPending, ""},
+		"update " + tempC: {MutationPending, ""},
+		"update deleted":  {MutationConflicted, "task deleted on server"},
+		"update busy":     {MutationPending, ""},
+	}
+	if len(got) != len(want) {
+		t.Errorf("queue after flush = %v, want %v", got, want)
+	}
+	for key, w := range want {
+		if got[key] != w {
+			t.Errorf("%s = %+v, want %+v", key, got[key], w)
+		}
+	}
+
+	if task, _ := r.store.GetTaskByID(tempA); task != nil {
+		t.Errorf("temp task %s still cached after its create flushed", tempA)
+	}
+	if task, _ := r.store.GetTaskByID("real-a"); task == nil || task.Content != "A v2" {
+		t.Errorf("real-a = %+v, want the created task", task)
+	}
+	for id, want := range map[string]string{"deleted": "original", "busy": "local edit"} {
+		if task, _ := r.store.GetTaskByID(id); task == nil || task.Content != want {
+			t.Errorf("%s = %+v, want content %q", id, task, want)
+		}
+	}
+	if got := r.store.GetSyncToken(); got != "tok" {
+		t.Errorf("sync token = %q, want tok", got)
+	}
+}
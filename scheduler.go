@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Background sync runs every interval while online. A network error takes the
+// app offline; it then retries with exponential backoff and jitter, and the
+// first successful sync brings it back online and flushes the queue.
+// TODOIST_SYNC_INTERVAL sets the interval ("90s", "10m"); "0" or "off" turns
+// periodic sync off, though offline retries still run.
+
+const (
+	defaultSyncInterval = 5 * time.Minute
+	minSyncBackoff      = 5 * time.Second
+	maxSyncBackoff      = 5 * time.Minute
+)
+
+type connState int
+
+const (
+	connOnline connState = iota
+	connOffline
+)
+
+// syncTickMsg fires a scheduled background sync. Ticks from a schedule that
+// has since been replaced carry an old generation and are dropped.
+type syncTickMsg struct{ gen int }
+
+type syncScheduler struct {
+	interval time.Duration
+	state    connState
+	failures int       // consecutive failed round-trips
+	gen      int       // generation of the pending tick
+	next     time.Time // when the pending tick fires; zero if none
+}
+
+func newSyncScheduler(interval time.Duration) syncScheduler {
+	return syncScheduler{interval: interval}
+}
+
+// syncIntervalFromEnv reads TODOIST_SYNC_INTERVAL, falling back to the
+// default when it is unset or malformed.
+func syncIntervalFromEnv() time.Duration {
+	v := strings.TrimSpace(os.Getenv("TODOIST_SYNC_INTERVAL"))
+	if v == "off" || v == "0" {
+		return 0
+	}
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return d
+	}
+	return defaultSyncInterval
+}
+
+// schedule replaces any pending tick with one after d.
+func (s *syncScheduler) schedule(d time.Duration) tea.Cmd {
+	s.gen++
+	gen := s.gen
+	s.next = time.Now().Add(d)
+	return tea.Tick(d, func(time.Time) tea.Msg { return syncTickMsg{gen: gen} })
+}
+
+func (s *syncScheduler) stop() {
+	s.gen++
+	s.next = time.Time{}
+}
+
+// succeeded records a successful round-trip and schedules the next periodic
+// sync. It reports whether the app was offline until now.
+func (s *syncScheduler) succeeded() (tea.Cmd, bool) {
+	reconnected := s.state == connOffline
+	s.state = connOnline
+	s.failures = 0
+	if s.interval <= 0 {
+		s.stop()
+		return nil, reconnected
+	}
+	return s.schedule(s.interval), reconnected
+}
+
+// failed records a failed round-trip. Network errors take the app offline,
+// while an HTTP error shows the server is reachable. Retriable errors back
+// off, for at least the server's Retry-After; anything else (a revoked token,
+// say) waits for the next periodic sync. It reports whether the failure is
+// news: the first of a run, rather than another retry still failing.
+func (s *syncScheduler) failed(err error) (tea.Cmd, bool) {
+	if isNetworkError(err) {
+		s.state = connOffline
+	} else {
+		s.state = connOnline
+	}
+	if !isRetriableMutationError(err) {
+		if s.interval <= 0 {
+			s.stop()
+			return nil, true
+		}
+		return s.schedule(s.interval), true
+	}
+	s.failures++
+	return s.schedule(max(s.backoff(), retryHint(err))), s.failures == 1
+}
+
+// backoff doubles from minSyncBackoff per consecutive failure up to
+// maxSyncBackoff. Half of it is random so clients don't retry in lockstep.
+func (s *syncScheduler) backoff() time.Duration {
+	d := maxSyncBackoff
+	if s.failures <= 10 {
+		d = min(minSyncBackoff<<(s.failures-1), maxSyncBackoff)
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// status describes the connection for the header, or "" while online.
+func (s syncScheduler) status() string {
+	if s.state != connOffline {
+		return ""
+	}
+	wait := time.Until(s.next).Round(time.Second)
+	if s.next.IsZero() || wait <= 0 {
+		return "offline · retrying"
+	}
+	return "offline · retry in " + wait.String()
+}
+
+// isNetworkError reports whether err means the server couldn't be reached:
+// a refused or reset connection, a failed DNS lookup, a timeout, or any other
+// transport failure before a response. HTTP error responses, cancellations
+// and local failures such as a cache write are not.
+func isNetworkError(err error) bool {
+	if err == nil || apiErrorStatus(err) != 0 || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	var netErr net.Error // includes *url.Error and *net.OpError
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTUNREACH) ||
+		errors.As(err, &dnsErr) || errors.As(err, &netErr)
+}
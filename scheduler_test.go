@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsNetworkError(t *testing.T) {
+	refused := &url.Error{Op: "Post", URL: "https://api.todoist.com", Err: &net.OpError{
+		Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED),
+	}}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"connection refused", refused, true},
+		{"wrapped connection refused", fmt.Errorf("sync: %w", refused), true},
+		{"bare errno", syscall.ECONNRESET, true},
+		{"dns", &net.DNSError{Err: "no such host", Name: "api.todoist.com", IsNotFound: true}, true},
+		{"timeout", &url.Error{Op: "Post", URL: "u", Err: context.DeadlineExceeded}, true},
+		{"http error", &APIError{StatusCode: http.StatusServiceUnavailable}, false},
+		{"client rate limit", &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}, false},
+		{"cancelled", &url.Error{Op: "Post", URL: "u", Err: context.Canceled}, false},
+		{"cache write", fmt.Errorf("merge sync: %w", errors.New("database is locked")), false},
+		{"command error", &SyncCommandError{Message: "internal", HTTPCode: 500}, false},
+	}
+	for _, tt := range tests {
+		if got := isNetworkError(tt.err); got != tt.want {
+			t.Errorf("%s: isNetworkError = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSchedulerFailed(t *testing.T) {
+	s := newSyncScheduler(time.Minute)
+	if _, news := s.failed(&net.DNSError{Err: "no such host"}); !news || s.state != connOffline || s.failures != 1 {
+		t.Errorf("DNS failure: news %v, state %v, failures %d", news, s.state, s.failures)
+	}
+	if _, news := s.failed(&net.DNSError{Err: "no such host"}); news || s.failures != 2 {
+		t.Errorf("repeat failure: news %v, failures %d", news, s.failures)
+	}
+
+	// A local failure is reported, but doesn't mean the app is offline.
+	s = newSyncScheduler(time.Minute)
+	if _, news := s.failed(errors.New("merge sync: disk full")); !news || s.state != connOnline {
+		t.Errorf("local failure: news %v, state %v", news, s.state)
+	}
+
+	// A command the server couldn't process yet backs off; one it rejected
+	// waits for the next periodic sync.
+	s = newSyncScheduler(time.Minute)
+	s.failed(&SyncCommandError{HTTPCode: http.StatusServiceUnavailable})
+	if s.failures != 1 {
+		t.Errorf("retriable command error: failures = %d, want a backoff", s.failures)
+	}
+	s = newSyncScheduler(time.Minute)
+	s.failed(&SyncCommandError{HTTPCode: http.StatusBadRequest})
+	if s.failures != 0 {
+		t.Errorf("rejected command: failures = %d, want no backoff", s.failures)
+	}
+}
@@ -52,6 +52,17 @@ func containsMatch(text, query string) bool {
 	return strings.Contains(strings.ToLower(text), strings.ToLower(query))
 }
 
+// containsAllWords returns true if text contains every word of query, in any
+// order, so "work mtg" finds "Work / Meetings".
+func containsAllWords(text, query string) bool {
+	for _, w := range strings.Fields(query) {
+		if !containsMatch(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
 // findMatchIndices returns indices of items whose task content matches the query.
 func findMatchIndices(items []displayItem, query string) []int {
 	if query == "" {
@@ -187,6 +198,7 @@ const (
 	searchResultAction searchResultKind = iota
 	searchResultTask
 	searchResultProject
+	searchResultDestination
 )
 
 type searchResult struct {
@@ -197,6 +209,8 @@ type searchResult struct {
 	task        *Task
 	project     *Project
 	projectName string // for task results: the project name
+	section     *Section
+	path        string // for destinations: "Project / Section"
 }
 
 // --- SearchView ---
@@ -216,6 +230,10 @@ type SearchView struct {
 	projectMap  map[string]string // id -> name
 	context     InputContext
 	actions     []DiscoverableAction
+
+	// Move picker: destinations for moveTasks instead of the global search
+	moveTasks    []Task
+	destinations []searchResult
 }
 
 func NewSearchView(repo *Repository) SearchView {
@@ -245,11 +263,89 @@ func (v *SearchView) Open(ctx InputContext) {
 	v.filter()
 }
 
+// OpenMove opens the search as a picker of projects and sections to move
+// tasks to.
+func (v *SearchView) OpenMove(tasks []Task) {
+	v.active = true
+	v.cursor = 0
+	v.moveTasks = tasks
+	v.input.Reset()
+	v.input.Placeholder = "Move to project or section..."
+	v.input.Focus()
+
+	v.destinations = nil
+	sections := make(map[string][]Section)
+	for _, sec := range v.repo.GetAllCachedSections() {
+		sections[sec.ProjectID] = append(sections[sec.ProjectID], sec)
+	}
+	for _, p := range sortProjects(v.repo.GetCachedProjects()) {
+		p := p
+		v.destinations = append(v.destinations, searchResult{kind: searchResultDestination, project: &p, path: p.Name})
+		for _, sec := range sections[p.ID] {
+			sec := sec
+			v.destinations = append(v.destinations, searchResult{
+				kind:    searchResultDestination,
+				project: &p,
+				section: &sec,
+				path:    p.Name + " / " + sec.Name,
+			})
+		}
+	}
+
+	v.filter()
+}
+
 func (v *SearchView) Close() {
 	v.active = false
+	v.moveTasks = nil
+	v.input.Placeholder = "Search tasks and lists..."
 	v.input.Blur()
 }
 
+// isAt reports whether r is where task t already is.
+func isAt(t Task, r searchResult) bool {
+	if t.ParentID != nil || t.ProjectID != r.project.ID {
+		return false
+	}
+	if r.section == nil {
+		return t.SectionID == ""
+	}
+	return t.SectionID == r.section.ID
+}
+
+// isCurrentLocation reports whether r is where every task being moved already is.
+func (v SearchView) isCurrentLocation(r searchResult) bool {
+	if len(v.moveTasks) == 0 {
+		return false
+	}
+	for _, t := range v.moveTasks {
+		if !isAt(t, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// moveTo queues the move of the picked tasks to destination r, as one bulk
+// edit when several tasks are moved.
+func (v SearchView) moveTo(r searchResult) tea.Cmd {
+	var ids []string
+	for _, t := range v.moveTasks {
+		if !isAt(t, r) {
+			ids = append(ids, t.ID)
+		}
+	}
+	req := moveTaskRequest{Destination: r.path}
+	if r.section != nil {
+		req.SectionID = &r.section.ID
+	} else {
+		req.ProjectID = &r.project.ID
+	}
+	return bulkOrSingle(v.repo, "Moved", ids, func(id string) tea.Cmd {
+		return v.repo.MoveTask(id, req)
+	})
+}
+
 func (v *SearchView) IsActive() bool {
 	return v.active
 }
@@ -259,6 +355,15 @@ func (v *SearchView) filter() {
 	v.results = nil
 	v.cursor = 0
 
+	if len(v.moveTasks) > 0 {
+		for _, d := range v.destinations {
+			if containsAllWords(d.path, query) {
+				v.results = append(v.results, d)
+			}
+		}
+		return
+	}
+
 	// Actions (always visible; filtered when query is provided).
 	actionCount := 0
 	for _, a := range v.actions {
@@ -327,12 +432,20 @@ func (v SearchView) Update(msg tea.Msg) (SearchView, tea.Cmd) {
 		case ActionSearchCreate:
 			// Create a new task via quick-add with the search text
 			text := strings.TrimSpace(v.input.Value())
-			if text == "" {
+			if text == "" || len(v.moveTasks) > 0 {
 				return v, nil
 			}
 			v.Close()
 			return v, v.repo.QuickAdd(text, "")
 		case ActionConfirm:
+			if len(v.moveTasks) > 0 {
+				if len(v.results) == 0 {
+					return v, nil
+				}
+				cmd := v.moveTo(v.results[v.cursor])
+				v.Close()
+				return v, cmd
+			}
 			// If no results, create a new task via quick-add
 			if len(v.results) == 0 {
 				text := strings.TrimSpace(v.input.Value())
@@ -394,10 +507,17 @@ func (v SearchView) View(width, height int) string {
 	var b strings.Builder
 
 	// Title
+	titleText := "Search"
+	switch n := len(v.moveTasks); {
+	case n == 1:
+		titleText = "Move " + truncate(fmt.Sprintf("%q", v.moveTasks[0].Content), width-12)
+	case n > 1:
+		titleText = fmt.Sprintf("Move %d tasks", n)
+	}
 	title := lipgloss.NewStyle().
 		Foreground(colorBlue).
 		Bold(true).
-		Render("Search")
+		Render(titleText)
 	b.WriteString(title)
 	b.WriteString("\n\n")
 
@@ -411,7 +531,7 @@ func (v SearchView) View(width, height int) string {
 	if len(v.results) == 0 {
 		b.WriteString("\n")
 		msg := "No matches"
-		if query == "" {
+		if query == "" && len(v.moveTasks) == 0 {
 			msg = "Type to search tasks/lists/actions"
 		}
 		b.WriteString(lipgloss.NewStyle().
@@ -456,6 +576,8 @@ func (v SearchView) View(width, height int) string {
 					b.WriteString("  " + sectionStyle.Render("Tasks"))
 				case searchResultProject:
 					b.WriteString("  " + sectionStyle.Render("Lists"))
+				case searchResultDestination:
+					b.WriteString("  " + sectionStyle.Render("Destinations"))
 				}
 				b.WriteString("\n")
 				prevKind = r.kind
@@ -562,6 +684,39 @@ func (v SearchView) View(width, height int) string {
 					name = highlightMatch(name, query, taskContentStyle, searchMatchStyle)
 					line = "  " + dot + "  " + name
 				}
+
+			case searchResultDestination:
+				// Sections indent under their project; highlight the first query word.
+				word := ""
+				if words := strings.Fields(query); len(words) > 0 {
+					word = words[0]
+				}
+				name := r.project.Name
+				marker := "●"
+				if r.section != nil {
+					name = r.section.Name
+					marker = "  ▪"
+					if query != "" {
+						name = r.path
+					}
+				}
+				name = truncate(name, width-20)
+				current := ""
+				if v.isCurrentLocation(r) {
+					current = "  (current)"
+				}
+				if selected {
+					line = lipgloss.NewStyle().
+						Background(colorBgHL).
+						Foreground(colorBright).
+						Bold(true).
+						Width(width - 4).
+						Render("  " + marker + " " + highlightMatchPlain(name, word) + current)
+				} else {
+					dot := lipgloss.NewStyle().Foreground(projectColor(r.project.Color)).Render(marker)
+					line = "  " + dot + " " + highlightMatch(name, word, taskContentStyle, searchMatchStyle) +
+						subtaskCountStyle.Render(current)
+				}
 			}
 
 			b.WriteString(line)
@@ -577,7 +732,11 @@ func (v SearchView) View(width, height int) string {
 	// Footer
 	b.WriteString("\n\n")
 	b.WriteString(footerKeyStyle.Render("↑/↓") + " " + footerDescStyle.Render("navigate") + "  ")
-	if len(v.results) > 0 {
+	if len(v.moveTasks) > 0 {
+		if len(v.results) > 0 {
+			b.WriteString(footerKeyStyle.Render("enter") + " " + footerDescStyle.Render("move here") + "  ")
+		}
+	} else if len(v.results) > 0 {
 		b.WriteString(footerKeyStyle.Render("enter") + " " + footerDescStyle.Render("run/open") + "  ")
 		b.WriteString(footerKeyStyle.Render("alt+enter") + " " + footerDescStyle.Render("new task") + "  ")
 	} else if query != "" {
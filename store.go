@@ -1,22 +1,26 @@
 package main
 
 import (
+	"cmp"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	_ "modernc.org/sqlite"
 )
 
 // Store is a SQLite-backed cache for Todoist data.
 type Store struct {
-	db  *sql.DB
-	ttl time.Duration
+	db *sql.DB
 }
 
 // NewStore opens (or creates) a SQLite database at dbPath and runs migrations.
-func NewStore(dbPath string, ttl time.Duration) (*Store, error) {
+func NewStore(dbPath string) (*Store, error) {
 	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(wal)&_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("open db: %w", err)
@@ -27,7 +31,12 @@ func NewStore(dbPath string, ttl time.Duration) (*Store, error) {
 		return nil, fmt.Errorf("migrate: %w", err)
 	}
 
-	return &Store{db: db, ttl: ttl}, nil
+	s := &Store{db: db}
+	if err := s.reclaimStaleMutations(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("reclaim queue: %w", err)
+	}
+	return s, nil
 }
 
 // Close closes the underlying database connection.
@@ -35,110 +44,304 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
-func migrate(db *sql.DB) error {
-	ddl := `
-CREATE TABLE IF NOT EXISTS sync_meta (
-	resource_type TEXT,
-	scope_id      TEXT DEFAULT '',
-	last_synced   INTEGER,
-	PRIMARY KEY (resource_type, scope_id)
-);
-CREATE TABLE IF NOT EXISTS projects (
-	id   TEXT PRIMARY KEY,
-	data TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS tasks (
-	id         TEXT PRIMARY KEY,
-	project_id TEXT NOT NULL,
-	data       TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS sections (
-	id         TEXT PRIMARY KEY,
-	project_id TEXT NOT NULL,
-	data       TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS labels (
-	id   TEXT PRIMARY KEY,
-	data TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS user_names (
-	user_id    TEXT PRIMARY KEY,
-	full_name  TEXT NOT NULL,
-	updated_at INTEGER NOT NULL
-);
-CREATE TABLE IF NOT EXISTS mutation_queue (
-	id          INTEGER PRIMARY KEY AUTOINCREMENT,
-	entity_type TEXT NOT NULL,
-	entity_id   TEXT NOT NULL,
-	action      TEXT NOT NULL,
-	payload     TEXT NOT NULL DEFAULT '',
-	snapshot    TEXT NOT NULL DEFAULT '',
-	status      TEXT NOT NULL DEFAULT 'pending',
-	conflict    TEXT NOT NULL DEFAULT '',
-	created_at  INTEGER NOT NULL,
-	attempts    INTEGER NOT NULL DEFAULT 0
-);
-CREATE TABLE IF NOT EXISTS completed_tasks (
-	id            TEXT PRIMARY KEY,
-	project_id    TEXT NOT NULL,
-	project_name  TEXT NOT NULL DEFAULT '',
-	data          TEXT NOT NULL,
-	completed_at  INTEGER NOT NULL
-);
-CREATE TABLE IF NOT EXISTS archived_projects (
-	id          TEXT PRIMARY KEY,
-	data        TEXT NOT NULL,
-	archived_at INTEGER NOT NULL
-);
-CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);
-CREATE INDEX IF NOT EXISTS idx_sections_project ON sections(project_id);
-CREATE INDEX IF NOT EXISTS idx_completed_project ON completed_tasks(project_id);
-`
-	_, err := db.Exec(ddl)
-	return err
-}
-
-// --- Staleness ---
+// --- Sync state ---
 
-// IsStale returns true if the given resource/scope has not been synced within the TTL.
-func (s *Store) IsStale(resourceType, scopeID string) bool {
-	var ts int64
-	err := s.db.QueryRow(
-		"SELECT last_synced FROM sync_meta WHERE resource_type = ? AND scope_id = ?",
-		resourceType, scopeID,
-	).Scan(&ts)
-	if err != nil {
-		return true
+// GetSyncToken returns the persisted Sync API token, or "*" when none is stored.
+// A token issued for a different set of resource types would never deliver the
+// existing rows of a newly added type, so that case falls back to a full sync.
+func (s *Store) GetSyncToken() string {
+	var token, types string
+	err := s.db.QueryRow("SELECT value FROM sync_state WHERE key = 'sync_token'").Scan(&token)
+	if err != nil || token == "" {
+		return fullSyncToken
+	}
+	_ = s.db.QueryRow("SELECT value FROM sync_state WHERE key = 'resource_types'").Scan(&types)
+	if types != strings.Join(syncResourceTypes, ",") {
+		return fullSyncToken
 	}
-	return time.Since(time.Unix(ts, 0)) > s.ttl
+	return token
+}
+
+// CurrentUserID returns the signed-in user's ID, or "" if not yet known.
+func (s *Store) CurrentUserID() string {
+	var id string
+	_ = s.db.QueryRow("SELECT value FROM sync_state WHERE key = 'user_id'").Scan(&id)
+	return id
 }
 
-// TouchSync records that the given resource/scope was just synced.
-func (s *Store) TouchSync(resourceType, scopeID string) {
-	_, _ = s.db.Exec(
-		"INSERT INTO sync_meta (resource_type, scope_id, last_synced) VALUES (?, ?, ?) "+
-			"ON CONFLICT(resource_type, scope_id) DO UPDATE SET last_synced = excluded.last_synced",
-		resourceType, scopeID, time.Now().Unix(),
+// SetCurrentUserID records the signed-in user's ID.
+func (s *Store) SetCurrentUserID(id string) error {
+	_, err := s.db.Exec(
+		"INSERT INTO sync_state (key, value) VALUES ('user_id', ?) "+
+			"ON CONFLICT(key) DO UPDATE SET value = excluded.value",
+		id,
 	)
+	return err
 }
 
-// LastSynced returns the last sync time for a resource/scope, if present.
-func (s *Store) LastSynced(resourceType, scopeID string) (*time.Time, error) {
-	var ts int64
-	err := s.db.QueryRow(
-		"SELECT last_synced FROM sync_meta WHERE resource_type = ? AND scope_id = ?",
-		resourceType, scopeID,
-	).Scan(&ts)
+// LastSynced returns the time of the last successful sync, if present.
+func (s *Store) LastSynced() (*time.Time, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM sync_state WHERE key = 'last_synced'").Scan(&value)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	ts, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
 	t := time.Unix(ts, 0)
 	return &t, nil
 }
 
+func setSyncState(tx *sql.Tx, key, value string) error {
+	_, err := tx.Exec(
+		"INSERT INTO sync_state (key, value) VALUES (?, ?) "+
+			"ON CONFLICT(key) DO UPDATE SET value = excluded.value",
+		key, value,
+	)
+	return err
+}
+
+// MergeSyncResponse applies a Sync API response to the cache in a single transaction.
+// Entities with queued local mutations keep their optimistic state until flushed.
+func (s *Store) MergeSyncResponse(resp *SyncResponse) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	local, err := mutatedEntityIDs(tx)
+	if err != nil {
+		return err
+	}
+
+	if resp.FullSync {
+		// Full syncs only list live resources, so anything not mentioned is gone.
+		for _, stmt := range []string{
+			"DELETE FROM projects WHERE id NOT IN (" + mutatedEntityIDsQuery + ")",
+			"DELETE FROM sections WHERE id NOT LIKE 'pending-%' AND id NOT IN (" + mutatedEntityIDsQuery + ")",
+			"DELETE FROM labels WHERE id NOT LIKE 'pending-%' AND id NOT IN (" + mutatedEntityIDsQuery + ")",
+			"DELETE FROM filters WHERE id NOT LIKE 'pending-%' AND id NOT IN (" + mutatedEntityIDsQuery + ")",
+			"DELETE FROM tasks WHERE id NOT LIKE 'pending-%' AND id NOT IN (" + mutatedEntityIDsQuery + ")",
+			"DELETE FROM comments WHERE id NOT LIKE 'pending-%' AND id NOT IN (" + mutatedEntityIDsQuery + ")",
+		} {
+			if _, err := tx.Exec(stmt, mutatedEntityIDsArgs...); err != nil {
+				return err
+			}
+		}
+	}
+
+	projectNames := make(map[string]string)
+	for _, p := range resp.Projects {
+		projectNames[p.ID] = p.Name
+		if local[p.ID] {
+			continue
+		}
+		blob, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM projects WHERE id = ?", p.ID); err != nil {
+			return err
+		}
+		switch {
+		case p.IsDeleted:
+			_, err = tx.Exec("DELETE FROM archived_projects WHERE id = ?", p.ID)
+		case p.IsArchived:
+			_, err = tx.Exec(
+				`INSERT INTO archived_projects (id, data, archived_at) VALUES (?, ?, ?)
+				 ON CONFLICT(id) DO UPDATE SET data = excluded.data`,
+				p.ID, string(blob), time.Now().Unix(),
+			)
+		default:
+			if _, err := tx.Exec("DELETE FROM archived_projects WHERE id = ?", p.ID); err != nil {
+				return err
+			}
+			_, err = tx.Exec("INSERT INTO projects (id, data) VALUES (?, ?)", p.ID, string(blob))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, sec := range resp.Sections {
+		if local[sec.ID] {
+			continue
+		}
+		if sec.IsDeleted || sec.IsArchived {
+			if _, err := tx.Exec("DELETE FROM sections WHERE id = ?", sec.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		blob, err := json.Marshal(sec)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO sections (id, project_id, data) VALUES (?, ?, ?) "+
+				"ON CONFLICT(id) DO UPDATE SET project_id = excluded.project_id, data = excluded.data",
+			sec.ID, sec.ProjectID, string(blob),
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, sl := range resp.Labels {
+		l := sl.label()
+		if local[l.ID] {
+			continue
+		}
+		if l.IsDeleted {
+			if _, err := tx.Exec("DELETE FROM labels WHERE id = ?", l.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		blob, err := json.Marshal(l)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO labels (id, data) VALUES (?, ?) ON CONFLICT(id) DO UPDATE SET data = excluded.data",
+			l.ID, string(blob),
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range resp.Filters {
+		if local[f.ID] {
+			continue
+		}
+		if f.IsDeleted {
+			if _, err := tx.Exec("DELETE FROM filters WHERE id = ?", f.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		blob, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO filters (id, data) VALUES (?, ?) ON CONFLICT(id) DO UPDATE SET data = excluded.data",
+			f.ID, string(blob),
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range resp.Items {
+		if local[t.ID] {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM tasks WHERE id = ?", t.ID); err != nil {
+			return err
+		}
+		if t.IsDeleted {
+			continue
+		}
+		blob, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		if t.Checked {
+			name, ok := projectNames[t.ProjectID]
+			if !ok {
+				_ = tx.QueryRow("SELECT json_extract(data, '$.name') FROM projects WHERE id = ?", t.ProjectID).Scan(&name)
+			}
+			_, err = tx.Exec(
+				`INSERT INTO completed_tasks (id, project_id, project_name, data, completed_at)
+				 VALUES (?, ?, ?, ?, ?)
+				 ON CONFLICT(id) DO UPDATE SET data = excluded.data`,
+				t.ID, t.ProjectID, name, string(blob), time.Now().Unix(),
+			)
+		} else {
+			if _, err := tx.Exec("DELETE FROM completed_tasks WHERE id = ?", t.ID); err != nil {
+				return err
+			}
+			_, err = tx.Exec(
+				"INSERT INTO tasks (id, project_id, data) VALUES (?, ?, ?)",
+				t.ID, t.ProjectID, string(blob),
+			)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, n := range resp.Notes {
+		c := n.comment()
+		if local[c.ID] {
+			continue
+		}
+		if c.IsDeleted || c.TaskID == nil {
+			if _, err := tx.Exec("DELETE FROM comments WHERE id = ?", c.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		blob, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO comments (id, task_id, data) VALUES (?, ?, ?) "+
+				"ON CONFLICT(id) DO UPDATE SET task_id = excluded.task_id, data = excluded.data",
+			c.ID, *c.TaskID, string(blob),
+		); err != nil {
+			return err
+		}
+	}
+
+	if resp.SyncToken != "" {
+		if err := setSyncState(tx, "sync_token", resp.SyncToken); err != nil {
+			return err
+		}
+		if err := setSyncState(tx, "resource_types", strings.Join(syncResourceTypes, ",")); err != nil {
+			return err
+		}
+	}
+	if err := setSyncState(tx, "last_synced", strconv.FormatInt(time.Now().Unix(), 10)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// mutatedEntityIDsQuery selects the IDs of entities that still have queued
+// mutations. A reorder renumbers every sibling in its payload, so those count
+// too. It takes mutatedEntityIDsArgs.
+const mutatedEntityIDsQuery = `SELECT entity_id FROM mutation_queue
+	UNION SELECT j.key FROM mutation_queue m, json_each(m.payload) j
+	WHERE m.action IN (?, ?, ?, ?) AND json_valid(m.payload)`
+
+var mutatedEntityIDsArgs = []any{MutationReorder, MutationReorderProjects, MutationReorderSections, MutationReorderFilters}
+
+// mutatedEntityIDs returns the IDs of entities that still have queued mutations.
+func mutatedEntityIDs(tx *sql.Tx) (map[string]bool, error) {
+	rows, err := tx.Query(mutatedEntityIDsQuery, mutatedEntityIDsArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
 // --- Projects ---
 
 // GetProjects returns all cached projects.
@@ -164,93 +367,250 @@ func (s *Store) GetProjects() ([]Project, error) {
 	return projects, rows.Err()
 }
 
-// ReplaceProjects replaces all cached projects in a transaction.
-func (s *Store) ReplaceProjects(projects []Project) error {
-	tx, err := s.db.Begin()
+// --- Labels ---
+
+// GetLabels returns all cached personal labels in list order.
+func (s *Store) GetLabels() ([]Label, error) {
+	rows, err := s.db.Query(
+		"SELECT data FROM labels ORDER BY json_extract(data, '$.order'), json_extract(data, '$.name') COLLATE NOCASE",
+	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer tx.Rollback()
+	defer rows.Close()
 
-	if _, err := tx.Exec("DELETE FROM projects"); err != nil {
-		return err
+	var labels []Label
+	for rows.Next() {
+		var blob string
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
+		}
+		var l Label
+		if err := json.Unmarshal([]byte(blob), &l); err != nil {
+			return nil, err
+		}
+		labels = append(labels, l)
+	}
+	return labels, rows.Err()
+}
+
+// GetLabelByID returns a single cached label, or nil if absent.
+func (s *Store) GetLabelByID(id string) (*Label, error) {
+	var blob string
+	err := s.db.QueryRow("SELECT data FROM labels WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
+	if err != nil {
+		return nil, err
+	}
+	var l Label
+	if err := json.Unmarshal([]byte(blob), &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
 
-	stmt, err := tx.Prepare("INSERT INTO projects (id, data) VALUES (?, ?)")
+// UpsertLabel inserts or replaces a cached label.
+func (s *Store) UpsertLabel(l Label) error {
+	blob, err := json.Marshal(l)
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
+	_, err = s.db.Exec(
+		"INSERT INTO labels (id, data) VALUES (?, ?) ON CONFLICT(id) DO UPDATE SET data = excluded.data",
+		l.ID, string(blob),
+	)
+	return err
+}
 
-	for _, p := range projects {
-		blob, err := json.Marshal(p)
+// DeleteLabel removes a label from the cache.
+func (s *Store) DeleteLabel(id string) error {
+	_, err := s.db.Exec("DELETE FROM labels WHERE id = ?", id)
+	return err
+}
+
+// ReplaceTaskLabel renames oldName to newName on every cached task carrying
+// it, or strips it when newName is empty. It returns the affected task IDs.
+func (s *Store) ReplaceTaskLabel(oldName, newName string) ([]string, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		"SELECT data FROM tasks WHERE EXISTS (SELECT 1 FROM json_each(data, '$.labels') WHERE value = ?)",
+		oldName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []Task
+	for rows.Next() {
+		var blob string
+		if err := rows.Scan(&blob); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		var t Task
+		if err := json.Unmarshal([]byte(blob), &t); err != nil {
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		labels := make([]string, 0, len(t.Labels))
+		for _, l := range t.Labels {
+			switch {
+			case l != oldName:
+				labels = append(labels, l)
+			case newName != "" && !slices.Contains(t.Labels, newName):
+				labels = append(labels, newName)
+			}
+		}
+		t.Labels = labels
+		blob, err := json.Marshal(t)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if _, err := stmt.Exec(p.ID, string(blob)); err != nil {
+		if _, err := tx.Exec("UPDATE tasks SET data = ? WHERE id = ?", string(blob), t.ID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, t.ID)
+	}
+	return ids, tx.Commit()
+}
+
+// AddTaskLabel puts name back on the given cached tasks.
+func (s *Store) AddTaskLabel(name string, taskIDs []string) error {
+	for _, id := range taskIDs {
+		t, err := s.GetTaskByID(id)
+		if err != nil || t == nil || slices.Contains(t.Labels, name) {
+			continue
+		}
+		t.Labels = append(t.Labels, name)
+		if err := s.UpsertTask(*t); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	s.TouchSync("projects", "")
-	return tx.Commit()
+// LabelTaskCounts returns the number of open cached tasks per label name.
+func (s *Store) LabelTaskCounts() (map[string]int, error) {
+	rows, err := s.db.Query(
+		"SELECT l.value, COUNT(*) FROM tasks, json_each(tasks.data, '$.labels') AS l GROUP BY l.value",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var n int
+		if err := rows.Scan(&name, &n); err != nil {
+			return nil, err
+		}
+		out[name] = n
+	}
+	return out, rows.Err()
 }
 
-// --- Tasks ---
+// --- Filters ---
 
-// GetTasks returns cached tasks for a project.
-func (s *Store) GetTasks(projectID string) ([]Task, error) {
-	rows, err := s.db.Query("SELECT data FROM tasks WHERE project_id = ?", projectID)
+// GetFilters returns all cached saved filters in list order.
+func (s *Store) GetFilters() ([]SavedFilter, error) {
+	rows, err := s.db.Query(
+		"SELECT data FROM filters ORDER BY json_extract(data, '$.item_order'), json_extract(data, '$.name') COLLATE NOCASE",
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tasks []Task
+	var filters []SavedFilter
 	for rows.Next() {
 		var blob string
 		if err := rows.Scan(&blob); err != nil {
 			return nil, err
 		}
-		var t Task
-		if err := json.Unmarshal([]byte(blob), &t); err != nil {
+		var f SavedFilter
+		if err := json.Unmarshal([]byte(blob), &f); err != nil {
 			return nil, err
 		}
-		tasks = append(tasks, t)
+		filters = append(filters, f)
 	}
-	return tasks, rows.Err()
+	return filters, rows.Err()
 }
 
-// ReplaceTasks replaces all cached tasks for a project in a transaction.
-func (s *Store) ReplaceTasks(projectID string, tasks []Task) error {
-	tx, err := s.db.Begin()
+// GetFilterByID returns a single cached filter, or nil if absent.
+func (s *Store) GetFilterByID(id string) (*SavedFilter, error) {
+	var blob string
+	err := s.db.QueryRow("SELECT data FROM filters WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer tx.Rollback()
+	var f SavedFilter
+	if err := json.Unmarshal([]byte(blob), &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
 
-	if _, err := tx.Exec("DELETE FROM tasks WHERE project_id = ?", projectID); err != nil {
+// UpsertFilter inserts or replaces a cached filter.
+func (s *Store) UpsertFilter(f SavedFilter) error {
+	blob, err := json.Marshal(f)
+	if err != nil {
 		return err
 	}
+	_, err = s.db.Exec(
+		"INSERT INTO filters (id, data) VALUES (?, ?) ON CONFLICT(id) DO UPDATE SET data = excluded.data",
+		f.ID, string(blob),
+	)
+	return err
+}
+
+// DeleteFilter removes a filter from the cache.
+func (s *Store) DeleteFilter(id string) error {
+	_, err := s.db.Exec("DELETE FROM filters WHERE id = ?", id)
+	return err
+}
+
+// --- Tasks ---
 
-	stmt, err := tx.Prepare("INSERT INTO tasks (id, project_id, data) VALUES (?, ?, ?)")
+// GetTasks returns cached tasks for a project.
+func (s *Store) GetTasks(projectID string) ([]Task, error) {
+	rows, err := s.db.Query("SELECT data FROM tasks WHERE project_id = ?", projectID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer stmt.Close()
+	defer rows.Close()
 
-	for _, t := range tasks {
-		blob, err := json.Marshal(t)
-		if err != nil {
-			return err
+	var tasks []Task
+	for rows.Next() {
+		var blob string
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
 		}
-		if _, err := stmt.Exec(t.ID, projectID, string(blob)); err != nil {
-			return err
+		var t Task
+		if err := json.Unmarshal([]byte(blob), &t); err != nil {
+			return nil, err
 		}
+		tasks = append(tasks, t)
 	}
-
-	s.TouchSync("tasks", projectID)
-	return tx.Commit()
+	return tasks, rows.Err()
 }
 
 // UpsertTask inserts or updates a single task in the cache.
@@ -298,9 +658,58 @@ func (s *Store) GetAllTasks() ([]Task, error) {
 
 // --- Sections ---
 
-// GetSections returns cached sections for a project.
+// GetSections returns cached sections for a project, in section order.
 func (s *Store) GetSections(projectID string) ([]Section, error) {
-	rows, err := s.db.Query("SELECT data FROM sections WHERE project_id = ?", projectID)
+	return s.querySections(
+		"SELECT data FROM sections WHERE project_id = ? ORDER BY json_extract(data, '$.section_order')",
+		projectID,
+	)
+}
+
+// GetAllSections returns cached sections across all projects.
+func (s *Store) GetAllSections() ([]Section, error) {
+	return s.querySections("SELECT data FROM sections ORDER BY project_id, json_extract(data, '$.section_order')")
+}
+
+// GetSectionByID returns a single cached section, or nil if absent.
+func (s *Store) GetSectionByID(id string) (*Section, error) {
+	var blob string
+	err := s.db.QueryRow("SELECT data FROM sections WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sec Section
+	if err := json.Unmarshal([]byte(blob), &sec); err != nil {
+		return nil, err
+	}
+	return &sec, nil
+}
+
+// UpsertSection inserts or replaces a cached section.
+func (s *Store) UpsertSection(sec Section) error {
+	blob, err := json.Marshal(sec)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(
+		"INSERT INTO sections (id, project_id, data) VALUES (?, ?, ?) "+
+			"ON CONFLICT(id) DO UPDATE SET project_id = excluded.project_id, data = excluded.data",
+		sec.ID, sec.ProjectID, string(blob),
+	)
+	return err
+}
+
+// DeleteSection removes a section from the cache.
+func (s *Store) DeleteSection(id string) error {
+	_, err := s.db.Exec("DELETE FROM sections WHERE id = ?", id)
+	return err
+}
+
+func (s *Store) querySections(query string, args ...any) ([]Section, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -321,38 +730,6 @@ func (s *Store) GetSections(projectID string) ([]Section, error) {
 	return sections, rows.Err()
 }
 
-// ReplaceSections replaces all cached sections for a project in a transaction.
-func (s *Store) ReplaceSections(projectID string, sections []Section) error {
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	if _, err := tx.Exec("DELETE FROM sections WHERE project_id = ?", projectID); err != nil {
-		return err
-	}
-
-	stmt, err := tx.Prepare("INSERT INTO sections (id, project_id, data) VALUES (?, ?, ?)")
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	for _, sec := range sections {
-		blob, err := json.Marshal(sec)
-		if err != nil {
-			return err
-		}
-		if _, err := stmt.Exec(sec.ID, projectID, string(blob)); err != nil {
-			return err
-		}
-	}
-
-	s.TouchSync("sections", projectID)
-	return tx.Commit()
-}
-
 // --- Single task lookup ---
 
 // GetTaskByID returns a single cached task by ID.
@@ -371,13 +748,19 @@ func (s *Store) GetTaskByID(taskID string) (*Task, error) {
 
 // --- Mutation queue ---
 
+const mutationColumns = "id, entity_type, entity_id, action, payload, snapshot, status, conflict, created_at, attempts, uuid, depends_on, group_id, remote"
+
 // EnqueueMutation inserts a mutation into the queue and returns its ID.
+// Each mutation gets a stable command UUID so retries are idempotent server-side.
 func (s *Store) EnqueueMutation(m Mutation) (int64, error) {
+	if m.UUID == "" {
+		m.UUID = uuid.New().String()
+	}
 	res, err := s.db.Exec(
-		`INSERT INTO mutation_queue (entity_type, entity_id, action, payload, snapshot, status, conflict, created_at, attempts)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO mutation_queue (entity_type, entity_id, action, payload, snapshot, status, conflict, created_at, attempts, uuid, depends_on, group_id, remote)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		m.EntityType, m.EntityID, string(m.Action), m.Payload, m.Snapshot,
-		string(m.Status), m.Conflict, m.CreatedAt.Unix(), m.Attempts,
+		string(m.Status), m.Conflict, m.CreatedAt.Unix(), m.Attempts, m.UUID, m.DependsOn, m.GroupID, m.Remote,
 	)
 	if err != nil {
 		return 0, err
@@ -385,13 +768,156 @@ func (s *Store) EnqueueMutation(m Mutation) (int64, error) {
 	return res.LastInsertId()
 }
 
-// NextPendingMutation returns the oldest mutation with status=pending.
-func (s *Store) NextPendingMutation() (*Mutation, error) {
-	row := s.db.QueryRow(
-		`SELECT id, entity_type, entity_id, action, payload, snapshot, status, conflict, created_at, attempts
-		 FROM mutation_queue WHERE status = 'pending' ORDER BY id ASC LIMIT 1`,
+// LastMutationID returns the ID of the most recently queued mutation, or 0.
+func (s *Store) LastMutationID() int64 {
+	var id int64
+	_ = s.db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM mutation_queue").Scan(&id)
+	return id
+}
+
+// GroupMutationsAfter tags every mutation queued after afterID with groupID,
+// so a bulk edit is shown and handled as a single unit.
+func (s *Store) GroupMutationsAfter(afterID int64, groupID string) error {
+	_, err := s.db.Exec("UPDATE mutation_queue SET group_id = ? WHERE id > ?", groupID, afterID)
+	return err
+}
+
+// GroupMutations ties the given mutations into one bulk unit.
+func (s *Store) GroupMutations(ids []int64, groupID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, id := range ids {
+		if _, err := tx.Exec("UPDATE mutation_queue SET group_id = ? WHERE id = ?", groupID, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetGroupMutations returns the mutations belonging to a bulk edit.
+func (s *Store) GetGroupMutations(groupID string) ([]Mutation, error) {
+	return s.queryMutations(
+		"SELECT "+mutationColumns+" FROM mutation_queue WHERE group_id = ? ORDER BY id ASC",
+		groupID,
+	)
+}
+
+// ClaimMutations marks up to limit pending mutations flushing and returns
+// them, oldest first. The claim is a single UPDATE, so two processes sharing
+// the cache never flush the same mutation.
+func (s *Store) ClaimMutations(limit int) ([]Mutation, error) {
+	muts, err := s.queryMutations(`
+UPDATE mutation_queue SET status = 'flushing', attempts = attempts + 1, claimed_at = ?
+WHERE id IN (SELECT id FROM mutation_queue WHERE status = 'pending' ORDER BY id ASC LIMIT ?)
+RETURNING `+mutationColumns,
+		time.Now().Unix(), limit,
+	)
+	slices.SortFunc(muts, func(a, b Mutation) int { return cmp.Compare(a.ID, b.ID) })
+	return muts, err
+}
+
+// staleClaimAge is how long a claimed mutation may stay flushing before it
+// is taken to be left over from a process that died mid-flush. It is well
+// past the Sync client's request timeout.
+const staleClaimAge = 5 * time.Minute
+
+// reclaimStaleMutations returns to pending the mutations an interrupted flush
+// left claimed. It runs only when the store opens: a live process's flush is
+// never that old, and any younger claim may still be in flight.
+func (s *Store) reclaimStaleMutations() error {
+	_, err := s.db.Exec(
+		"UPDATE mutation_queue SET status = 'pending' WHERE status = 'flushing' AND claimed_at < ?",
+		time.Now().Add(-staleClaimAge).Unix(),
+	)
+	return err
+}
+
+// CreateMutationID returns the queued create (task, quick add or comment)
+// mutation that introduced entityID, or 0 if it has already been flushed.
+func (s *Store) CreateMutationID(entityID string) int64 {
+	var id int64
+	_ = s.db.QueryRow(
+		"SELECT id FROM mutation_queue WHERE entity_id = ? AND action IN (?, ?, ?, ?, ?, ?) ORDER BY id ASC LIMIT 1",
+		entityID, string(MutationCreate), string(MutationQuickAdd), string(MutationAddComment),
+		string(MutationCreateLabel), string(MutationCreateFilter), string(MutationCreateSection),
+	).Scan(&id)
+	return id
+}
+
+// ReleaseDependents clears the dependency on a create mutation once it has flushed.
+func (s *Store) ReleaseDependents(createID int64) error {
+	_, err := s.db.Exec("UPDATE mutation_queue SET depends_on = 0 WHERE depends_on = ?", createID)
+	return err
+}
+
+// BlockDependents marks conflicted the pending mutations queued behind a
+// conflicted create, and those behind them in turn: none can flush until the
+// create does.
+func (s *Store) BlockDependents(conflict string) error {
+	_, err := s.db.Exec(`
+WITH RECURSIVE blocked(id) AS (
+	SELECT d.id FROM mutation_queue d JOIN mutation_queue c ON d.depends_on = c.id WHERE c.status = 'conflicted'
+	UNION SELECT m.id FROM mutation_queue m JOIN blocked b ON m.depends_on = b.id
+)
+UPDATE mutation_queue SET status = 'conflicted', conflict = ?
+WHERE status = 'pending' AND id IN (SELECT id FROM blocked)`, conflict)
+	return err
+}
+
+// UnblockDependents returns to pending the mutations behind createID that
+// BlockDependents marked with reason.
+func (s *Store) UnblockDependents(createID int64, reason string) error {
+	_, err := s.db.Exec(`
+WITH RECURSIVE blocked(id) AS (
+	SELECT id FROM mutation_queue WHERE depends_on = ?1
+	UNION SELECT m.id FROM mutation_queue m JOIN blocked b ON m.depends_on = b.id
+)
+UPDATE mutation_queue SET status = 'pending', conflict = ''
+WHERE status = 'conflicted' AND json_valid(conflict) AND json_extract(conflict, '$[0].reason') = ?2
+	AND id IN (SELECT id FROM blocked)`, createID, reason)
+	return err
+}
+
+// GetDependentMutations returns mutations queued against a not-yet-created entity.
+func (s *Store) GetDependentMutations(createID int64) ([]Mutation, error) {
+	return s.queryMutations(
+		"SELECT "+mutationColumns+" FROM mutation_queue WHERE depends_on = ? ORDER BY id ASC",
+		createID,
 	)
-	return scanMutation(row)
+}
+
+// RemapPendingID replaces a temporary ID with the server-assigned one across
+// cached tasks, completed tasks, comments, completion history and queued mutations.
+func (s *Store) RemapPendingID(tempID, realID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Pending IDs embed a UUID, so plain text replacement cannot hit unrelated data.
+	for _, stmt := range []string{
+		"DELETE FROM tasks WHERE id = ?2 AND EXISTS (SELECT 1 FROM tasks WHERE id = ?1)",
+		"DELETE FROM completed_tasks WHERE id = ?2 AND EXISTS (SELECT 1 FROM completed_tasks WHERE id = ?1)",
+		"UPDATE tasks SET id = replace(id, ?1, ?2), data = replace(data, ?1, ?2) WHERE instr(data, ?1) > 0",
+		"UPDATE completed_tasks SET id = replace(id, ?1, ?2), data = replace(data, ?1, ?2) WHERE instr(data, ?1) > 0",
+		"UPDATE comments SET id = replace(id, ?1, ?2), task_id = replace(task_id, ?1, ?2), data = replace(data, ?1, ?2) WHERE instr(data, ?1) > 0",
+		"UPDATE labels SET id = replace(id, ?1, ?2), data = replace(data, ?1, ?2) WHERE instr(data, ?1) > 0",
+		"UPDATE filters SET id = replace(id, ?1, ?2), data = replace(data, ?1, ?2) WHERE instr(data, ?1) > 0",
+		"UPDATE sections SET id = replace(id, ?1, ?2), data = replace(data, ?1, ?2) WHERE instr(data, ?1) > 0",
+		"UPDATE completion_history SET task_id = replace(task_id, ?1, ?2), data = replace(data, ?1, ?2) WHERE instr(task_id, ?1) > 0",
+		`UPDATE mutation_queue SET entity_id = replace(entity_id, ?1, ?2),
+			payload = replace(payload, ?1, ?2), snapshot = replace(snapshot, ?1, ?2)
+		 WHERE instr(entity_id || payload || snapshot, ?1) > 0`,
+	} {
+		if _, err := tx.Exec(stmt, tempID, realID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 // UpdateMutationStatus updates a mutation's status and conflict description.
@@ -403,9 +929,23 @@ func (s *Store) UpdateMutationStatus(id int64, status MutationStatus, conflict s
 	return err
 }
 
-// IncrementMutationAttempts increments the attempt counter for a mutation.
-func (s *Store) IncrementMutationAttempts(id int64) error {
-	_, err := s.db.Exec("UPDATE mutation_queue SET attempts = attempts + 1 WHERE id = ?", id)
+// MarkMutationConflicted flags a mutation conflicted, keeping the server copy
+// of the entity so the conflict can be resolved later.
+func (s *Store) MarkMutationConflicted(id int64, conflict, remote string) error {
+	_, err := s.db.Exec(
+		"UPDATE mutation_queue SET status = ?, conflict = ?, remote = ? WHERE id = ?",
+		string(MutationConflicted), conflict, remote, id,
+	)
+	return err
+}
+
+// RebaseMutation replaces a mutation's payload and snapshot, as when it is
+// merged onto a newer server copy of its entity.
+func (s *Store) RebaseMutation(id int64, payload, snapshot string) error {
+	_, err := s.db.Exec(
+		"UPDATE mutation_queue SET payload = ?, snapshot = ? WHERE id = ?",
+		payload, snapshot, id,
+	)
 	return err
 }
 
@@ -415,6 +955,24 @@ func (s *Store) DeleteMutation(id int64) error {
 	return err
 }
 
+// DiscardMutation removes a mutation that was never applied on the server.
+// Sync merges skipped its entity while it was queued, so the sync token is
+// reset too: the next sync is a full one and brings the server state back.
+func (s *Store) DiscardMutation(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM mutation_queue WHERE id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM sync_state WHERE key = 'sync_token'"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // PendingCount returns the number of pending mutations.
 func (s *Store) PendingCount() int {
 	var count int
@@ -438,16 +996,16 @@ func (s *Store) ConflictCount() int {
 
 // GetConflictedMutations returns all conflicted mutations.
 func (s *Store) GetConflictedMutations() ([]Mutation, error) {
-	return s.queryMutations("SELECT id, entity_type, entity_id, action, payload, snapshot, status, conflict, created_at, attempts FROM mutation_queue WHERE status = 'conflicted' ORDER BY id ASC")
+	return s.queryMutations("SELECT " + mutationColumns + " FROM mutation_queue WHERE status = 'conflicted' ORDER BY id ASC")
 }
 
 // GetAllMutations returns all mutations (for queue view).
 func (s *Store) GetAllMutations() ([]Mutation, error) {
-	return s.queryMutations("SELECT id, entity_type, entity_id, action, payload, snapshot, status, conflict, created_at, attempts FROM mutation_queue ORDER BY id ASC")
+	return s.queryMutations("SELECT " + mutationColumns + " FROM mutation_queue ORDER BY id ASC")
 }
 
-func (s *Store) queryMutations(query string) ([]Mutation, error) {
-	rows, err := s.db.Query(query)
+func (s *Store) queryMutations(query string, args ...any) ([]Mutation, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -455,16 +1013,11 @@ func (s *Store) queryMutations(query string) ([]Mutation, error) {
 
 	var mutations []Mutation
 	for rows.Next() {
-		var m Mutation
-		var action, status string
-		var createdAt int64
-		if err := rows.Scan(&m.ID, &m.EntityType, &m.EntityID, &action, &m.Payload, &m.Snapshot, &status, &m.Conflict, &createdAt, &m.Attempts); err != nil {
+		m, err := scanMutation(rows)
+		if err != nil {
 			return nil, err
 		}
-		m.Action = MutationAction(action)
-		m.Status = MutationStatus(status)
-		m.CreatedAt = time.Unix(createdAt, 0)
-		mutations = append(mutations, m)
+		mutations = append(mutations, *m)
 	}
 	return mutations, rows.Err()
 }
@@ -522,12 +1075,159 @@ func (s *Store) GetRecentlyCompleted(limit int) ([]CompletedTaskRow, error) {
 	return result, rows.Err()
 }
 
+// CompletedSubtaskCounts returns, per parent task ID, how many of its
+// subtasks in projectID are in the completed list.
+func (s *Store) CompletedSubtaskCounts(projectID string) (map[string]int, error) {
+	rows, err := s.db.Query(
+		`SELECT json_extract(data, '$.parent_id'), COUNT(*) FROM completed_tasks
+		 WHERE project_id = ? AND json_extract(data, '$.parent_id') IS NOT NULL
+		 GROUP BY 1`, projectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var parentID string
+		var n int
+		if err := rows.Scan(&parentID, &n); err != nil {
+			return nil, err
+		}
+		counts[parentID] = n
+	}
+	return counts, rows.Err()
+}
+
 // DeleteCompletedTask removes a task from the completed list.
 func (s *Store) DeleteCompletedTask(taskID string) error {
 	_, err := s.db.Exec("DELETE FROM completed_tasks WHERE id = ?", taskID)
 	return err
 }
 
+// --- Comments ---
+
+// GetComments returns the cached comments on a task, oldest first.
+func (s *Store) GetComments(taskID string) ([]Comment, error) {
+	rows, err := s.db.Query(
+		"SELECT data FROM comments WHERE task_id = ? ORDER BY json_extract(data, '$.posted_at'), rowid",
+		taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []Comment
+	for rows.Next() {
+		var blob string
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
+		}
+		var c Comment
+		if err := json.Unmarshal([]byte(blob), &c); err != nil {
+			continue
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// GetCommentByID returns a single cached comment, or nil if absent.
+func (s *Store) GetCommentByID(id string) (*Comment, error) {
+	var blob string
+	err := s.db.QueryRow("SELECT data FROM comments WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Comment
+	if err := json.Unmarshal([]byte(blob), &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// UpsertComment inserts or replaces a cached comment.
+func (s *Store) UpsertComment(c Comment) error {
+	if c.TaskID == nil {
+		return fmt.Errorf("comment %s has no task", c.ID)
+	}
+	blob, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(
+		"INSERT INTO comments (id, task_id, data) VALUES (?, ?, ?) "+
+			"ON CONFLICT(id) DO UPDATE SET task_id = excluded.task_id, data = excluded.data",
+		c.ID, *c.TaskID, string(blob),
+	)
+	return err
+}
+
+// DeleteComment removes a comment from the cache.
+func (s *Store) DeleteComment(id string) error {
+	_, err := s.db.Exec("DELETE FROM comments WHERE id = ?", id)
+	return err
+}
+
+// --- Completion history ---
+
+// RecordCompletion logs one completed occurrence of a recurring task.
+func (s *Store) RecordCompletion(task Task, completedAt time.Time) error {
+	blob, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	dueDate := ""
+	if task.Due != nil {
+		dueDate = task.Due.Date
+	}
+	_, err = s.db.Exec(
+		"INSERT INTO completion_history (task_id, due_date, data, completed_at) VALUES (?, ?, ?, ?)",
+		task.ID, dueDate, string(blob), completedAt.Unix(),
+	)
+	return err
+}
+
+// DeleteCompletion removes the most recent history entry for an occurrence.
+func (s *Store) DeleteCompletion(taskID, dueDate string) error {
+	_, err := s.db.Exec(
+		`DELETE FROM completion_history WHERE id = (
+			SELECT id FROM completion_history WHERE task_id = ? AND due_date = ? ORDER BY id DESC LIMIT 1
+		)`,
+		taskID, dueDate,
+	)
+	return err
+}
+
+// GetCompletionHistory returns the recorded occurrences of a task, newest first.
+func (s *Store) GetCompletionHistory(taskID string) ([]CompletionRecord, error) {
+	rows, err := s.db.Query(
+		"SELECT task_id, due_date, completed_at FROM completion_history WHERE task_id = ? ORDER BY completed_at DESC, id DESC",
+		taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []CompletionRecord
+	for rows.Next() {
+		var rec CompletionRecord
+		var completedAt int64
+		if err := rows.Scan(&rec.TaskID, &rec.DueDate, &completedAt); err != nil {
+			return nil, err
+		}
+		rec.CompletedAt = time.Unix(completedAt, 0)
+		result = append(result, rec)
+	}
+	return result, rows.Err()
+}
+
 // --- Archived projects ---
 
 // SaveArchivedProject stores an archived project.
@@ -579,11 +1279,71 @@ func (s *Store) DeleteProject(projectID string) error {
 	return err
 }
 
+// GetProjectByID returns a single cached project, or nil if absent.
+func (s *Store) GetProjectByID(id string) (*Project, error) {
+	var blob string
+	err := s.db.QueryRow("SELECT data FROM projects WHERE id = ?", id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p Project
+	if err := json.Unmarshal([]byte(blob), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpsertProject inserts or replaces a cached project.
+func (s *Store) UpsertProject(p Project) error {
+	blob, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(
+		"INSERT INTO projects (id, data) VALUES (?, ?) ON CONFLICT(id) DO UPDATE SET data = excluded.data",
+		p.ID, string(blob),
+	)
+	return err
+}
+
+// CollapsedProjects returns the IDs of projects collapsed in the sidebar.
+func (s *Store) CollapsedProjects() (map[string]bool, error) {
+	rows, err := s.db.Query("SELECT id FROM collapsed_projects")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+// SetProjectCollapsed records whether a project's subprojects are hidden.
+func (s *Store) SetProjectCollapsed(id string, collapsed bool) error {
+	var err error
+	if collapsed {
+		_, err = s.db.Exec("INSERT OR IGNORE INTO collapsed_projects (id) VALUES (?)", id)
+	} else {
+		_, err = s.db.Exec("DELETE FROM collapsed_projects WHERE id = ?", id)
+	}
+	return err
+}
+
 func scanMutation(row scannable) (*Mutation, error) {
 	var m Mutation
 	var action, status string
 	var createdAt int64
-	err := row.Scan(&m.ID, &m.EntityType, &m.EntityID, &action, &m.Payload, &m.Snapshot, &status, &m.Conflict, &createdAt, &m.Attempts)
+	err := row.Scan(&m.ID, &m.EntityType, &m.EntityID, &action, &m.Payload, &m.Snapshot, &status, &m.Conflict, &createdAt, &m.Attempts, &m.UUID, &m.DependsOn, &m.GroupID, &m.Remote)
 	if err != nil {
 		return nil, err
 	}
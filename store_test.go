@@ -0,0 +1,109 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := NewStore(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestMergeSyncResponseKeepsQueuedEntities(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.UpsertTask(Task{ID: "t1", Content: "local", ProjectID: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.EnqueueMutation(Mutation{EntityType: "task", EntityID: "t1", Action: MutationUpdate, Status: MutationPending, CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := &SyncResponse{SyncToken: "tok1", Items: []Task{{ID: "t1", Content: "server", ProjectID: "p1"}}}
+	if err := s.MergeSyncResponse(resp); err != nil {
+		t.Fatal(err)
+	}
+	if task, _ := s.GetTaskByID("t1"); task == nil || task.Content != "local" {
+		t.Fatalf("queued task overwritten by sync: %+v", task)
+	}
+	if got := s.GetSyncToken(); got != "tok1" {
+		t.Fatalf("sync token = %q, want tok1", got)
+	}
+
+	// Dropping the mutation unapplied must bring the skipped server state back.
+	if err := s.DiscardMutation(id); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetSyncToken(); got != fullSyncToken {
+		t.Fatalf("sync token after discard = %q, want a full sync", got)
+	}
+	resp = &SyncResponse{SyncToken: "tok2", FullSync: true, Items: []Task{{ID: "t1", Content: "server", ProjectID: "p1"}}}
+	if err := s.MergeSyncResponse(resp); err != nil {
+		t.Fatal(err)
+	}
+	if task, _ := s.GetTaskByID("t1"); task == nil || task.Content != "server" {
+		t.Fatalf("server state not restored: %+v", task)
+	}
+}
+
+func TestClaimMutations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := s.EnqueueMutation(Mutation{EntityType: "task", EntityID: "t1", Action: MutationClose, Status: MutationPending, CreatedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := s.ClaimMutations(2)
+	if err != nil || len(first) != 2 || first[0].ID > first[1].ID {
+		t.Fatalf("first claim = %+v (%v), want the two oldest", first, err)
+	}
+	second, _ := s.ClaimMutations(2)
+	if len(second) != 1 || second[0].ID == first[0].ID || second[0].ID == first[1].ID {
+		t.Fatalf("second claim = %+v, want only the unclaimed mutation", second)
+	}
+	for _, m := range append(first, second...) {
+		if m.Status != MutationFlushing || m.Attempts != 1 {
+			t.Errorf("claimed mutation %d is %s after %d attempts", m.ID, m.Status, m.Attempts)
+		}
+	}
+	if more, _ := s.ClaimMutations(2); len(more) != 0 {
+		t.Errorf("claimed %d mutations already in flight", len(more))
+	}
+
+	// Another process opening the cache leaves a live flush alone...
+	other, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := other.FlushingCount(); n != 3 {
+		t.Errorf("opening the cache reclaimed fresh claims: %d flushing, want 3", n)
+	}
+	other.Close()
+
+	// ...but takes back claims left by a process that died mid-flush.
+	stale := time.Now().Add(-2 * staleClaimAge).Unix()
+	if _, err := s.db.Exec("UPDATE mutation_queue SET claimed_at = ? WHERE id = ?", stale, first[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	s, err = NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if n := s.PendingCount(); n != 1 {
+		t.Errorf("pending after reopening = %d, want the one stale claim", n)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// fullSyncToken asks the Sync endpoint for every active resource.
+const fullSyncToken = "*"
+
+// syncResourceTypes are the resources the app keeps in its local cache.
+var syncResourceTypes = []string{"projects", "items", "sections", "labels", "notes", "filters"}
+
+// SyncClient talks to the Todoist Sync endpoint.
+type SyncClient struct {
+	token   string
+	http    *http.Client
+	limiter *rateLimiter
+}
+
+// NewSyncClient creates a new Sync API client.
+func NewSyncClient(token string) *SyncClient {
+	return &SyncClient{
+		token: token,
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		limiter: apiLimiter,
+	}
+}
+
+// SyncResponse is the subset of the Sync API response the app consumes.
+type SyncResponse struct {
+	SyncToken  string                     `json:"sync_token"`
+	FullSync   bool                       `json:"full_sync"`
+	Projects   []Project                  `json:"projects"`
+	Items      []Task                     `json:"items"`
+	Sections   []Section                  `json:"sections"`
+	Labels     []syncLabel                `json:"labels"`
+	Notes      []syncNote                 `json:"notes"`
+	Filters    []SavedFilter              `json:"filters"`
+	TempIDMap  map[string]string          `json:"temp_id_mapping"`
+	SyncStatus map[string]json.RawMessage `json:"sync_status"`
+}
+
+// syncLabel is a personal label as the Sync API reports it (ordered by item_order).
+type syncLabel struct {
+	Label
+	ItemOrder int `json:"item_order"`
+}
+
+func (l syncLabel) label() Label {
+	out := l.Label
+	out.Order = l.ItemOrder
+	return out
+}
+
+// syncNote is a task comment as the Sync API reports it (keyed by item_id).
+type syncNote struct {
+	Comment
+	ItemID string `json:"item_id"`
+}
+
+// comment converts a Sync note to the REST-shaped Comment the app caches.
+func (n syncNote) comment() Comment {
+	c := n.Comment
+	if c.TaskID == nil && n.ItemID != "" {
+		itemID := n.ItemID
+		c.TaskID = &itemID
+	}
+	return c
+}
+
+// SyncCommand is a single write command sent to the Sync endpoint.
+type SyncCommand struct {
+	Type   string         `json:"type"`
+	UUID   string         `json:"uuid"`
+	TempID string         `json:"temp_id,omitempty"`
+	Args   map[string]any `json:"args"`
+}
+
+// SyncCommandError is a per-command failure reported in sync_status.
+type SyncCommandError struct {
+	Tag        string         `json:"error_tag"`
+	Code       int            `json:"error_code"`
+	Message    string         `json:"error"`
+	HTTPCode   int            `json:"http_code"`
+	ErrorExtra map[string]any `json:"error_extra"`
+}
+
+func (e *SyncCommandError) Error() string {
+	if e.Message == "" {
+		return e.Tag
+	}
+	return e.Message
+}
+
+// Retriable reports whether the command may succeed if resent unchanged.
+func (e *SyncCommandError) Retriable() bool {
+	return e.HTTPCode == http.StatusTooManyRequests || e.HTTPCode == http.StatusRequestTimeout || e.HTTPCode >= 500
+}
+
+// CommandResult returns the outcome of the command with the given UUID.
+// ok is false when the server did not report on the command at all.
+func (r *SyncResponse) CommandResult(uuid string) (cmdErr *SyncCommandError, ok bool) {
+	raw, found := r.SyncStatus[uuid]
+	if !found {
+		return nil, false
+	}
+	var status string
+	if json.Unmarshal(raw, &status) == nil {
+		if status == "ok" {
+			return nil, true
+		}
+		return &SyncCommandError{Message: status}, true
+	}
+	var e SyncCommandError
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return &SyncCommandError{Message: "unreadable sync status: " + string(raw)}, true
+	}
+	return &e, true
+}
+
+// Sync reads resources changed since syncToken ("*" for a full sync).
+func (c *SyncClient) Sync(ctx context.Context, syncToken string, resourceTypes []string) (*SyncResponse, error) {
+	return c.SyncWithCommands(ctx, syncToken, resourceTypes, nil)
+}
+
+// SyncWithCommands sends write commands and reads changed resources in one request.
+func (c *SyncClient) SyncWithCommands(ctx context.Context, syncToken string, resourceTypes []string, cmds []SyncCommand) (*SyncResponse, error) {
+	if syncToken == "" {
+		syncToken = fullSyncToken
+	}
+	types, err := json.Marshal(resourceTypes)
+	if err != nil {
+		return nil, fmt.Errorf("marshal resource types: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("sync_token", syncToken)
+	form.Set("resource_types", string(types))
+	if len(cmds) > 0 {
+		body, err := json.Marshal(cmds)
+		if err != nil {
+			return nil, fmt.Errorf("marshal commands: %w", err)
+		}
+		form.Set("commands", string(body))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/sync", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(c.limiter, resp, respBody)
+	}
+
+	var out SyncResponse
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return nil, fmt.Errorf("decode sync: %w", err)
+	}
+	for i := range out.Items {
+		out.Items[i].Priority = priorityFromSync(out.Items[i].Priority)
+	}
+	return &out, nil
+}
+
+// priorityFromSync converts Sync priorities (4 = very urgent) to the
+// app's p1..p4 convention (1 = very urgent).
+func priorityFromSync(p int) int {
+	if p < 1 || p > 4 {
+		return 4
+	}
+	return 5 - p
+}
+
+// priorityToSync converts the app's p1..p4 convention back to Sync priorities.
+func priorityToSync(p int) int {
+	if p < 1 || p > 4 {
+		return 1
+	}
+	return 5 - p
+}
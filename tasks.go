@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -15,6 +17,12 @@ type displayItem struct {
 	section   *Section
 	task      *Task
 	completed bool
+
+	// Subtask tree (TasksView only); for section headers childTotal counts
+	// the section's active tasks
+	depth      int
+	childTotal int // direct children, active and completed
+	childDone  int
 }
 
 // TasksView displays tasks for the selected project
@@ -32,9 +40,23 @@ type TasksView struct {
 	projectID   string
 	projectName string
 
+	// Label view: tasks across projects carrying this label, grouped by project
+	labelName string
+
+	// Saved filter view: tasks matching the query, one group per sub-query
+	filterID     string
+	filterName   string
+	filterQuery  string
+	filterErr    string
+	filterGroups [][]Task
+
 	// Dialog state
-	mode            string // "", "quick-add", "edit", "delete", "due", "deadline"
+	mode            string // "", "quick-add", "edit", "delete", "due", "deadline", "labels", "complete-subtasks", "section-add", "section-rename", "section-archive", "section-delete"
 	editInput       textinput.Model
+	labelInput      textinput.Model
+	labelsBefore    []string // labels shared by the targets when the labels dialog opened
+	knownLabels     []Label  // for label autocomplete
+	sectionInput    textinput.Model
 	dueInput        textinput.Model
 	deadlineInput   textinput.Model
 	quickInput      textinput.Model
@@ -43,12 +65,15 @@ type TasksView struct {
 	// Scroll offset
 	scrollOffset int
 
-	// Loading state (waiting for async task fetch)
+	// Loading state (cache empty until the first sync lands)
 	loading bool
 
 	// Completed tasks (in-memory, cleared on project switch)
 	completedTasks []Task
 
+	// Parent task and section IDs whose tasks are hidden
+	collapsed map[string]bool
+
 	// Page-local search
 	searchMode   bool
 	searchInput  textinput.Model
@@ -58,6 +83,12 @@ type TasksView struct {
 
 	// Jump target (set by global search navigation)
 	jumpToTaskID string
+
+	// Mouse drag: the task being dragged to a new place among its siblings
+	dragTaskID string
+
+	// Tasks marked for a bulk edit
+	marks taskMarks
 }
 
 func NewTasksView(repo *Repository) TasksView {
@@ -81,9 +112,21 @@ func NewTasksView(repo *Repository) TasksView {
 	si.Placeholder = "Search..."
 	si.CharLimit = 200
 
+	sei := textinput.New()
+	sei.Placeholder = "Section name..."
+	sei.CharLimit = 120
+
+	li := textinput.New()
+	li.Placeholder = "urgent work (space-separated)"
+	li.CharLimit = 200
+
 	return TasksView{
 		repo:          repo,
+		collapsed:     make(map[string]bool),
+		marks:         newTaskMarks(),
 		editInput:     ei,
+		labelInput:    li,
+		sectionInput:  sei,
 		dueInput:      di,
 		deadlineInput: dli,
 		quickInput:    qi,
@@ -98,85 +141,171 @@ func (v TasksView) Init() tea.Cmd {
 func (v TasksView) LoadProject(projectID, projectName string) (TasksView, tea.Cmd) {
 	v.projectID = projectID
 	v.projectName = projectName
+	v.labelName = ""
+	v.filterID = ""
 	v.cursor = 0
 	v.scrollOffset = 0
 	v.completedTasks = nil
+	v.marks.clear()
 	v.searchMode = false
 	v.searchQuery = ""
 	v.matchIndices = nil
 	v.currentMatch = 0
 
-	// Sync-load cache for instant display
+	// The sync engine keeps the cache current, so projects load from it directly.
 	v.tasks = v.repo.GetCachedTasks(projectID)
 	v.sections = v.repo.GetCachedSections(projectID)
 	v.rebuildItems()
-	v.loading = len(v.tasks) == 0
+	v.loading = len(v.tasks) == 0 && v.repo.LastSynced() == nil
 
-	return v, tea.Batch(
-		v.repo.FetchTasks(projectID),
-		v.repo.FetchSections(projectID),
-	)
+	return v, nil
 }
 
-func (v TasksView) Update(msg tea.Msg) (TasksView, tea.Cmd) {
-	switch msg := msg.(type) {
-	case cachedTasksMsg:
-		if msg.projectID != v.projectID {
-			return v, nil
-		}
-		v.tasks = msg.tasks
-		v.loading = false
-		v.rebuildItems()
-		return v, v.repo.RefreshTasks(v.projectID)
+// LoadLabel shows every cached task carrying the label, grouped by project.
+func (v TasksView) LoadLabel(name string) (TasksView, tea.Cmd) {
+	v.projectID = ""
+	v.projectName = ""
+	v.labelName = name
+	v.filterID = ""
+	v.cursor = 0
+	v.scrollOffset = 0
+	v.completedTasks = nil
+	v.marks.clear()
+	v.searchMode = false
+	v.searchQuery = ""
+	v.matchIndices = nil
+	v.currentMatch = 0
 
-	case cachedSectionsMsg:
-		if msg.projectID != v.projectID {
-			return v, nil
-		}
-		v.sections = msg.sections
-		v.rebuildItems()
-		return v, v.repo.RefreshSections(v.projectID)
+	v.tasks = v.repo.GetCachedLabelTasks(name)
+	v.sections = nil
+	v.rebuildItems()
+	v.loading = false
+	return v, nil
+}
 
-	case tasksMsg:
-		if msg.projectID != v.projectID {
-			return v, nil
-		}
-		if msg.err != nil {
-			v.loading = false
-			return v, func() tea.Msg {
-				return toastMsg{text: "Failed to load tasks: " + msg.err.Error(), isError: true}
+// LoadFilter shows the cached tasks matching a saved filter, evaluated locally.
+func (v TasksView) LoadFilter(f SavedFilter) (TasksView, tea.Cmd) {
+	v.projectID = ""
+	v.projectName = ""
+	v.labelName = ""
+	v.filterID = f.ID
+	v.filterName = f.Name
+	v.filterQuery = f.Query
+	v.cursor = 0
+	v.scrollOffset = 0
+	v.completedTasks = nil
+	v.marks.clear()
+	v.searchMode = false
+	v.searchQuery = ""
+	v.matchIndices = nil
+	v.currentMatch = 0
+
+	v.evalFilter()
+	v.sections = nil
+	v.rebuildItems()
+	v.loading = false
+	return v, nil
+}
+
+// RefreshFilter picks up an edited saved filter without resetting the cursor.
+func (v *TasksView) RefreshFilter(f SavedFilter) {
+	v.filterName = f.Name
+	v.filterQuery = f.Query
+	v.Reload()
+}
+
+// evalFilter re-runs the filter query against the cache.
+func (v *TasksView) evalFilter() {
+	v.filterErr = ""
+	v.filterGroups = nil
+	v.tasks = nil
+	groups, err := v.repo.FilterTaskGroups(v.filterQuery)
+	if err != nil {
+		v.filterErr = err.Error()
+		return
+	}
+	v.filterGroups = groups
+	seen := make(map[string]bool)
+	for _, g := range groups {
+		for _, t := range g {
+			if !seen[t.ID] {
+				seen[t.ID] = true
+				v.tasks = append(v.tasks, t)
 			}
 		}
-		v.tasks = msg.tasks
-		v.loading = false
-		v.rebuildItems()
-		v.clampCursor()
-		return v, nil
+	}
+}
 
-	case sectionsMsg:
-		if msg.projectID != v.projectID {
-			return v, nil
-		}
-		if msg.err != nil {
-			return v, nil
+// hasView reports whether a project, label or filter is loaded.
+func (v TasksView) hasView() bool {
+	return v.projectID != "" || v.labelName != "" || v.filterID != ""
+}
+
+// Reload re-reads the current project from the cache after a sync,
+// keeping the cursor on the same task when possible.
+func (v *TasksView) Reload() {
+	if !v.hasView() {
+		return
+	}
+	var selectedID, selectedSectionID string
+	if t := v.selectedTask(); t != nil {
+		selectedID = t.ID
+	} else if sec := v.selectedSection(); sec != nil {
+		selectedSectionID = sec.ID
+	}
+	if v.filterID != "" {
+		v.evalFilter()
+	} else if v.labelName != "" {
+		v.tasks = v.repo.GetCachedLabelTasks(v.labelName)
+	} else {
+		v.tasks = v.repo.GetCachedTasks(v.projectID)
+		v.sections = v.repo.GetCachedSections(v.projectID)
+	}
+	v.loading = false
+	v.rebuildItems()
+	v.marks.prune(v.taskOrder())
+	if selectedID != "" {
+		for i, item := range v.items {
+			if item.task != nil && !item.completed && item.task.ID == selectedID {
+				v.cursor = i
+				break
+			}
 		}
-		v.sections = msg.sections
-		v.rebuildItems()
-		v.clampCursor()
-		return v, nil
+	} else if selectedSectionID != "" {
+		v.selectSection(selectedSectionID)
+	}
+	v.clampCursor()
+	if v.searchQuery != "" {
+		v.matchIndices = findMatchIndices(v.items, v.searchQuery)
+	}
+}
 
+func (v TasksView) Update(msg tea.Msg) (TasksView, tea.Cmd) {
+	switch msg := msg.(type) {
 	case taskClosedMsg:
 		if msg.err != nil {
 			return v, func() tea.Msg {
 				return toastMsg{text: "Failed to complete task: " + msg.err.Error(), isError: true}
 			}
 		}
+		if msg.next != nil {
+			for i, t := range v.tasks {
+				if t.ID == msg.taskID {
+					v.tasks[i] = *msg.next
+					break
+				}
+			}
+			v.rebuildItems()
+			return v, recurringCompletedToast(msg.next)
+		}
 		// Move task to completedTasks (shown at bottom with strikethrough)
-		for i, t := range v.tasks {
-			if t.ID == msg.taskID {
-				v.completedTasks = append(v.completedTasks, v.tasks[i])
-				v.tasks = append(v.tasks[:i], v.tasks[i+1:]...)
-				break
+		for _, id := range append([]string{msg.taskID}, msg.subtaskIDs...) {
+			for i, t := range v.tasks {
+				if t.ID == id {
+					v.completedTasks = append(v.completedTasks, v.tasks[i])
+					v.tasks = append(v.tasks[:i], v.tasks[i+1:]...)
+					break
+				}
 			}
 		}
 		v.rebuildItems()
@@ -198,7 +327,7 @@ func (v TasksView) Update(msg tea.Msg) (TasksView, tea.Cmd) {
 				break
 			}
 		}
-		if msg.task.ProjectID == v.projectID {
+		if v.shows(msg.task) {
 			v.tasks = append(v.tasks, msg.task)
 		}
 		v.rebuildItems()
@@ -236,6 +365,15 @@ func (v TasksView) Update(msg tea.Msg) (TasksView, tea.Cmd) {
 			return toastMsg{text: "Task created", isError: false}
 		}
 
+	case taskMovedMsg:
+		if msg.err != nil {
+			return v, func() tea.Msg {
+				return toastMsg{text: "Failed to move task: " + msg.err.Error(), isError: true}
+			}
+		}
+		v.Reload()
+		return v, nil
+
 	case taskUpdatedMsg:
 		if msg.err != nil {
 			return v, func() tea.Msg {
@@ -245,31 +383,53 @@ func (v TasksView) Update(msg tea.Msg) (TasksView, tea.Cmd) {
 		for i, t := range v.tasks {
 			if t.ID == msg.task.ID {
 				v.tasks[i] = msg.task
+				if !v.shows(msg.task) {
+					// Label removed: the task leaves this label view.
+					v.tasks = append(v.tasks[:i], v.tasks[i+1:]...)
+				}
 				break
 			}
 		}
 		v.rebuildItems()
+		v.clampCursor()
 		return v, func() tea.Msg {
 			return toastMsg{text: "Task updated", isError: false}
 		}
 
+	case bulkEditMsg:
+		v.Reload()
+		return v, func() tea.Msg {
+			return toastMsg{text: fmt.Sprintf("%s %d tasks", msg.verb, msg.count), isError: false}
+		}
+
+	case sectionUpdatedMsg:
+		if msg.err != nil {
+			return v, func() tea.Msg {
+				return toastMsg{text: "Section change failed: " + msg.err.Error(), isError: true}
+			}
+		}
+		if msg.section.ProjectID == v.projectID && v.labelName == "" && v.filterID == "" {
+			v.Reload()
+			v.selectSection(msg.section.ID)
+			v.clampCursor()
+			v.ensureVisible()
+		}
+		return v, nil
+
 	case quickAddMsg:
 		if msg.err != nil {
 			return v, func() tea.Msg {
 				return toastMsg{text: "Quick add failed: " + msg.err.Error(), isError: true}
 			}
 		}
-		if msg.task != nil && msg.task.ProjectID == v.projectID {
+		if msg.task != nil && v.shows(*msg.task) {
 			v.tasks = append(v.tasks, *msg.task)
 			v.rebuildItems()
 			v.clampCursor()
 			return v, func() tea.Msg { return toastMsg{text: "Task queued", isError: false} }
 		}
-		// Unknown project destination: refresh current list for eventual consistency.
-		return v, tea.Batch(
-			v.repo.RefreshTasks(v.projectID),
-			func() tea.Msg { return toastMsg{text: "Task queued", isError: false} },
-		)
+		// Unknown project destination: the next sync brings the parsed task in.
+		return v, func() tea.Msg { return toastMsg{text: "Task queued", isError: false} }
 
 	case tea.KeyMsg:
 		if !v.focused && v.mode == "" && !v.searchMode {
@@ -299,6 +459,16 @@ func (v TasksView) Update(msg tea.Msg) (TasksView, tea.Cmd) {
 		v.quickInput, cmd = v.quickInput.Update(msg)
 		return v, cmd
 	}
+	if v.mode == "labels" {
+		var cmd tea.Cmd
+		v.labelInput, cmd = v.labelInput.Update(msg)
+		return v, cmd
+	}
+	if v.mode == "section-add" || v.mode == "section-rename" {
+		var cmd tea.Cmd
+		v.sectionInput, cmd = v.sectionInput.Update(msg)
+		return v, cmd
+	}
 	if v.searchMode {
 		var cmd tea.Cmd
 		v.searchInput, cmd = v.searchInput.Update(msg)
@@ -316,6 +486,9 @@ func (v TasksView) handleKey(msg tea.KeyMsg) (TasksView, tea.Cmd) {
 	if msg.String() == "N" && v.searchQuery != "" {
 		normalAction = ActionSearchPrev
 	}
+	if msg.String() == " " && v.marks.count() > 0 {
+		normalAction = ActionToggleMark
+	}
 
 	if v.searchMode {
 		switch ResolveAction(ContextMainTasksSearch, msg.String()) {
@@ -353,6 +526,9 @@ func (v TasksView) handleKey(msg tea.KeyMsg) (TasksView, tea.Cmd) {
 				text += " #" + v.quickAddProject
 				defaultProjectID = v.projectID
 			}
+			if v.labelName != "" && !slices.Contains(strings.Fields(text), "@"+v.labelName) {
+				text += " @" + v.labelName
+			}
 			v.mode = ""
 			return v, v.repo.QuickAdd(text, defaultProjectID)
 		case ActionCancel:
@@ -390,17 +566,10 @@ func (v TasksView) handleKey(msg tea.KeyMsg) (TasksView, tea.Cmd) {
 		switch ResolveAction(ContextMainTasksSearch, msg.String()) {
 		case ActionConfirm:
 			dueStr := strings.TrimSpace(v.dueInput.Value())
-			task := v.selectedTask()
-			if task == nil {
-				v.mode = ""
-				return v, nil
-			}
 			v.mode = ""
-			if dueStr == "" {
-				empty := ""
-				return v, v.repo.UpdateTask(task.ID, updateTaskRequest{DueString: &empty})
-			}
-			return v, v.repo.UpdateTask(task.ID, updateTaskRequest{DueString: &dueStr})
+			return v, bulkOrSingle(v.repo, "Rescheduled", v.takeTargets(), func(id string) tea.Cmd {
+				return v.repo.UpdateTask(id, updateTaskRequest{DueString: &dueStr})
+			})
 		case ActionCancel:
 			v.mode = ""
 			return v, nil
@@ -431,23 +600,136 @@ func (v TasksView) handleKey(msg tea.KeyMsg) (TasksView, tea.Cmd) {
 		v.deadlineInput, cmd = v.deadlineInput.Update(msg)
 		return v, cmd
 
-	case "delete":
+	case "complete-subtasks":
 		switch ResolveAction(ContextMainSidebarDialog, msg.String()) {
 		case ActionConfirm:
-			task := v.selectedTask()
-			if task != nil {
-				v.mode = ""
-				return v, v.repo.DeleteTask(task.ID)
+			v.mode = ""
+			if task := v.selectedTask(); task != nil {
+				var ids []string
+				for _, d := range descendantsOf(v.tasks, task.ID) {
+					ids = append(ids, d.ID)
+				}
+				return v, v.repo.CloseTaskTree(task.ID, ids)
 			}
+			return v, nil
+		case ActionCancel:
+			v.mode = ""
+			return v, nil
+		}
+		return v, nil
+
+	case "labels":
+		switch ResolveAction(ContextMainTasksSearch, msg.String()) {
+		case ActionCompleteLabel:
+			if matches := labelCompletions(v.labelInput.Value(), v.knownLabels); len(matches) > 0 {
+				v.labelInput.SetValue(completeLabelInput(v.labelInput.Value(), matches[0]))
+				v.labelInput.CursorEnd()
+			}
+			return v, nil
+		case ActionConfirm:
+			v.mode = ""
+			v.labelInput.Blur()
+			before, after := v.labelsBefore, parseLabelInput(v.labelInput.Value())
+			current := make(map[string][]string)
+			for _, t := range v.tasks {
+				current[t.ID] = t.Labels
+			}
+			return v, bulkOrSingle(v.repo, "Relabeled", v.takeTargets(), func(id string) tea.Cmd {
+				return v.repo.UpdateTask(id, updateTaskRequest{Labels: editLabels(current[id], before, after)})
+			})
+		case ActionCancel:
+			v.mode = ""
+			v.labelInput.Blur()
+			return v, nil
+		}
+		var cmd tea.Cmd
+		v.labelInput, cmd = v.labelInput.Update(msg)
+		return v, cmd
+
+	case "delete":
+		switch ResolveAction(ContextMainSidebarDialog, msg.String()) {
+		case ActionConfirm:
+			v.mode = ""
+			return v, bulkOrSingle(v.repo, "Deleted", v.takeTargets(), v.repo.DeleteTask)
+		case ActionCancel:
 			v.mode = ""
 			return v, nil
+		}
+		return v, nil
+
+	case "section-add", "section-rename":
+		switch ResolveAction(ContextMainTasksSearch, msg.String()) {
+		case ActionConfirm:
+			mode := v.mode
+			v.mode = ""
+			v.sectionInput.Blur()
+			name := strings.TrimSpace(v.sectionInput.Value())
+			if name == "" {
+				return v, nil
+			}
+			if mode == "section-add" {
+				return v, v.repo.CreateSection(v.projectID, name)
+			}
+			if sec := v.selectedSection(); sec != nil && name != sec.Name {
+				return v, v.repo.RenameSection(sec.ID, name)
+			}
+			return v, nil
 		case ActionCancel:
 			v.mode = ""
+			v.sectionInput.Blur()
 			return v, nil
 		}
+		var cmd tea.Cmd
+		v.sectionInput, cmd = v.sectionInput.Update(msg)
+		return v, cmd
+
+	case "section-archive", "section-delete":
+		switch ResolveAction(ContextMainSidebarDialog, msg.String()) {
+		case ActionConfirm:
+			mode := v.mode
+			v.mode = ""
+			sec := v.selectedSection()
+			if sec == nil {
+				return v, nil
+			}
+			if mode == "section-archive" {
+				return v, v.repo.ArchiveSection(sec.ID)
+			}
+			return v, v.repo.DeleteSection(sec.ID)
+		case ActionCancel:
+			v.mode = ""
+		}
 		return v, nil
 	}
 
+	// Section headers are selectable in project views and take their own actions.
+	if sec := v.selectedSection(); sec != nil {
+		switch normalAction {
+		case ActionToggleCollapse:
+			v.collapsed[sec.ID] = !v.collapsed[sec.ID]
+			v.rebuildItems()
+			v.selectSection(sec.ID)
+			v.ensureVisible()
+			return v, nil
+		case ActionEditTask:
+			v.mode = "section-rename"
+			v.sectionInput.SetValue(sec.Name)
+			v.sectionInput.CursorEnd()
+			v.sectionInput.Focus()
+			return v, textinput.Blink
+		case ActionDeleteTask:
+			v.mode = "section-delete"
+			return v, nil
+		case ActionArchiveSection:
+			v.mode = "section-archive"
+			return v, nil
+		case ActionMoveDown:
+			return v, v.repo.MoveSection(sec.ID, 1)
+		case ActionMoveUp:
+			return v, v.repo.MoveSection(sec.ID, -1)
+		}
+	}
+
 	switch normalAction {
 	case ActionSearchLocal:
 		v.searchMode = true
@@ -479,6 +761,20 @@ func (v TasksView) handleKey(msg tea.KeyMsg) (TasksView, tea.Cmd) {
 			v.searchQuery = ""
 			v.matchIndices = nil
 			v.currentMatch = 0
+		} else {
+			v.marks.clear()
+		}
+		return v, nil
+	case ActionToggleMark:
+		if item := v.selectedItem(); item != nil && !item.completed {
+			v.marks.toggle(item.task.ID)
+			v.moveDown()
+			v.ensureVisible()
+		}
+		return v, nil
+	case ActionMarkRange:
+		if item := v.selectedItem(); item != nil && !item.completed {
+			v.marks.markRange(v.taskOrder(), item.task.ID)
 		}
 		return v, nil
 	case ActionNavDown:
@@ -490,20 +786,71 @@ func (v TasksView) handleKey(msg tea.KeyMsg) (TasksView, tea.Cmd) {
 		v.ensureVisible()
 		return v, nil
 	case ActionNavTop:
-		listJumpTop(&v.cursor, &v.scrollOffset, len(v.items), func(idx int) bool { return v.items[idx].isSection })
+		listJumpTop(&v.cursor, &v.scrollOffset, len(v.items), v.skipItem)
 		return v, nil
 	case ActionNavBottom:
-		listJumpBottom(&v.cursor, len(v.items), func(idx int) bool { return v.items[idx].isSection })
+		listJumpBottom(&v.cursor, len(v.items), v.skipItem)
 		v.ensureVisible()
 		return v, nil
 	case ActionToggleDone:
+		if v.marks.count() > 0 {
+			return v, v.closeTargets(v.takeTargets())
+		}
 		item := v.selectedItem()
 		if item != nil && item.task != nil {
 			if item.completed {
 				return v, v.repo.ReopenTask(*item.task)
 			}
+			// Todoist completes subtasks with their parent, so ask first.
+			recurring := item.task.Due != nil && item.task.Due.IsRecurring
+			if !recurring && len(descendantsOf(v.tasks, item.task.ID)) > 0 {
+				v.mode = "complete-subtasks"
+				return v, nil
+			}
 			return v, v.repo.CloseTask(item.task.ID)
 		}
+	case ActionOpenDetail:
+		if task := v.selectedTask(); task != nil {
+			t := *task
+			return v, func() tea.Msg { return openTaskDetailMsg{task: t} }
+		}
+		return v, nil
+	case ActionToggleCollapse:
+		if item := v.selectedItem(); item != nil && item.task != nil && !item.completed && item.childTotal > item.childDone {
+			v.collapsed[item.task.ID] = !v.collapsed[item.task.ID]
+			v.rebuildItems()
+			v.clampCursor()
+			v.ensureVisible()
+		}
+		return v, nil
+	case ActionAddSection:
+		if v.projectID != "" && v.labelName == "" && v.filterID == "" {
+			v.mode = "section-add"
+			v.sectionInput.Reset()
+			v.sectionInput.Focus()
+			return v, textinput.Blink
+		}
+		return v, nil
+	case ActionMoveTask:
+		if item := v.selectedItem(); v.marks.count() > 0 || (item != nil && !item.completed) {
+			tasks := v.tasksByID(v.takeTargets())
+			return v, func() tea.Msg { return openMovePickerMsg{tasks: tasks} }
+		}
+		return v, nil
+	case ActionMoveDown, ActionMoveUp:
+		item := v.selectedItem()
+		if item == nil || item.completed || !v.inProject() {
+			return v, nil
+		}
+		delta := 1
+		if normalAction == ActionMoveUp {
+			delta = -1
+		}
+		return v, v.repo.ReorderTask(item.task.ID, delta)
+	case ActionIndent:
+		return v, v.indentSelected()
+	case ActionOutdent:
+		return v, v.outdentSelected()
 	case ActionEditTask:
 		task := v.selectedTask()
 		if task != nil {
@@ -513,12 +860,32 @@ func (v TasksView) handleKey(msg tea.KeyMsg) (TasksView, tea.Cmd) {
 			return v, textinput.Blink
 		}
 	case ActionDeleteTask:
-		if v.selectedTask() != nil {
+		if v.selectedTask() != nil || v.marks.count() > 0 {
 			v.mode = "delete"
 		}
 		return v, nil
+	case ActionCopyJSON:
+		return v, copyTasksJSON(v.repo, v.tasksByID(v.marks.targets(v.taskOrder(), v.selectedTask())))
+	case ActionSetLabels:
+		tasks := v.tasksByID(v.marks.targets(v.taskOrder(), v.selectedTask()))
+		if len(tasks) > 0 {
+			v.mode = "labels"
+			v.knownLabels = v.repo.GetCachedLabels()
+			v.labelsBefore = commonLabels(tasks)
+			v.labelInput.SetValue(strings.Join(v.labelsBefore, " "))
+			v.labelInput.CursorEnd()
+			v.labelInput.Focus()
+			return v, textinput.Blink
+		}
+		return v, nil
 	case ActionSetDue:
 		task := v.selectedTask()
+		if v.marks.count() > 0 {
+			v.mode = "due"
+			v.dueInput.Reset()
+			v.dueInput.Focus()
+			return v, textinput.Blink
+		}
 		if task != nil {
 			v.mode = "due"
 			v.dueInput.Reset()
@@ -559,26 +926,25 @@ func (v TasksView) handleKey(msg tea.KeyMsg) (TasksView, tea.Cmd) {
 			ClearDeadline: true,
 		})
 	case ActionSetPriority1, ActionSetPriority2, ActionSetPriority3, ActionSetPriority4:
-		task := v.selectedTask()
-		if task != nil {
-			p := 1
-			switch normalAction {
-			case ActionSetPriority2:
-				p = 2
-			case ActionSetPriority3:
-				p = 3
-			case ActionSetPriority4:
-				p = 4
-			}
-			return v, v.repo.UpdateTask(task.ID, updateTaskRequest{Priority: &p})
-		}
+		p := 1
+		switch normalAction {
+		case ActionSetPriority2:
+			p = 2
+		case ActionSetPriority3:
+			p = 3
+		case ActionSetPriority4:
+			p = 4
+		}
+		return v, bulkOrSingle(v.repo, "Reprioritized", v.takeTargets(), func(id string) tea.Cmd {
+			return v.repo.UpdateTask(id, updateTaskRequest{Priority: &p})
+		})
 	}
 
 	return v, nil
 }
 
 func (v TasksView) View() string {
-	if v.projectID == "" {
+	if !v.hasView() {
 		return emptyStyle.Render("Select a project")
 	}
 	if v.loading {
@@ -589,6 +955,11 @@ func (v TasksView) View() string {
 			Render(v.projectName) + "\n" +
 			emptyStyle.Render("Loading tasks...")
 	}
+	if v.filterErr != "" {
+		return lipgloss.NewStyle().Foreground(colorBright).Bold(true).Padding(0, 0, 1, 0).Render(v.title()) + "\n" +
+			queueConflictStyle.Render("⚠ "+v.filterErr) + "\n" +
+			emptyStyle.Render(v.filterQuery)
+	}
 	if len(v.items) == 0 && len(v.tasks) == 0 {
 		content := emptyStyle.Render("No tasks - press 'n' to add one")
 		if v.mode != "" && v.mode != "quick-add" {
@@ -606,7 +977,10 @@ func (v TasksView) View() string {
 		Foreground(colorBright).
 		Bold(true).
 		Padding(0, 0, 1, 0).
-		Render(v.projectName)
+		Render(v.title())
+	if n := v.marks.count(); n > 0 {
+		title = lipgloss.JoinHorizontal(lipgloss.Top, title, "  ", markedCheckStyle.Render(fmt.Sprintf("%d marked", n)))
+	}
 	b.WriteString(title)
 	b.WriteString("\n")
 
@@ -621,19 +995,18 @@ func (v TasksView) View() string {
 		end = len(v.items)
 	}
 
+	sectionStatus := v.repo.SectionMutationStatusMap()
 	for i := v.scrollOffset; i < end; i++ {
 		item := v.items[i]
 		if item.isSection {
-			name := item.section.Name
-			b.WriteString(sectionStyle.Render("━━ " + name))
+			b.WriteString(v.renderSectionHeader(item, i == v.cursor && v.focused, sectionStatus[item.section.ID]))
 			b.WriteString("\n")
 			continue
 		}
 
-		task := item.task
 		selected := i == v.cursor && v.focused
 
-		line := v.renderTask(task, selected, item.completed, mutationStatus[task.ID], assigneeNames)
+		line := v.renderTask(item, selected, mutationStatus[item.task.ID], assigneeNames)
 		b.WriteString(line)
 		if i < end-1 {
 			b.WriteString("\n")
@@ -649,8 +1022,10 @@ func (v TasksView) View() string {
 	return b.String()
 }
 
-func (v TasksView) renderTask(task *Task, selected bool, completed bool, syncStatus MutationStatus, assigneeNames map[string]string) string {
-	maxContentWidth := v.width - 34
+func (v TasksView) renderTask(item displayItem, selected bool, syncStatus MutationStatus, assigneeNames map[string]string) string {
+	task, completed := item.task, item.completed
+	indent := strings.Repeat("  ", item.depth)
+	maxContentWidth := v.width - 34 - len(indent)
 	if maxContentWidth < 20 {
 		maxContentWidth = 20
 	}
@@ -660,6 +1035,8 @@ func (v TasksView) renderTask(task *Task, selected bool, completed bool, syncSta
 		check := "○"
 		if completed {
 			check = "✓"
+		} else if v.marks.has(task.ID) {
+			check = markedCheck
 		}
 		content := truncate(task.Content, maxContentWidth)
 		if v.searchQuery != "" {
@@ -667,6 +1044,9 @@ func (v TasksView) renderTask(task *Task, selected bool, completed bool, syncSta
 		}
 		var parts []string
 		parts = append(parts, check, content)
+		if fold := v.foldMarker(item); fold != "" {
+			parts = append(parts, fold)
+		}
 		if task.Due != nil {
 			dueText := formatDue(task.Due)
 			if dueText != "" {
@@ -700,12 +1080,16 @@ func (v TasksView) renderTask(task *Task, selected bool, completed bool, syncSta
 			Foreground(colorBright).
 			Bold(true).
 			Width(v.width).
-			Render("  " + strings.Join(parts, "  "))
+			Render("  " + indent + strings.Join(parts, "  "))
 	}
 
 	// Non-selected: full styled rendering
 	var parts []string
-	parts = append(parts, styledCheckbox(completed, task.Priority))
+	if !completed && v.marks.has(task.ID) {
+		parts = append(parts, markedCheckStyle.Render(markedCheck))
+	} else {
+		parts = append(parts, styledCheckbox(completed, task.Priority))
+	}
 
 	content := truncate(task.Content, maxContentWidth)
 	if completed {
@@ -722,6 +1106,9 @@ func (v TasksView) renderTask(task *Task, selected bool, completed bool, syncSta
 		}
 	}
 	parts = append(parts, content)
+	if fold := v.foldMarker(item); fold != "" {
+		parts = append(parts, subtaskCountStyle.Render(fold))
+	}
 
 	if task.Due != nil {
 		dueText := formatDue(task.Due)
@@ -771,7 +1158,52 @@ func (v TasksView) renderTask(task *Task, selected bool, completed bool, syncSta
 		parts = append(parts, badge)
 	}
 
-	return "  " + strings.Join(parts, "  ")
+	return "  " + indent + strings.Join(parts, "  ")
+}
+
+// renderSectionHeader draws a section divider. Real project sections show a
+// fold marker, and the hidden task count while collapsed.
+func (v TasksView) renderSectionHeader(item displayItem, selected bool, syncStatus MutationStatus) string {
+	name := item.section.Name
+	if !v.isSectionHeader(item) {
+		return sectionStyle.Render("━━ " + name)
+	}
+	fold := "▾"
+	if v.collapsed[item.section.ID] {
+		fold = fmt.Sprintf("▸ (%d)", item.childTotal)
+	}
+	if selected {
+		line := "━━ " + name + "  " + fold
+		if badge := mutationBadgePlain(syncStatus); badge != "" {
+			line += "  " + badge
+		}
+		return lipgloss.NewStyle().
+			Background(colorBgHL).
+			Foreground(colorBright).
+			Bold(true).
+			Width(v.width).
+			Render(line)
+	}
+	line := sectionStyle.Render("━━ "+name) + "  " + subtaskCountStyle.Render(fold)
+	if badge := mutationBadgeStyled(syncStatus); badge != "" {
+		line += "  " + badge
+	}
+	return line
+}
+
+// foldMarker shows whether a parent's subtasks are expanded, and how many are
+// done while they are hidden.
+func (v TasksView) foldMarker(item displayItem) string {
+	if item.completed || item.childTotal == 0 {
+		return ""
+	}
+	if v.collapsed[item.task.ID] {
+		return fmt.Sprintf("▸ %d/%d", item.childDone, item.childTotal)
+	}
+	if item.childTotal > item.childDone {
+		return "▾"
+	}
+	return ""
 }
 
 func (v TasksView) renderDialog() string {
@@ -799,11 +1231,74 @@ func (v TasksView) renderDialog() string {
 				inputLabelStyle.Render("YYYY-MM-DD (empty to clear)") + "\n" +
 				v.deadlineInput.View(),
 		)
-	case "delete":
+	case "complete-subtasks":
 		task := v.selectedTask()
+		if task == nil {
+			return ""
+		}
+		n := len(descendantsOf(v.tasks, task.ID))
+		return dialogStyle.Width(v.width - 4).Render(
+			dialogTitleStyle.Render("Complete Task?") + "\n" +
+				taskContentStyle.Render("\""+truncate(task.Content, 60)+"\"") + "\n" +
+				inputLabelStyle.Render(fmt.Sprintf("Its %d subtask(s) will be completed too.", n)) + "\n\n" +
+				footerKeyStyle.Render("y") + " complete all  " +
+				footerKeyStyle.Render("n") + " cancel",
+		)
+	case "section-add", "section-rename":
+		title := "New Section"
+		if v.mode == "section-rename" {
+			title = "Rename Section"
+		}
+		return dialogStyle.Width(v.width - 4).Render(
+			dialogTitleStyle.Render(title) + "\n" +
+				v.sectionInput.View(),
+		)
+	case "section-archive", "section-delete":
+		sec := v.selectedSection()
+		if sec == nil {
+			return ""
+		}
+		title, note := "Delete Section?", "Its %d task(s) will be deleted too."
+		if v.mode == "section-archive" {
+			title, note = "Archive Section?", "Its %d task(s) will be archived with it."
+		}
+		n := 0
+		for _, t := range v.tasks {
+			if t.SectionID == sec.ID {
+				n++
+			}
+		}
+		body := dialogTitleStyle.Render(title) + "\n" +
+			taskContentStyle.Render("\""+truncate(sec.Name, 60)+"\"") + "\n"
+		if n > 0 {
+			body += inputLabelStyle.Render(fmt.Sprintf(note, n)) + "\n"
+		}
+		return dialogStyle.Width(v.width - 4).Render(body + "\n" +
+			footerKeyStyle.Render("y") + " confirm  " +
+			footerKeyStyle.Render("n") + " cancel",
+		)
+	case "labels":
+		title := "Set Labels"
+		if n := v.marks.count(); n > 1 {
+			title = fmt.Sprintf("Set Labels on %d Tasks", n)
+		}
+		return dialogStyle.Width(v.width - 4).Render(
+			dialogTitleStyle.Render(title) + "\n" +
+				inputLabelStyle.Render("space-separated, tab to complete") + "\n" +
+				v.labelInput.View(),
+		)
+	case "delete":
+		targets := v.tasksByID(v.marks.targets(v.taskOrder(), v.selectedTask()))
+		if len(targets) > 1 {
+			return dialogStyle.Width(v.width - 4).Render(
+				dialogTitleStyle.Render(fmt.Sprintf("Delete %d Tasks?", len(targets))) + "\n\n" +
+					footerKeyStyle.Render("y") + " confirm  " +
+					footerKeyStyle.Render("n") + " cancel",
+			)
+		}
 		name := ""
-		if task != nil {
-			name = task.Content
+		if len(targets) == 1 {
+			name = targets[0].Content
 		}
 		return dialogStyle.Width(v.width - 4).Render(
 			dialogTitleStyle.Render("Delete Task?") + "\n" +
@@ -822,6 +1317,8 @@ func (v *TasksView) SetSize(width, height int) {
 	v.dueInput.Width = width - 8
 	v.deadlineInput.Width = width - 8
 	v.quickInput.Width = width - 8
+	v.sectionInput.Width = width - 8
+	v.labelInput.Width = width - 8
 	v.searchInput.Width = 30
 }
 
@@ -865,6 +1362,37 @@ func (v TasksView) CurrentProjectName() string {
 	return v.projectName
 }
 
+// CurrentLabelName returns the label being shown, or "" in a project view.
+func (v TasksView) CurrentLabelName() string {
+	return v.labelName
+}
+
+// CurrentFilterID returns the saved filter being shown, or "".
+func (v TasksView) CurrentFilterID() string {
+	return v.filterID
+}
+
+func (v TasksView) title() string {
+	if v.filterID != "" {
+		return "⚲ " + v.filterName
+	}
+	if v.labelName != "" {
+		return "@" + v.labelName
+	}
+	return v.projectName
+}
+
+// shows reports whether t belongs in the current project, label or filter view.
+func (v TasksView) shows(t Task) bool {
+	if v.filterID != "" {
+		return v.repo.TaskMatchesFilter(v.filterQuery, t)
+	}
+	if v.labelName != "" {
+		return slices.Contains(t.Labels, v.labelName)
+	}
+	return t.ProjectID == v.projectID
+}
+
 func (v TasksView) QuickAddInputView() string {
 	return v.quickInput.View()
 }
@@ -891,6 +1419,15 @@ func (v TasksView) SearchPanel() string {
 // rebuildItems creates the flat display list from sections and tasks
 func (v *TasksView) rebuildItems() {
 	v.items = nil
+	if v.filterID != "" {
+		v.rebuildFilterItems()
+		return
+	}
+	if v.labelName != "" {
+		v.rebuildLabelItems()
+		return
+	}
+	doneCounts := v.repo.GetCompletedSubtaskCounts(v.projectID)
 
 	// Group tasks by section
 	sectionTasks := make(map[string][]Task)
@@ -906,20 +1443,14 @@ func (v *TasksView) rebuildItems() {
 	}
 
 	// Add unsectioned tasks first
-	for i := range noSection {
-		v.items = append(v.items, displayItem{task: &noSection[i]})
-	}
+	v.appendTaskTree(noSection, doneCounts)
 
-	// Add sections with their tasks
+	// Add sections with their tasks; collapsed sections show only the header
 	for i := range v.sections {
 		sec := v.sections[i]
-		tasks := sectionTasks[sec.ID]
-		if len(tasks) == 0 && len(v.sections) > 0 {
-			// Still show empty sections
-		}
-		v.items = append(v.items, displayItem{isSection: true, section: &sec})
-		for j := range tasks {
-			v.items = append(v.items, displayItem{task: &tasks[j]})
+		v.items = append(v.items, displayItem{isSection: true, section: &sec, childTotal: len(sectionTasks[sec.ID])})
+		if !v.collapsed[sec.ID] {
+			v.appendTaskTree(sectionTasks[sec.ID], doneCounts)
 		}
 	}
 
@@ -945,20 +1476,171 @@ func (v *TasksView) rebuildItems() {
 	}
 }
 
+// rebuildLabelItems lists a label's tasks under one header per project, in
+// sidebar order.
+func (v *TasksView) rebuildLabelItems() {
+	byProject := make(map[string][]Task)
+	for _, t := range v.tasks {
+		byProject[t.ProjectID] = append(byProject[t.ProjectID], t)
+	}
+	for _, p := range sortProjects(v.repo.GetCachedProjects()) {
+		tasks, ok := byProject[p.ID]
+		if !ok {
+			continue
+		}
+		delete(byProject, p.ID)
+		v.items = append(v.items, displayItem{isSection: true, section: &Section{ID: p.ID, ProjectID: p.ID, Name: p.Name}})
+		v.appendTaskTree(tasks, v.repo.GetCompletedSubtaskCounts(p.ID))
+	}
+	// Tasks whose project is not cached (e.g. shared, not yet synced)
+	var rest []Task
+	for _, t := range v.tasks {
+		if _, ok := byProject[t.ProjectID]; ok {
+			rest = append(rest, t)
+		}
+	}
+	if len(rest) > 0 {
+		v.items = append(v.items, displayItem{isSection: true, section: &Section{Name: "Other"}})
+		v.appendTaskTree(rest, nil)
+	}
+	if len(v.completedTasks) > 0 {
+		completedSection := Section{Name: "Completed"}
+		v.items = append(v.items, displayItem{isSection: true, section: &completedSection})
+		for i := range v.completedTasks {
+			v.items = append(v.items, displayItem{task: &v.completedTasks[i], completed: true})
+		}
+	}
+}
+
+// rebuildFilterItems lists a filter's matches; a comma-separated query gets
+// one header per sub-query, as in Todoist.
+func (v *TasksView) rebuildFilterItems() {
+	f, _ := ParseFilter(v.filterQuery)
+	for i, g := range v.filterGroups {
+		if len(v.filterGroups) > 1 && f != nil {
+			v.items = append(v.items, displayItem{isSection: true, section: &Section{Name: f.Queries[i]}})
+		}
+		v.appendTaskTree(g, nil)
+	}
+	if len(v.completedTasks) > 0 {
+		completedSection := Section{Name: "Completed"}
+		v.items = append(v.items, displayItem{isSection: true, section: &completedSection})
+		for i := range v.completedTasks {
+			v.items = append(v.items, displayItem{task: &v.completedTasks[i], completed: true})
+		}
+	}
+}
+
+// appendTaskTree adds tasks as an indented tree: subtasks follow their parent,
+// siblings are ordered by ChildOrder, and collapsed parents hide their subtree.
+func (v *TasksView) appendTaskTree(tasks []Task, doneCounts map[string]int) {
+	inBucket := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		inBucket[t.ID] = true
+	}
+	children := make(map[string][]*Task)
+	var roots []*Task
+	for i := range tasks {
+		t := &tasks[i]
+		if t.ParentID != nil && inBucket[*t.ParentID] {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+	byChildOrder := func(ts []*Task) {
+		sort.SliceStable(ts, func(i, j int) bool { return ts[i].ChildOrder < ts[j].ChildOrder })
+	}
+
+	var walk func(t *Task, depth int)
+	walk = func(t *Task, depth int) {
+		kids := children[t.ID]
+		byChildOrder(kids)
+		done := doneCounts[t.ID]
+		v.items = append(v.items, displayItem{
+			task:       t,
+			depth:      depth,
+			childTotal: len(kids) + done,
+			childDone:  done,
+		})
+		if v.collapsed[t.ID] {
+			return
+		}
+		for _, c := range kids {
+			walk(c, depth+1)
+		}
+	}
+	byChildOrder(roots)
+	for _, t := range roots {
+		walk(t, 0)
+	}
+}
+
+// indentSelected nests the selected task under the sibling directly above it.
+func (v *TasksView) indentSelected() tea.Cmd {
+	if v.cursor <= 0 || v.cursor >= len(v.items) {
+		return nil
+	}
+	item := v.items[v.cursor]
+	if item.task == nil || item.completed {
+		return nil
+	}
+	for i := v.cursor - 1; i >= 0; i-- {
+		above := v.items[i]
+		if above.isSection || above.depth < item.depth {
+			break
+		}
+		if above.depth == item.depth {
+			parentID := above.task.ID
+			v.collapsed[parentID] = false
+			return v.repo.MoveTask(item.task.ID, moveTaskRequest{ParentID: &parentID})
+		}
+	}
+	return func() tea.Msg {
+		return toastMsg{text: "No task above to indent under", isError: true}
+	}
+}
+
+// outdentSelected moves the selected task up one level, next to its parent.
+func (v *TasksView) outdentSelected() tea.Cmd {
+	task := v.selectedTask()
+	if task == nil || task.ParentID == nil || v.selectedItem().completed {
+		return nil
+	}
+	var parent *Task
+	for i := range v.tasks {
+		if v.tasks[i].ID == *task.ParentID {
+			parent = &v.tasks[i]
+			break
+		}
+	}
+	switch {
+	case parent != nil && parent.ParentID != nil:
+		grandparentID := *parent.ParentID
+		return v.repo.MoveTask(task.ID, moveTaskRequest{ParentID: &grandparentID})
+	case task.SectionID != "":
+		sectionID := task.SectionID
+		return v.repo.MoveTask(task.ID, moveTaskRequest{SectionID: &sectionID})
+	default:
+		projectID := task.ProjectID
+		return v.repo.MoveTask(task.ID, moveTaskRequest{ProjectID: &projectID})
+	}
+}
+
 func (v *TasksView) clampCursor() {
-	listClampCursor(&v.cursor, len(v.items), func(idx int) bool { return v.items[idx].isSection })
+	listClampCursor(&v.cursor, len(v.items), v.skipItem)
 }
 
 func (v *TasksView) moveDown() {
-	listMoveDown(&v.cursor, len(v.items), func(idx int) bool { return v.items[idx].isSection })
+	listMoveDown(&v.cursor, len(v.items), v.skipItem)
 }
 
 func (v *TasksView) moveUp() {
-	listMoveUp(&v.cursor, len(v.items), func(idx int) bool { return v.items[idx].isSection })
+	listMoveUp(&v.cursor, len(v.items), v.skipItem)
 }
 
 func (v *TasksView) skipToNextTask(dir int) {
-	listSkip(&v.cursor, len(v.items), dir, func(idx int) bool { return v.items[idx].isSection })
+	listSkip(&v.cursor, len(v.items), dir, v.skipItem)
 	v.clampCursorSimple()
 }
 
@@ -979,10 +1661,29 @@ func (v *TasksView) clampCursorSimple() {
 	}
 }
 
-// HandleMouse processes mouse events for the tasks view.
+// HandleMouse processes mouse events for the tasks view. Pressing on a task
+// and releasing over one of its siblings reorders it.
 func (v TasksView) HandleMouse(m tea.MouseEvent, yOffset int) (TasksView, tea.Cmd) {
 	if m.Action == tea.MouseActionMotion || m.Action == tea.MouseActionRelease {
-		return v, nil
+		if v.dragTaskID == "" {
+			return v, nil
+		}
+		target := v.scrollOffset + m.Y - yOffset - 2
+		from, to := v.dragIndex(v.dragTaskID), v.dragIndex(v.itemTaskID(target))
+		if m.Action == tea.MouseActionMotion {
+			// The cursor follows the drop target while dragging.
+			if to >= 0 {
+				v.cursor = target
+				v.ensureVisible()
+			}
+			return v, nil
+		}
+		taskID := v.dragTaskID
+		v.dragTaskID = ""
+		if from < 0 || to < 0 || from == to {
+			return v, nil
+		}
+		return v, v.repo.ReorderTask(taskID, to-from)
 	}
 
 	// Scroll wheel
@@ -1005,15 +1706,149 @@ func (v TasksView) HandleMouse(m tea.MouseEvent, yOffset int) (TasksView, tea.Cm
 	localY := m.Y - yOffset  // subtract header row
 	itemOffset := localY - 2 // subtract title + padding rows
 	clickedIndex := v.scrollOffset + itemOffset
+	v.dragTaskID = ""
 
-	if clickedIndex >= 0 && clickedIndex < len(v.items) && !v.items[clickedIndex].isSection {
-		v.cursor = clickedIndex
+	if clickedIndex >= 0 && clickedIndex < len(v.items) && !v.skipItem(clickedIndex) {
+		// Clicking a section header folds or unfolds it.
+		if item := v.items[clickedIndex]; item.isSection {
+			v.collapsed[item.section.ID] = !v.collapsed[item.section.ID]
+			v.rebuildItems()
+			v.selectSection(item.section.ID)
+		} else {
+			v.cursor = clickedIndex
+			if item.task != nil && !item.completed && v.inProject() {
+				v.dragTaskID = item.task.ID
+			}
+		}
 		v.ensureVisible()
 	}
 
 	return v, nil
 }
 
+// IsDragging reports whether a task is being dragged with the mouse.
+func (v TasksView) IsDragging() bool {
+	return v.dragTaskID != ""
+}
+
+func (v TasksView) itemTaskID(idx int) string {
+	if idx < 0 || idx >= len(v.items) || v.items[idx].task == nil || v.items[idx].completed {
+		return ""
+	}
+	return v.items[idx].task.ID
+}
+
+// dragIndex returns taskID's position among the dragged task's siblings, or
+// -1 if it is not one of them.
+func (v TasksView) dragIndex(taskID string) int {
+	var dragged *Task
+	for i := range v.tasks {
+		if v.tasks[i].ID == v.dragTaskID {
+			dragged = &v.tasks[i]
+		}
+	}
+	if dragged == nil || taskID == "" {
+		return -1
+	}
+	return slices.IndexFunc(taskSiblings(v.tasks, *dragged), func(t Task) bool { return t.ID == taskID })
+}
+
+// inProject reports whether the view shows a project, where tasks keep their
+// sections and manual order.
+func (v TasksView) inProject() bool {
+	return v.projectID != "" && v.labelName == "" && v.filterID == ""
+}
+
+// isSectionHeader reports whether item is a real section of the current
+// project, as opposed to a label view's project group or a filter group.
+func (v TasksView) isSectionHeader(item displayItem) bool {
+	return item.isSection && item.section.ID != "" && v.inProject()
+}
+
+// skipItem reports whether the cursor passes over items[idx].
+func (v TasksView) skipItem(idx int) bool {
+	return v.items[idx].isSection && !v.isSectionHeader(v.items[idx])
+}
+
+// selectedSection returns the section whose header is under the cursor.
+func (v TasksView) selectedSection() *Section {
+	if v.cursor >= 0 && v.cursor < len(v.items) && v.isSectionHeader(v.items[v.cursor]) {
+		return v.items[v.cursor].section
+	}
+	return nil
+}
+
+func (v *TasksView) selectSection(sectionID string) {
+	for i, item := range v.items {
+		if item.isSection && item.section.ID == sectionID {
+			v.cursor = i
+			return
+		}
+	}
+}
+
+// taskOrder returns the IDs of the view's open tasks in display order, with
+// tasks hidden in folds after the visible ones.
+func (v TasksView) taskOrder() []string {
+	var ids []string
+	for _, item := range v.items {
+		if item.task != nil && !item.completed {
+			ids = append(ids, item.task.ID)
+		}
+	}
+	for _, t := range v.tasks {
+		if !slices.Contains(ids, t.ID) {
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids
+}
+
+// takeTargets returns the tasks an action applies to, the marked ones or
+// else the selected task, and clears the marks.
+func (v *TasksView) takeTargets() []string {
+	ids := v.marks.targets(v.taskOrder(), v.selectedTask())
+	v.marks.clear()
+	return ids
+}
+
+func (v TasksView) tasksByID(ids []string) []Task {
+	var out []Task
+	for _, id := range ids {
+		for _, t := range append(v.tasks, v.completedTasks...) {
+			if t.ID == id {
+				out = append(out, t)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// closeTargets completes the marked tasks with their subtasks. Tasks whose
+// ancestor is also marked close with that ancestor.
+func (v TasksView) closeTargets(ids []string) tea.Cmd {
+	covered := make(map[string]bool)
+	for _, id := range ids {
+		for _, d := range descendantsOf(v.tasks, id) {
+			covered[d.ID] = true
+		}
+	}
+	var roots []string
+	for _, id := range ids {
+		if !covered[id] {
+			roots = append(roots, id)
+		}
+	}
+	return bulkOrSingle(v.repo, "Completed", roots, func(id string) tea.Cmd {
+		var descendants []string
+		for _, d := range descendantsOf(v.tasks, id) {
+			descendants = append(descendants, d.ID)
+		}
+		return v.repo.CloseTaskTree(id, descendants)
+	})
+}
+
 func (v TasksView) selectedTask() *Task {
 	if v.cursor >= 0 && v.cursor < len(v.items) && !v.items[v.cursor].isSection {
 		return v.items[v.cursor].task
@@ -100,6 +100,11 @@ var (
 	taskCheckboxDone = lipgloss.NewStyle().
 				Foreground(colorGreen)
 
+	// Tasks marked for a bulk edit
+	markedCheckStyle = lipgloss.NewStyle().
+				Foreground(colorBlue).
+				Bold(true)
+
 	// Due dates
 	dueTodayStyle = lipgloss.NewStyle().
 			Foreground(colorGreen).
@@ -239,6 +244,9 @@ var (
 	todayProjectTagStyle = lipgloss.NewStyle().Foreground(colorTextDim)
 	todayUpNextStyle     = lipgloss.NewStyle().Foreground(colorTextDim)
 
+	// Subtask tree
+	subtaskCountStyle = lipgloss.NewStyle().Foreground(colorTextDim)
+
 	// Triage / Eisenhower Matrix
 	triageQ1Style       = lipgloss.NewStyle().Foreground(colorP1).Bold(true)
 	triageQ2Style       = lipgloss.NewStyle().Foreground(colorP2).Bold(true)
@@ -29,9 +29,15 @@ type TodayView struct {
 	matchIndices []int
 	currentMatch int
 
-	mode          string // "", "due", "deadline"
+	mode          string // "", "due", "deadline", "labels", "delete"
 	dueInput      textinput.Model
 	deadlineInput textinput.Model
+	labelInput    textinput.Model
+	labelsBefore  []string // labels shared by the targets when the labels dialog opened
+	knownLabels   []Label  // for label autocomplete
+
+	// Tasks marked for a bulk edit
+	marks taskMarks
 }
 
 func NewTodayView(repo *Repository) TodayView {
@@ -47,11 +53,17 @@ func NewTodayView(repo *Repository) TodayView {
 	dli.Placeholder = "YYYY-MM-DD (empty to clear)"
 	dli.CharLimit = 32
 
+	li := textinput.New()
+	li.Placeholder = "urgent work (space-separated)"
+	li.CharLimit = 200
+
 	return TodayView{
 		repo:          repo,
 		searchInput:   si,
 		dueInput:      di,
 		deadlineInput: dli,
+		labelInput:    li,
+		marks:         newTaskMarks(),
 	}
 }
 
@@ -63,8 +75,46 @@ func (v *TodayView) Refresh() {
 	v.matchIndices = nil
 	v.currentMatch = 0
 
-	var overdue, today, upcoming []Task
+	overdue, today, upcoming := todayBuckets(allTasks)
+
+	// Build items
+	v.items = nil
+	v.tasks = nil
+
+	if len(overdue) > 0 {
+		overdueSection := Section{Name: "Overdue"}
+		v.items = append(v.items, displayItem{isSection: true, section: &overdueSection})
+		for i := range overdue {
+			v.items = append(v.items, displayItem{task: &overdue[i]})
+			v.tasks = append(v.tasks, overdue[i])
+		}
+	}
 
+	if len(today) > 0 {
+		todaySection := Section{Name: "Today"}
+		v.items = append(v.items, displayItem{isSection: true, section: &todaySection})
+		for i := range today {
+			v.items = append(v.items, displayItem{task: &today[i]})
+			v.tasks = append(v.tasks, today[i])
+		}
+	}
+
+	if len(upcoming) > 0 {
+		upNextSection := Section{Name: "Up Next"}
+		v.items = append(v.items, displayItem{isSection: true, section: &upNextSection})
+		for i := range upcoming {
+			v.items = append(v.items, displayItem{task: &upcoming[i]})
+			v.tasks = append(v.tasks, upcoming[i])
+		}
+	}
+
+	v.marks.prune(v.taskOrder())
+	v.clampCursor()
+}
+
+// todayBuckets splits tasks into the Today view's overdue, due-today and
+// up-next lists, each sorted for display. Up next keeps the first ten.
+func todayBuckets(allTasks []Task) (overdue, today, upcoming []Task) {
 	for _, task := range allTasks {
 		hasDue := task.Due != nil && task.Due.Date != ""
 		hasDeadline := task.Deadline != nil && task.Deadline.Date != ""
@@ -109,39 +159,7 @@ func (v *TodayView) Refresh() {
 	if len(upcoming) > 10 {
 		upcoming = upcoming[:10]
 	}
-
-	// Build items
-	v.items = nil
-	v.tasks = nil
-
-	if len(overdue) > 0 {
-		overdueSection := Section{Name: "Overdue"}
-		v.items = append(v.items, displayItem{isSection: true, section: &overdueSection})
-		for i := range overdue {
-			v.items = append(v.items, displayItem{task: &overdue[i]})
-			v.tasks = append(v.tasks, overdue[i])
-		}
-	}
-
-	if len(today) > 0 {
-		todaySection := Section{Name: "Today"}
-		v.items = append(v.items, displayItem{isSection: true, section: &todaySection})
-		for i := range today {
-			v.items = append(v.items, displayItem{task: &today[i]})
-			v.tasks = append(v.tasks, today[i])
-		}
-	}
-
-	if len(upcoming) > 0 {
-		upNextSection := Section{Name: "Up Next"}
-		v.items = append(v.items, displayItem{isSection: true, section: &upNextSection})
-		for i := range upcoming {
-			v.items = append(v.items, displayItem{task: &upcoming[i]})
-			v.tasks = append(v.tasks, upcoming[i])
-		}
-	}
-
-	v.clampCursor()
+	return overdue, today, upcoming
 }
 
 func (v TodayView) Update(msg tea.Msg) (TodayView, tea.Cmd) {
@@ -157,9 +175,21 @@ func (v TodayView) Update(msg tea.Msg) (TodayView, tea.Cmd) {
 			return toastMsg{text: "Failed to update task: " + msg.err.Error(), isError: true}
 		}
 
+	case taskMovedMsg:
+		if msg.err != nil {
+			return v, func() tea.Msg {
+				return toastMsg{text: "Failed to move task: " + msg.err.Error(), isError: true}
+			}
+		}
+		v.Refresh()
+		return v, nil
+
 	case taskClosedMsg:
 		if msg.err == nil {
 			v.Refresh()
+			if msg.next != nil {
+				return v, recurringCompletedToast(msg.next)
+			}
 			return v, func() tea.Msg {
 				return toastMsg{text: "Task completed", isError: false}
 			}
@@ -168,6 +198,12 @@ func (v TodayView) Update(msg tea.Msg) (TodayView, tea.Cmd) {
 			return toastMsg{text: "Failed to complete task: " + msg.err.Error(), isError: true}
 		}
 
+	case bulkEditMsg:
+		v.Refresh()
+		return v, func() tea.Msg {
+			return toastMsg{text: fmt.Sprintf("%s %d tasks", msg.verb, msg.count), isError: false}
+		}
+
 	case taskReopenedMsg:
 		if msg.err == nil {
 			v.Refresh()
@@ -202,6 +238,11 @@ func (v TodayView) Update(msg tea.Msg) (TodayView, tea.Cmd) {
 		v.deadlineInput, cmd = v.deadlineInput.Update(msg)
 		return v, cmd
 	}
+	if v.mode == "labels" {
+		var cmd tea.Cmd
+		v.labelInput, cmd = v.labelInput.Update(msg)
+		return v, cmd
+	}
 
 	return v, nil
 }
@@ -212,17 +253,10 @@ func (v TodayView) handleKey(msg tea.KeyMsg) (TodayView, tea.Cmd) {
 		switch ResolveAction(ContextMainTodayDialog, msg.String()) {
 		case ActionConfirm:
 			dueStr := strings.TrimSpace(v.dueInput.Value())
-			item := v.selectedItem()
-			if item == nil || item.task == nil {
-				v.mode = ""
-				return v, nil
-			}
 			v.mode = ""
-			if dueStr == "" {
-				empty := ""
-				return v, v.repo.UpdateTask(item.task.ID, updateTaskRequest{DueString: &empty})
-			}
-			return v, v.repo.UpdateTask(item.task.ID, updateTaskRequest{DueString: &dueStr})
+			return v, bulkOrSingle(v.repo, "Rescheduled", v.takeTargets(), func(id string) tea.Cmd {
+				return v.repo.UpdateTask(id, updateTaskRequest{DueString: &dueStr})
+			})
 		case ActionCancel:
 			v.mode = ""
 			return v, nil
@@ -252,6 +286,44 @@ func (v TodayView) handleKey(msg tea.KeyMsg) (TodayView, tea.Cmd) {
 		var cmd tea.Cmd
 		v.deadlineInput, cmd = v.deadlineInput.Update(msg)
 		return v, cmd
+
+	case "labels":
+		switch ResolveAction(ContextMainTodayDialog, msg.String()) {
+		case ActionCompleteLabel:
+			if matches := labelCompletions(v.labelInput.Value(), v.knownLabels); len(matches) > 0 {
+				v.labelInput.SetValue(completeLabelInput(v.labelInput.Value(), matches[0]))
+				v.labelInput.CursorEnd()
+			}
+			return v, nil
+		case ActionConfirm:
+			v.mode = ""
+			v.labelInput.Blur()
+			before, after := v.labelsBefore, parseLabelInput(v.labelInput.Value())
+			current := make(map[string][]string)
+			for _, t := range v.tasks {
+				current[t.ID] = t.Labels
+			}
+			return v, bulkOrSingle(v.repo, "Relabeled", v.takeTargets(), func(id string) tea.Cmd {
+				return v.repo.UpdateTask(id, updateTaskRequest{Labels: editLabels(current[id], before, after)})
+			})
+		case ActionCancel:
+			v.mode = ""
+			v.labelInput.Blur()
+			return v, nil
+		}
+		var cmd tea.Cmd
+		v.labelInput, cmd = v.labelInput.Update(msg)
+		return v, cmd
+
+	case "delete":
+		switch ResolveAction(ContextMainSidebarDialog, msg.String()) {
+		case ActionConfirm:
+			v.mode = ""
+			return v, bulkOrSingle(v.repo, "Deleted", v.takeTargets(), v.repo.DeleteTask)
+		case ActionCancel:
+			v.mode = ""
+		}
+		return v, nil
 	}
 
 	if v.searchMode {
@@ -283,6 +355,9 @@ func (v TodayView) handleKey(msg tea.KeyMsg) (TodayView, tea.Cmd) {
 	if msg.String() == "N" && v.searchQuery != "" {
 		action = ActionSearchPrev
 	}
+	if msg.String() == " " && v.marks.count() > 0 {
+		action = ActionToggleMark
+	}
 
 	switch action {
 	case ActionSearchLocal:
@@ -315,6 +390,20 @@ func (v TodayView) handleKey(msg tea.KeyMsg) (TodayView, tea.Cmd) {
 			v.searchQuery = ""
 			v.matchIndices = nil
 			v.currentMatch = 0
+		} else {
+			v.marks.clear()
+		}
+		return v, nil
+	case ActionToggleMark:
+		if task := v.selectedTask(); task != nil {
+			v.marks.toggle(task.ID)
+			v.moveDown()
+			v.ensureVisible()
+		}
+		return v, nil
+	case ActionMarkRange:
+		if task := v.selectedTask(); task != nil {
+			v.marks.markRange(v.taskOrder(), task.ID)
 		}
 		return v, nil
 	case ActionNavDown:
@@ -332,7 +421,53 @@ func (v TodayView) handleKey(msg tea.KeyMsg) (TodayView, tea.Cmd) {
 		listJumpBottom(&v.cursor, len(v.items), func(idx int) bool { return v.items[idx].isSection })
 		v.ensureVisible()
 		return v, nil
+	case ActionOpenDetail:
+		if item := v.selectedItem(); item != nil && item.task != nil {
+			t := *item.task
+			return v, func() tea.Msg { return openTaskDetailMsg{task: t} }
+		}
+		return v, nil
+	case ActionMoveTask:
+		if tasks := v.tasksByID(v.takeTargets()); len(tasks) > 0 {
+			return v, func() tea.Msg { return openMovePickerMsg{tasks: tasks} }
+		}
+		return v, nil
+	case ActionDeleteTask:
+		if v.selectedTask() != nil || v.marks.count() > 0 {
+			v.mode = "delete"
+		}
+		return v, nil
+	case ActionCopyJSON:
+		return v, copyTasksJSON(v.repo, v.tasksByID(v.marks.targets(v.taskOrder(), v.selectedTask())))
+	case ActionSetLabels:
+		tasks := v.tasksByID(v.marks.targets(v.taskOrder(), v.selectedTask()))
+		if len(tasks) > 0 {
+			v.mode = "labels"
+			v.knownLabels = v.repo.GetCachedLabels()
+			v.labelsBefore = commonLabels(tasks)
+			v.labelInput.SetValue(strings.Join(v.labelsBefore, " "))
+			v.labelInput.CursorEnd()
+			v.labelInput.Focus()
+			return v, textinput.Blink
+		}
+		return v, nil
+	case ActionSetPriority1, ActionSetPriority2, ActionSetPriority3, ActionSetPriority4:
+		p := 1
+		switch action {
+		case ActionSetPriority2:
+			p = 2
+		case ActionSetPriority3:
+			p = 3
+		case ActionSetPriority4:
+			p = 4
+		}
+		return v, bulkOrSingle(v.repo, "Reprioritized", v.takeTargets(), func(id string) tea.Cmd {
+			return v.repo.UpdateTask(id, updateTaskRequest{Priority: &p})
+		})
 	case ActionToggleDone:
+		if v.marks.count() > 0 {
+			return v, bulkOrSingle(v.repo, "Completed", v.takeTargets(), v.repo.CloseTask)
+		}
 		item := v.selectedItem()
 		if item != nil && item.task != nil {
 			if item.completed {
@@ -342,6 +477,12 @@ func (v TodayView) handleKey(msg tea.KeyMsg) (TodayView, tea.Cmd) {
 		}
 	case ActionSetDue:
 		item := v.selectedItem()
+		if v.marks.count() > 0 {
+			v.mode = "due"
+			v.dueInput.Reset()
+			v.dueInput.Focus()
+			return v, textinput.Blink
+		}
 		if item != nil && item.task != nil {
 			v.mode = "due"
 			v.dueInput.Reset()
@@ -405,6 +546,9 @@ func (v TodayView) View() string {
 		Bold(true).
 		Padding(0, 0, 1, 0).
 		Render("Today")
+	if n := v.marks.count(); n > 0 {
+		title = lipgloss.JoinHorizontal(lipgloss.Top, title, "  ", markedCheckStyle.Render(fmt.Sprintf("%d marked", n)))
+	}
 	b.WriteString(title)
 	b.WriteString("\n")
 
@@ -469,6 +613,9 @@ func (v TodayView) renderTask(task *Task, selected bool, faded bool, syncStatus
 
 	if selected {
 		check := "○"
+		if v.marks.has(task.ID) {
+			check = markedCheck
+		}
 		content := truncate(task.Content, maxContentWidth)
 		if v.searchQuery != "" {
 			content = highlightMatchPlain(content, v.searchQuery)
@@ -509,7 +656,11 @@ func (v TodayView) renderTask(task *Task, selected bool, faded bool, syncStatus
 
 	// Non-selected
 	var parts []string
-	parts = append(parts, styledCheckbox(false, task.Priority))
+	if v.marks.has(task.ID) {
+		parts = append(parts, markedCheckStyle.Render(markedCheck))
+	} else {
+		parts = append(parts, styledCheckbox(false, task.Priority))
+	}
 
 	content := truncate(task.Content, maxContentWidth)
 	if v.searchQuery != "" {
@@ -591,6 +742,7 @@ func (v *TodayView) SetSize(width, height int) {
 	v.searchInput.Width = 30
 	v.dueInput.Width = width - 8
 	v.deadlineInput.Width = width - 8
+	v.labelInput.Width = width - 8
 }
 
 func (v *TodayView) SetFocused(focused bool) {
@@ -623,6 +775,28 @@ func (v TodayView) renderDialog() string {
 				inputLabelStyle.Render("YYYY-MM-DD (empty to clear)") + "\n" +
 				v.deadlineInput.View(),
 		)
+	case "labels":
+		title := "Set Labels"
+		if n := v.marks.count(); n > 1 {
+			title = fmt.Sprintf("Set Labels on %d Tasks", n)
+		}
+		return dialogStyle.Width(v.width - 4).Render(
+			dialogTitleStyle.Render(title) + "\n" +
+				inputLabelStyle.Render("space-separated, tab to complete") + "\n" +
+				v.labelInput.View(),
+		)
+	case "delete":
+		targets := v.tasksByID(v.marks.targets(v.taskOrder(), v.selectedTask()))
+		title, name := fmt.Sprintf("Delete %d Tasks?", len(targets)), ""
+		if len(targets) == 1 {
+			title, name = "Delete Task?", "\""+truncate(targets[0].Content, 60)+"\"\n"
+		}
+		return dialogStyle.Width(v.width - 4).Render(
+			dialogTitleStyle.Render(title) + "\n" +
+				taskContentStyle.Render(name) + "\n" +
+				footerKeyStyle.Render("y") + " confirm  " +
+				footerKeyStyle.Render("n") + " cancel",
+		)
 	default:
 		return ""
 	}
@@ -691,6 +865,43 @@ func (v TodayView) HandleMouse(m tea.MouseEvent, yOffset int) (TodayView, tea.Cm
 	return v, nil
 }
 
+// taskOrder returns the IDs of the listed tasks in display order.
+func (v TodayView) taskOrder() []string {
+	ids := make([]string, len(v.tasks))
+	for i, t := range v.tasks {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// takeTargets returns the tasks an action applies to, the marked ones or
+// else the selected task, and clears the marks.
+func (v *TodayView) takeTargets() []string {
+	ids := v.marks.targets(v.taskOrder(), v.selectedTask())
+	v.marks.clear()
+	return ids
+}
+
+func (v TodayView) tasksByID(ids []string) []Task {
+	var out []Task
+	for _, id := range ids {
+		for _, t := range v.tasks {
+			if t.ID == id {
+				out = append(out, t)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func (v TodayView) selectedTask() *Task {
+	if item := v.selectedItem(); item != nil {
+		return item.task
+	}
+	return nil
+}
+
 func (v TodayView) selectedItem() *displayItem {
 	if v.cursor >= 0 && v.cursor < len(v.items) && !v.items[v.cursor].isSection {
 		return &v.items[v.cursor]
@@ -35,6 +35,11 @@ type TriageView struct {
 	editInput     textinput.Model
 	quickInput    textinput.Model
 	labelInput    textinput.Model
+	labelsBefore  []string // labels shared by the targets when the label dialog opened
+	knownLabels   []Label  // for label autocomplete
+
+	// Tasks marked for a bulk edit
+	marks taskMarks
 }
 
 type triageItem struct {
@@ -81,6 +86,7 @@ func NewTriageView(repo *Repository) TriageView {
 		editInput:     ei,
 		quickInput:    qi,
 		labelInput:    li,
+		marks:         newTaskMarks(),
 	}
 }
 
@@ -89,6 +95,7 @@ func (v *TriageView) Open() {
 	v.projectNames = v.repo.GetProjectNameMap()
 	v.reviewed = make(map[string]bool)
 	v.changes = triageStats{}
+	v.marks.clear()
 	v.mode = ""
 	v.cursor = 0
 	v.viewport.YOffset = 0
@@ -117,6 +124,12 @@ func (v TriageView) handlesInput() bool {
 	return v.mode != ""
 }
 
+// HasMarks reports whether tasks are marked, so esc clears the marks
+// before it closes triage.
+func (v TriageView) HasMarks() bool {
+	return v.marks.count() > 0
+}
+
 // --- Update ---
 
 func (v TriageView) Update(msg tea.Msg) (TriageView, tea.Cmd) {
@@ -136,10 +149,15 @@ func (v TriageView) Update(msg tea.Msg) (TriageView, tea.Cmd) {
 	case taskClosedMsg:
 		if msg.err == nil {
 			for i, t := range v.allTasks {
-				if t.ID == msg.taskID {
+				if t.ID != msg.taskID {
+					continue
+				}
+				if msg.next != nil {
+					v.allTasks[i] = *msg.next
+				} else {
 					v.allTasks = append(v.allTasks[:i], v.allTasks[i+1:]...)
-					break
 				}
+				break
 			}
 			v.rebuildItems()
 			v.clampCursor()
@@ -166,6 +184,15 @@ func (v TriageView) Update(msg tea.Msg) (TriageView, tea.Cmd) {
 		}
 		return v, nil
 
+	case bulkEditMsg:
+		v.allTasks = v.repo.GetAllCachedTasks()
+		v.rebuildItems()
+		v.marks.prune(v.taskOrder())
+		v.clampCursor()
+		return v, func() tea.Msg {
+			return toastMsg{text: fmt.Sprintf("%s %d tasks", msg.verb, msg.count), isError: false}
+		}
+
 	case quickAddMsg:
 		if msg.err == nil {
 			v.changes.added++
@@ -256,19 +283,12 @@ func (v TriageView) handleKey(msg tea.KeyMsg) (TriageView, tea.Cmd) {
 		switch ResolveAction(ContextTriageDialog, msg.String()) {
 		case ActionConfirm:
 			dueStr := strings.TrimSpace(v.dueInput.Value())
-			task := v.selectedTask()
-			if task == nil {
-				v.mode = ""
-				return v, nil
-			}
 			v.mode = ""
-			v.reviewed[task.ID] = true
-			v.changes.rescheduled++
-			if dueStr == "" {
-				empty := ""
-				return v, v.repo.UpdateTask(task.ID, updateTaskRequest{DueString: &empty})
-			}
-			return v, v.repo.UpdateTask(task.ID, updateTaskRequest{DueString: &dueStr})
+			ids := v.takeTargets()
+			v.changes.rescheduled += len(ids)
+			return v, bulkOrSingle(v.repo, "Rescheduled", ids, func(id string) tea.Cmd {
+				return v.repo.UpdateTask(id, updateTaskRequest{DueString: &dueStr})
+			})
 		case ActionCancel:
 			v.mode = ""
 			return v, nil
@@ -303,24 +323,24 @@ func (v TriageView) handleKey(msg tea.KeyMsg) (TriageView, tea.Cmd) {
 
 	case "label":
 		switch ResolveAction(ContextTriageDialog, msg.String()) {
-		case ActionConfirm:
-			labelStr := strings.TrimSpace(v.labelInput.Value())
-			task := v.selectedTask()
-			if task == nil {
-				v.mode = ""
-				return v, nil
+		case ActionCompleteLabel:
+			if matches := labelCompletions(v.labelInput.Value(), v.knownLabels); len(matches) > 0 {
+				v.labelInput.SetValue(completeLabelInput(v.labelInput.Value(), matches[0]))
+				v.labelInput.CursorEnd()
 			}
+			return v, nil
+		case ActionConfirm:
 			v.mode = ""
-			v.reviewed[task.ID] = true
-			v.changes.labeled++
-			// Parse labels: split on spaces, strip @ prefix
-			var labels []string
-			if labelStr != "" {
-				for _, l := range strings.Fields(labelStr) {
-					labels = append(labels, strings.TrimPrefix(l, "@"))
-				}
+			before, after := v.labelsBefore, parseLabelInput(v.labelInput.Value())
+			current := make(map[string][]string)
+			for _, t := range v.allTasks {
+				current[t.ID] = t.Labels
 			}
-			return v, v.repo.UpdateTask(task.ID, updateTaskRequest{Labels: labels})
+			ids := v.takeTargets()
+			v.changes.labeled += len(ids)
+			return v, bulkOrSingle(v.repo, "Relabeled", ids, func(id string) tea.Cmd {
+				return v.repo.UpdateTask(id, updateTaskRequest{Labels: editLabels(current[id], before, after)})
+			})
 		case ActionCancel:
 			v.mode = ""
 			return v, nil
@@ -332,15 +352,10 @@ func (v TriageView) handleKey(msg tea.KeyMsg) (TriageView, tea.Cmd) {
 	case "delete":
 		switch ResolveAction(ContextMainSidebarDialog, msg.String()) {
 		case ActionConfirm:
-			task := v.selectedTask()
-			if task != nil {
-				v.reviewed[task.ID] = true
-				v.changes.deleted++
-				v.mode = ""
-				return v, v.repo.DeleteTask(task.ID)
-			}
 			v.mode = ""
-			return v, nil
+			ids := v.takeTargets()
+			v.changes.deleted += len(ids)
+			return v, bulkOrSingle(v.repo, "Deleted", ids, v.repo.DeleteTask)
 		case ActionCancel:
 			v.mode = ""
 			return v, nil
@@ -349,7 +364,28 @@ func (v TriageView) handleKey(msg tea.KeyMsg) (TriageView, tea.Cmd) {
 	}
 
 	// Normal mode
-	switch ResolveAction(ContextTriageOverlay, msg.String()) {
+	action := ResolveAction(ContextTriageOverlay, msg.String())
+	if msg.String() == " " && v.marks.count() > 0 {
+		action = ActionToggleMark
+	}
+	switch action {
+	case ActionCancel:
+		if v.marks.count() > 0 {
+			v.marks.clear()
+			return v, nil
+		}
+	case ActionToggleMark:
+		if task := v.selectedTask(); task != nil {
+			v.marks.toggle(task.ID)
+			v.moveDown()
+			v.ensureVisible()
+		}
+		return v, nil
+	case ActionMarkRange:
+		if task := v.selectedTask(); task != nil {
+			v.marks.markRange(v.taskOrder(), task.ID)
+		}
+		return v, nil
 	case ActionNavDown:
 		v.moveDown()
 		v.ensureVisible()
@@ -378,6 +414,13 @@ func (v TriageView) handleKey(msg tea.KeyMsg) (TriageView, tea.Cmd) {
 	case ActionClearPriority:
 		return v.setPriority(4) // clear priority
 
+	case ActionOpenDetail:
+		if task := v.selectedTask(); task != nil {
+			t := *task
+			return v, func() tea.Msg { return openTaskDetailMsg{task: t} }
+		}
+		return v, nil
+
 	// Skip / mark reviewed
 	case ActionMarkReviewed:
 		task := v.selectedTask()
@@ -389,18 +432,13 @@ func (v TriageView) handleKey(msg tea.KeyMsg) (TriageView, tea.Cmd) {
 
 	// Complete task
 	case ActionToggleDone:
-		task := v.selectedTask()
-		if task != nil {
-			v.reviewed[task.ID] = true
-			v.changes.completed++
-			return v, v.repo.CloseTask(task.ID)
-		}
-		return v, nil
+		ids := v.takeTargets()
+		v.changes.completed += len(ids)
+		return v, bulkOrSingle(v.repo, "Completed", ids, v.repo.CloseTask)
 
 	// Delete task
 	case ActionDeleteTask:
-		task := v.selectedTask()
-		if task != nil {
+		if v.selectedTask() != nil || v.marks.count() > 0 {
 			v.mode = "delete"
 		}
 		return v, nil
@@ -408,6 +446,12 @@ func (v TriageView) handleKey(msg tea.KeyMsg) (TriageView, tea.Cmd) {
 	// Set due date
 	case ActionSetDue:
 		task := v.selectedTask()
+		if v.marks.count() > 0 {
+			v.mode = "due"
+			v.dueInput.Reset()
+			v.dueInput.Focus()
+			return v, textinput.Blink
+		}
 		if task != nil {
 			v.mode = "due"
 			v.dueInput.Reset()
@@ -467,13 +511,13 @@ func (v TriageView) handleKey(msg tea.KeyMsg) (TriageView, tea.Cmd) {
 
 	// Labels
 	case ActionSetLabels:
-		task := v.selectedTask()
-		if task != nil {
+		tasks := v.tasksByID(v.marks.targets(v.taskOrder(), v.selectedTask()))
+		if len(tasks) > 0 {
 			v.mode = "label"
+			v.knownLabels = v.repo.GetCachedLabels()
+			v.labelsBefore = commonLabels(tasks)
 			v.labelInput.Reset()
-			if len(task.Labels) > 0 {
-				v.labelInput.SetValue(strings.Join(task.Labels, " "))
-			}
+			v.labelInput.SetValue(strings.Join(v.labelsBefore, " "))
 			v.labelInput.Focus()
 			return v, textinput.Blink
 		}
@@ -491,13 +535,11 @@ func (v TriageView) handleKey(msg tea.KeyMsg) (TriageView, tea.Cmd) {
 }
 
 func (v *TriageView) setPriority(p int) (TriageView, tea.Cmd) {
-	task := v.selectedTask()
-	if task == nil {
-		return *v, nil
-	}
-	v.reviewed[task.ID] = true
-	v.changes.prioritized++
-	cmd := v.repo.UpdateTask(task.ID, updateTaskRequest{Priority: &p})
+	ids := v.takeTargets()
+	v.changes.prioritized += len(ids)
+	cmd := bulkOrSingle(v.repo, "Sorted", ids, func(id string) tea.Cmd {
+		return v.repo.UpdateTask(id, updateTaskRequest{Priority: &p})
+	})
 	return *v, cmd
 }
 
@@ -677,6 +719,9 @@ func (v TriageView) renderTask(task *Task, selected bool, maxW int, syncStatus M
 
 	if selected {
 		check := "○"
+		if v.marks.has(task.ID) {
+			check = markedCheck
+		}
 		content := truncate(task.Content, maxContentWidth)
 		var parts []string
 		parts = append(parts, reviewMark, check, content)
@@ -722,7 +767,11 @@ func (v TriageView) renderTask(task *Task, selected bool, maxW int, syncStatus M
 	// Non-selected
 	var parts []string
 	parts = append(parts, reviewMark)
-	parts = append(parts, styledCheckbox(false, task.Priority))
+	if v.marks.has(task.ID) {
+		parts = append(parts, markedCheckStyle.Render(markedCheck))
+	} else {
+		parts = append(parts, styledCheckbox(false, task.Priority))
+	}
 	parts = append(parts, taskContentStyle.Render(truncate(task.Content, maxContentWidth)))
 
 	if projectName != "" {
@@ -810,16 +859,28 @@ func (v TriageView) renderDialog(width int) string {
 				v.deadlineInput.View(),
 		)
 	case "label":
+		title := "Set Labels"
+		if n := v.marks.count(); n > 1 {
+			title = fmt.Sprintf("Set Labels on %d Tasks", n)
+		}
 		return dialogStyle.Width(dialogW).Render(
-			dialogTitleStyle.Render("Set Labels") + "\n" +
+			dialogTitleStyle.Render(title) + "\n" +
 				inputLabelStyle.Render("Space-separated labels (e.g. urgent work), empty to clear") + "\n" +
-				v.labelInput.View(),
+				v.labelInput.View() + "\n" +
+				renderLabelHints(v.labelInput.Value(), v.knownLabels),
 		)
 	case "delete":
-		task := v.selectedTask()
+		targets := v.tasksByID(v.marks.targets(v.taskOrder(), v.selectedTask()))
+		if len(targets) > 1 {
+			return dialogStyle.Width(dialogW).Render(
+				dialogTitleStyle.Render(fmt.Sprintf("Delete %d Tasks?", len(targets))) + "\n\n" +
+					footerKeyStyle.Render("y") + " confirm  " +
+					footerKeyStyle.Render("n") + " cancel",
+			)
+		}
 		name := ""
-		if task != nil {
-			name = task.Content
+		if len(targets) == 1 {
+			name = targets[0].Content
 		}
 		return dialogStyle.Width(dialogW).Render(
 			dialogTitleStyle.Render("Delete Task?") + "\n" +
@@ -859,6 +920,9 @@ func (v TriageView) renderFooter() string {
 	}
 
 	var footer string
+	if n := v.marks.count(); n > 0 {
+		stats = append(stats, markedCheckStyle.Render(fmt.Sprintf("%d marked", n)))
+	}
 	if len(stats) > 0 {
 		footer = triageStatStyle.Render(strings.Join(stats, " · ")) + "\n"
 	}
@@ -875,7 +939,9 @@ func (v TriageView) renderFooter() string {
 		keyHint("x", "done") + "  " +
 		keyHint("d", "del") + "  " +
 		keyHint("n", "new") + "  " +
-		keyHint("enter", "skip") + "  " +
+		keyHint("space", "skip") + "  " +
+		keyHint("v/V", "mark") + "  " +
+		keyHint("enter", "details") + "  " +
 		keyHint("T", "close")
 
 	return footer
@@ -1039,6 +1105,41 @@ func (v *TriageView) advanceToNextUnreviewed() {
 	v.ensureVisible()
 }
 
+// taskOrder returns the IDs of the listed tasks in display order.
+func (v TriageView) taskOrder() []string {
+	var ids []string
+	for _, item := range v.items {
+		if item.task != nil {
+			ids = append(ids, item.task.ID)
+		}
+	}
+	return ids
+}
+
+// takeTargets returns the tasks an action applies to, the marked ones or
+// else the selected task, marks them reviewed and clears the marks.
+func (v *TriageView) takeTargets() []string {
+	ids := v.marks.targets(v.taskOrder(), v.selectedTask())
+	for _, id := range ids {
+		v.reviewed[id] = true
+	}
+	v.marks.clear()
+	return ids
+}
+
+func (v TriageView) tasksByID(ids []string) []Task {
+	var out []Task
+	for _, id := range ids {
+		for _, t := range v.allTasks {
+			if t.ID == id {
+				out = append(out, t)
+				break
+			}
+		}
+	}
+	return out
+}
+
 func (v TriageView) selectedTask() *Task {
 	if v.cursor >= 0 && v.cursor < len(v.items) && !v.items[v.cursor].isSection {
 		return v.items[v.cursor].task
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -45,6 +46,10 @@ type Due struct {
 	String      string  `json:"string"`
 	Lang        string  `json:"lang"`
 	IsRecurring bool    `json:"is_recurring"`
+	// AnchorDay is the day of month a monthly or yearly recurrence falls on,
+	// kept locally so a date clamped to a short month doesn't drift (31st →
+	// Feb 28 → Mar 31). The server doesn't send it.
+	AnchorDay int `json:"anchor_day,omitempty"`
 }
 
 // Deadline represents a task deadline date (non-recurring date only).
@@ -83,6 +88,19 @@ type Label struct {
 	Color      string `json:"color"`
 	Order      int    `json:"order"`
 	IsFavorite bool   `json:"is_favorite"`
+	IsDeleted  bool   `json:"is_deleted"`
+}
+
+// SavedFilter represents a Todoist saved filter. Query uses the filter
+// language ParseFilter understands.
+type SavedFilter struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Query      string `json:"query"`
+	Color      string `json:"color"`
+	ItemOrder  int    `json:"item_order"`
+	IsFavorite bool   `json:"is_favorite"`
+	IsDeleted  bool   `json:"is_deleted"`
 }
 
 // Comment represents a Todoist comment
@@ -92,6 +110,7 @@ type Comment struct {
 	ProjectID *string `json:"project_id"`
 	Content   string  `json:"content"`
 	PostedAt  string  `json:"posted_at"`
+	IsDeleted bool    `json:"is_deleted"`
 }
 
 // --- Mutation types ---
@@ -105,8 +124,46 @@ const (
 	MutationDelete   MutationAction = "delete"
 	MutationReopen   MutationAction = "reopen"
 	MutationQuickAdd MutationAction = "quick_add"
+	MutationMove     MutationAction = "move"
+	MutationReorder  MutationAction = "reorder"
+
+	MutationAddComment    MutationAction = "add_comment"
+	MutationUpdateComment MutationAction = "update_comment"
+	MutationDeleteComment MutationAction = "delete_comment"
+
+	MutationCreateLabel MutationAction = "create_label"
+	MutationUpdateLabel MutationAction = "update_label"
+	MutationDeleteLabel MutationAction = "delete_label"
+
+	MutationCreateFilter   MutationAction = "create_filter"
+	MutationUpdateFilter   MutationAction = "update_filter"
+	MutationDeleteFilter   MutationAction = "delete_filter"
+	MutationReorderFilters MutationAction = "reorder_filters"
+
+	MutationMoveProject     MutationAction = "move_project"
+	MutationReorderProjects MutationAction = "reorder_projects"
+
+	MutationCreateSection   MutationAction = "create_section"
+	MutationRenameSection   MutationAction = "rename_section"
+	MutationArchiveSection  MutationAction = "archive_section"
+	MutationDeleteSection   MutationAction = "delete_section"
+	MutationReorderSections MutationAction = "reorder_sections"
 )
 
+// isCreateAction reports whether a mutation introduces a new entity under a
+// pending ID that later mutations may depend on.
+func isCreateAction(a MutationAction) bool {
+	return a == MutationCreate || a == MutationQuickAdd || a == MutationAddComment ||
+		a == MutationCreateLabel || a == MutationCreateFilter || a == MutationCreateSection
+}
+
+// isUpdateAction reports whether a mutation edits an existing entity in place,
+// so a 404 from the server means the edit was lost rather than already applied.
+func isUpdateAction(a MutationAction) bool {
+	return a == MutationUpdate || a == MutationUpdateComment || a == MutationUpdateLabel ||
+		a == MutationUpdateFilter || a == MutationRenameSection
+}
+
 type MutationStatus string
 
 const (
@@ -123,9 +180,38 @@ type Mutation struct {
 	Payload    string // JSON of the request (createTaskRequest or updateTaskRequest)
 	Snapshot   string // JSON of entity state at mutation time (empty for creates)
 	Status     MutationStatus
-	Conflict   string // JSON description of conflict (empty if none)
+	Conflict   string // JSON array of ConflictRecord (empty if none)
 	CreatedAt  time.Time
 	Attempts   int
+	UUID       string // Sync command UUID, stable across retries
+	DependsOn  int64  // ID of the create mutation this one waits on (0 if none)
+	GroupID    string // Bulk edit this mutation belongs to (empty if none)
+	Remote     string // JSON of the server's copy when a conflict was detected
+}
+
+// ConflictRecord is one reason a queued mutation could not apply. A field
+// conflict carries the field's base, local and remote values; any other
+// conflict carries a Reason. Mutation.Conflict stores a JSON array of them.
+type ConflictRecord struct {
+	Field      string    `json:"field,omitempty"`
+	Base       string    `json:"base,omitempty"`
+	Local      string    `json:"local,omitempty"`
+	Remote     string    `json:"remote,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	DetectedAt time.Time `json:"detected_at"`
+	HTTPStatus int       `json:"http_status,omitempty"`
+}
+
+// Conflicts decodes the mutation's conflict records.
+func (m Mutation) Conflicts() []ConflictRecord {
+	if m.Conflict == "" {
+		return nil
+	}
+	var records []ConflictRecord
+	if err := json.Unmarshal([]byte(m.Conflict), &records); err != nil {
+		return legacyConflictRecords(m.Conflict, m.CreatedAt)
+	}
+	return records
 }
 
 // NewPendingID returns a temporary ID for optimistically created entities.
@@ -141,29 +227,16 @@ func IsPendingID(id string) bool {
 // --- Message types for async Bubbletea commands ---
 
 type projectsMsg struct {
-	projects   []Project
-	err        error
-	fromCache  bool
-	stale      bool
-	lastSynced *time.Time
+	projects []Project
+	err      error
 }
 
-type tasksMsg struct {
-	projectID  string
-	tasks      []Task
-	err        error
-	fromCache  bool
-	stale      bool
+// syncDoneMsg reports the outcome of a Sync API round-trip. The cache has
+// already been updated; views reload from it.
+type syncDoneMsg struct {
+	fullSync   bool
 	lastSynced *time.Time
-}
-
-type sectionsMsg struct {
-	projectID  string
-	sections   []Section
 	err        error
-	fromCache  bool
-	stale      bool
-	lastSynced *time.Time
 }
 
 type labelsMsg struct {
@@ -172,8 +245,10 @@ type labelsMsg struct {
 }
 
 type taskClosedMsg struct {
-	taskID string
-	err    error
+	taskID     string
+	subtaskIDs []string // descendants closed along with the task
+	next       *Task    // set when a recurring task advanced to its next occurrence
+	err        error
 }
 
 type taskReopenedMsg struct {
@@ -191,6 +266,17 @@ type taskCreatedMsg struct {
 	err  error
 }
 
+type taskMovedMsg struct {
+	task Task
+	err  error
+}
+
+// bulkEditMsg reports a bulk edit queued across the marked tasks.
+type bulkEditMsg struct {
+	verb  string
+	count int
+}
+
 type taskUpdatedMsg struct {
 	task Task
 	err  error
@@ -202,53 +288,87 @@ type quickAddMsg struct {
 	projectID string
 }
 
-type cachedProjectsMsg struct {
-	projects   []Project
-	stale      bool
-	lastSynced *time.Time
+// conflictResolvedMsg reports a conflicted update requeued with the values
+// picked in the queue's resolution dialog.
+type conflictResolvedMsg struct{ err error }
+
+type noopMsg struct{}
+
+type mutationEnqueuedMsg struct{ count int }
+
+// flushDoneMsg reports the outcome of one batched flush of the mutation queue.
+type flushDoneMsg struct {
+	flushed   int
+	conflicts int
+	err       error
+	created   map[int64]string // mutation ID -> server ID of each create that flushed
 }
 
-type cachedTasksMsg struct {
-	projectID  string
-	tasks      []Task
-	stale      bool
-	lastSynced *time.Time
+// addCreated records the server ID a queued create was given.
+func (d *flushDoneMsg) addCreated(mutationID int64, serverID string) {
+	if d.created == nil {
+		d.created = make(map[int64]string)
+	}
+	d.created[mutationID] = serverID
 }
 
-type cachedSectionsMsg struct {
-	projectID  string
-	sections   []Section
-	stale      bool
-	lastSynced *time.Time
+type flushNextMsg struct{}
+
+type assigneeDirectoryMsg struct {
+	updated int
+	err     error
 }
 
-type noopMsg struct{}
+type commentAddedMsg struct {
+	comment Comment
+}
 
-type mutationEnqueuedMsg struct{ count int }
-type mutationFlushedMsg struct {
-	mutation Mutation
-	err      error
+type commentUpdatedMsg struct {
+	comment Comment
 }
-type mutationConflictMsg struct {
-	mutation Mutation
-	conflict string
+
+type commentDeletedMsg struct {
+	commentID string
 }
-type flushNextMsg struct{}
 
-type backgroundRefreshMsg struct {
-	staleProjects []string
+// openMovePickerMsg asks the app to open the project and section picker for
+// moving tasks: the marked ones, or else the selected one.
+type openMovePickerMsg struct {
+	tasks []Task
 }
-type backgroundRefreshDoneMsg struct {
-	remaining []string
+
+// openTaskDetailMsg asks the app to show the detail pane for a task.
+type openTaskDetailMsg struct {
+	task Task
 }
 
-type assigneeDirectoryMsg struct {
-	updated int
+type labelCreatedMsg struct {
+	label Label
+	err   error
+}
+
+type labelUpdatedMsg struct {
+	label Label
+	err   error
+}
+
+type labelDeletedMsg struct {
+	labelID string
 	err     error
 }
 
-type commentsMsg struct {
-	comments []Comment
+type filterCreatedMsg struct {
+	filter SavedFilter
+	err    error
+}
+
+type filterUpdatedMsg struct {
+	filter SavedFilter
+	err    error
+}
+
+type filterDeletedMsg struct {
+	filterID string
 	err      error
 }
 
@@ -257,6 +377,18 @@ type projectCreatedMsg struct {
 	err     error
 }
 
+type projectMovedMsg struct {
+	project Project
+	err     error
+}
+
+// sectionUpdatedMsg reports a queued section create, rename, archive, delete
+// or reorder.
+type sectionUpdatedMsg struct {
+	section Section
+	err     error
+}
+
 type projectArchivedMsg struct {
 	projectID string
 	err       error
@@ -275,6 +407,13 @@ type CompletedTaskRow struct {
 	CompletedAt time.Time
 }
 
+// CompletionRecord is one completed occurrence of a recurring task.
+type CompletionRecord struct {
+	TaskID      string
+	DueDate     string
+	CompletedAt time.Time
+}
+
 type toastMsg struct {
 	text    string
 	isError bool
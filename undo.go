@@ -0,0 +1,448 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+)
+
+// undoEntry is one user action: the mutations it queued, oldest first.
+type undoEntry struct {
+	mutations []Mutation
+}
+
+// undoHistory holds the session's undo and redo stacks. Entries keep copies
+// of their mutations, so they can be reverted after the queue has flushed.
+type undoHistory struct {
+	mu   sync.Mutex
+	undo []undoEntry
+	redo []undoEntry
+
+	// aliases maps task IDs that undo re-created under a new ID.
+	aliases map[string]string
+}
+
+// maxUndoEntries bounds the undo stack for long sessions.
+const maxUndoEntries = 100
+
+// record adds a queued mutation as a new user action, which clears redo.
+func (h *undoHistory) record(m Mutation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.undo = append(h.undo, undoEntry{mutations: []Mutation{m}})
+	if len(h.undo) > maxUndoEntries {
+		h.undo = h.undo[len(h.undo)-maxUndoEntries:]
+	}
+	h.redo = nil
+}
+
+// mergeAfter folds every entry recorded after mutation afterID into one,
+// so a bulk edit or subtree close is undone as a unit.
+func (h *undoHistory) mergeAfter(afterID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := len(h.undo)
+	for i > 0 && h.undo[i-1].mutations[0].ID > afterID {
+		i--
+	}
+	if len(h.undo)-i < 2 {
+		return
+	}
+	var merged undoEntry
+	for _, e := range h.undo[i:] {
+		merged.mutations = append(merged.mutations, e.mutations...)
+	}
+	h.undo = append(h.undo[:i], merged)
+}
+
+// remap follows a temporary ID to its server ID across both stacks.
+func (h *undoHistory) remap(tempID, realID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, stack := range [][]undoEntry{h.undo, h.redo} {
+		for _, e := range stack {
+			for i, m := range e.mutations {
+				e.mutations[i] = remapMutation(m, tempID, realID)
+			}
+		}
+	}
+	for id, alias := range h.aliases {
+		if alias == tempID {
+			h.aliases[id] = realID
+		}
+	}
+}
+
+// forget drops a mutation dismissed from the queue; it never reached the
+// server, so there is nothing left to undo for it.
+func (h *undoHistory) forget(id int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, stack := range []*[]undoEntry{&h.undo, &h.redo} {
+		kept := (*stack)[:0]
+		for _, e := range *stack {
+			e.mutations = slices.DeleteFunc(e.mutations, func(m Mutation) bool { return m.ID == id })
+			if len(e.mutations) > 0 {
+				kept = append(kept, e)
+			}
+		}
+		*stack = kept
+	}
+}
+
+func (h *undoHistory) alias(oldID, newID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.aliases == nil {
+		h.aliases = make(map[string]string)
+	}
+	h.aliases[oldID] = newID
+}
+
+// resolve rewrites the IDs of tasks undo has re-created inside m.
+func (h *undoHistory) resolve(m Mutation) Mutation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for oldID, newID := range h.aliases {
+		m = remapMutation(m, oldID, newID)
+	}
+	return m
+}
+
+// undoneMsg reports the outcome of an undo or redo.
+type undoneMsg struct {
+	desc string
+	redo bool
+	err  error
+}
+
+// Undo reverts the last user action. Mutations still in the queue are dropped
+// and their snapshots restored; ones that already synced are reverted by
+// queueing their inverse.
+func (r *Repository) Undo() tea.Cmd {
+	return func() tea.Msg {
+		return r.runUndo(false)
+	}
+}
+
+// Redo re-applies the last undone action.
+func (r *Repository) Redo() tea.Cmd {
+	return func() tea.Msg {
+		return r.runUndo(true)
+	}
+}
+
+func (r *Repository) runUndo(redo bool) tea.Msg {
+	if r.store == nil {
+		return undoneMsg{redo: redo, err: fmt.Errorf("no cache")}
+	}
+	r.undoMu.Lock()
+	defer r.undoMu.Unlock()
+
+	h := &r.history
+	h.mu.Lock()
+	from, to := &h.undo, &h.redo
+	if redo {
+		from, to = to, from
+	}
+	if len(*from) == 0 {
+		h.mu.Unlock()
+		if redo {
+			return undoneMsg{redo: true, err: fmt.Errorf("nothing to redo")}
+		}
+		return undoneMsg{err: fmt.Errorf("nothing to undo")}
+	}
+	entry := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	h.mu.Unlock()
+
+	// The mutations this undo or redo queues are collected here, not recorded
+	// as a new user action, and grouped so the queue shows them as one unit.
+	var captured []Mutation
+	capture := func(m Mutation) { captured = append(captured, m) }
+	desc := strings.TrimPrefix(renderUnitLine(queueUnit{mutations: entry.mutations}), "↑ ")
+	var err error
+	if redo {
+		err = r.replayEntry(entry, capture)
+	} else {
+		err = r.revertEntry(entry, capture)
+	}
+	if len(captured) > 0 {
+		ids := make([]int64, len(captured))
+		for i, m := range captured {
+			ids[i] = m.ID
+		}
+		_ = r.store.GroupMutations(ids, uuid.New().String())
+	}
+
+	h.mu.Lock()
+	switch {
+	case err != nil:
+		*from = append(*from, entry)
+	case redo:
+		// The replayed mutations are what a further undo has to revert.
+		if len(captured) > 0 {
+			h.undo = append(h.undo, undoEntry{mutations: captured})
+		}
+	default:
+		*to = append(*to, entry)
+	}
+	h.mu.Unlock()
+	return undoneMsg{desc: desc, redo: redo, err: err}
+}
+
+// revertEntry undoes an entry's mutations, newest first, passing the inverse
+// mutations it queues to record. It checks every mutation before touching
+// any, so an entry is reverted whole or not at all.
+func (r *Repository) revertEntry(e undoEntry, record func(Mutation)) error {
+	queued := make(map[int64]Mutation)
+	if muts, err := r.store.GetAllMutations(); err == nil {
+		for _, m := range muts {
+			queued[m.ID] = m
+		}
+	}
+	for _, m := range e.mutations {
+		q, pending := queued[m.ID]
+		if pending && q.Status == MutationFlushing {
+			return fmt.Errorf("still syncing, try again in a moment")
+		}
+		if !pending && deletedSubtree(m) {
+			return fmt.Errorf("%s took its subtasks and comments with it and can't be undone", strings.TrimPrefix(renderMutationLine(m), "↑ "))
+		}
+		if !pending && !canInvert(r.history.resolve(m)) {
+			return fmt.Errorf("%s has already synced and can't be undone", strings.TrimPrefix(renderMutationLine(m), "↑ "))
+		}
+	}
+	for i := len(e.mutations) - 1; i >= 0; i-- {
+		m := e.mutations[i]
+		if q, pending := queued[m.ID]; pending {
+			if isCreateAction(q.Action) {
+				r.dismissMutation(q)
+			} else {
+				r.rollbackMutation(q)
+				_ = r.store.DiscardMutation(q.ID)
+			}
+			continue
+		}
+		r.invert(r.history.resolve(m), record)
+	}
+	return nil
+}
+
+// deletedSubtree reports whether m deleted a task that had subtasks or
+// comments. The server deleted those with it, and re-creating the task alone
+// would not bring them back.
+func deletedSubtree(m Mutation) bool {
+	if m.Action != MutationDelete {
+		return false
+	}
+	var p deleteTaskPayload
+	_ = json.Unmarshal([]byte(m.Payload), &p)
+	return p.Subtasks > 0 || p.Comments > 0
+}
+
+// canInvert reports whether a synced mutation has an inverse to queue.
+func canInvert(m Mutation) bool {
+	switch m.Action {
+	case MutationCreate, MutationUpdate, MutationDelete, MutationReopen, MutationMove, MutationReorder, MutationAddComment:
+		return !IsPendingID(m.EntityID)
+	case MutationClose:
+		var t Task
+		return json.Unmarshal([]byte(m.Snapshot), &t) == nil && (t.Due == nil || !t.Due.IsRecurring)
+	case MutationQuickAdd:
+		var p quickAddMutationPayload
+		return json.Unmarshal([]byte(m.Payload), &p) == nil && p.TempID != "" && !IsPendingID(p.TempID)
+	}
+	return false
+}
+
+// invert queues the mutation that reverses a synced one, passing it to record.
+func (r *Repository) invert(m Mutation, record func(Mutation)) {
+	var snap Task
+	_ = json.Unmarshal([]byte(m.Snapshot), &snap)
+	switch m.Action {
+	case MutationCreate:
+		r.deleteTask(m.EntityID, record)
+	case MutationQuickAdd:
+		var p quickAddMutationPayload
+		_ = json.Unmarshal([]byte(m.Payload), &p)
+		r.deleteTask(p.TempID, record)
+	case MutationAddComment:
+		r.deleteComment(m.EntityID, record)
+	case MutationClose:
+		r.reopenTask(snap, record)
+	case MutationReopen:
+		r.closeTask(m.EntityID, record)
+	case MutationDelete:
+		req := createTaskRequest{
+			Content:     snap.Content,
+			Description: snap.Description,
+			ProjectID:   snap.ProjectID,
+			SectionID:   snap.SectionID,
+			Priority:    snap.Priority,
+			Labels:      snap.Labels,
+		}
+		if snap.Due != nil {
+			req.DueString = firstNonEmpty(snap.Due.String, snap.Due.Date)
+		}
+		if snap.Deadline != nil {
+			req.DeadlineDate = snap.Deadline.Date
+		}
+		if msg, ok := r.createTask(req, record).(taskCreatedMsg); ok {
+			r.history.alias(m.EntityID, msg.task.ID)
+		}
+	case MutationUpdate:
+		var req updateTaskRequest
+		_ = json.Unmarshal([]byte(m.Payload), &req)
+		r.updateTask(m.EntityID, revertUpdate(req, snap), record)
+	case MutationMove:
+		req := moveTaskRequest{Destination: r.projectNameForID(snap.ProjectID)}
+		switch {
+		case snap.ParentID != nil:
+			req.ParentID = snap.ParentID
+		case snap.SectionID != "":
+			req.SectionID = &snap.SectionID
+		default:
+			req.ProjectID = &snap.ProjectID
+		}
+		r.moveTask(m.EntityID, req, record)
+	case MutationReorder:
+		var siblings []Task
+		_ = json.Unmarshal([]byte(m.Snapshot), &siblings)
+		order := make(map[string]int, len(siblings))
+		for _, s := range siblings {
+			order[s.ID] = s.ChildOrder
+		}
+		r.setTaskOrder(m.EntityID, order, record)
+	}
+}
+
+// revertUpdate builds the update that puts back the snapshot's values for
+// the fields req changed.
+func revertUpdate(req updateTaskRequest, snap Task) updateTaskRequest {
+	var out updateTaskRequest
+	if req.Content != nil {
+		out.Content = &snap.Content
+	}
+	if req.Description != nil {
+		out.Description = &snap.Description
+	}
+	if req.Priority != nil {
+		out.Priority = &snap.Priority
+	}
+	if req.DueString != nil {
+		due := ""
+		if snap.Due != nil {
+			due = firstNonEmpty(snap.Due.String, snap.Due.Date)
+		}
+		out.DueString = &due
+	}
+	if req.DeadlineDate != nil || req.ClearDeadline {
+		if snap.Deadline != nil {
+			out.DeadlineDate = &snap.Deadline.Date
+		} else {
+			out.ClearDeadline = true
+		}
+	}
+	if req.Labels != nil {
+		out.Labels = append([]string{}, snap.Labels...)
+	}
+	return out
+}
+
+// replayEntry re-applies an undone entry's mutations, oldest first, passing
+// the mutations it queues to record.
+func (r *Repository) replayEntry(e undoEntry, record func(Mutation)) error {
+	for _, m := range e.mutations {
+		if !canReplay(m) {
+			return fmt.Errorf("%s can't be redone", strings.TrimPrefix(renderMutationLine(m), "↑ "))
+		}
+	}
+	for _, m := range e.mutations {
+		m = r.history.resolve(m)
+		switch m.Action {
+		case MutationCreate:
+			var req createTaskRequest
+			_ = json.Unmarshal([]byte(m.Payload), &req)
+			if msg, ok := r.createTask(req, record).(taskCreatedMsg); ok {
+				r.history.alias(m.EntityID, msg.task.ID)
+			}
+		case MutationQuickAdd:
+			var p quickAddMutationPayload
+			_ = json.Unmarshal([]byte(m.Payload), &p)
+			if msg, ok := r.quickAdd(p.Text, p.ProjectID, record).(quickAddMsg); ok && msg.task != nil && p.TempID != "" {
+				r.history.alias(p.TempID, msg.task.ID)
+			}
+		case MutationUpdate:
+			var req updateTaskRequest
+			_ = json.Unmarshal([]byte(m.Payload), &req)
+			r.updateTask(m.EntityID, req, record)
+		case MutationClose:
+			r.closeTask(m.EntityID, record)
+		case MutationReopen:
+			var t Task
+			_ = json.Unmarshal([]byte(m.Snapshot), &t)
+			t.ID = m.EntityID
+			r.reopenTask(t, record)
+		case MutationDelete:
+			r.deleteTask(m.EntityID, record)
+		case MutationMove:
+			var req moveTaskRequest
+			_ = json.Unmarshal([]byte(m.Payload), &req)
+			r.moveTask(m.EntityID, req, record)
+		case MutationReorder:
+			var order map[string]int
+			_ = json.Unmarshal([]byte(m.Payload), &order)
+			r.setTaskOrder(m.EntityID, order, record)
+		}
+	}
+	return nil
+}
+
+// canReplay reports whether redo can re-apply m. Only task edits replay;
+// other undone changes stay undone.
+func canReplay(m Mutation) bool {
+	switch m.Action {
+	case MutationCreate, MutationQuickAdd, MutationUpdate, MutationClose, MutationReopen, MutationDelete, MutationMove, MutationReorder:
+		return true
+	}
+	return false
+}
+
+// setTaskOrder applies a ChildOrder per task ID to the cache and queues it as
+// an item_reorder of taskID's siblings, passing the mutation to record.
+func (r *Repository) setTaskOrder(taskID string, order map[string]int, record func(Mutation)) {
+	var snapshot []Task
+	refs := make([]string, 0, len(order))
+	for id, o := range order {
+		t, err := r.store.GetTaskByID(id)
+		if err != nil || t == nil {
+			delete(order, id)
+			continue
+		}
+		snapshot = append(snapshot, *t)
+		t.ChildOrder = o
+		_ = r.store.UpsertTask(*t)
+		refs = append(refs, id)
+	}
+	if len(order) == 0 {
+		return
+	}
+	snap, _ := json.Marshal(snapshot)
+	payload, _ := json.Marshal(order)
+	r.enqueueWith(record, Mutation{
+		EntityType: "task",
+		EntityID:   taskID,
+		Action:     MutationReorder,
+		Payload:    string(payload),
+		Snapshot:   string(snap),
+		Status:     MutationPending,
+		CreatedAt:  time.Now(),
+	}, refs...)
+}
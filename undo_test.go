@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordSynced puts a mutation that has already flushed onto the undo stack.
+func recordSynced(r *Repository, m Mutation) {
+	m.ID = 1000 + int64(len(r.history.undo))
+	m.Status = MutationPending
+	m.CreatedAt = time.Now()
+	r.history.record(m)
+}
+
+func TestUndoKeepsItsMutationsOutOfHistory(t *testing.T) {
+	r := newTestRepository(t)
+	if err := r.store.UpsertTask(Task{ID: "t1", Content: "synced", ProjectID: "p"}); err != nil {
+		t.Fatal(err)
+	}
+	recordSynced(r, Mutation{EntityType: "task", EntityID: "t1", Action: MutationCreate, Payload: `{"content":"synced"}`})
+
+	msg := r.Undo()().(undoneMsg)
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	if len(r.history.undo) != 0 || len(r.history.redo) != 1 {
+		t.Fatalf("undo/redo stacks = %d/%d after undo, want 0/1", len(r.history.undo), len(r.history.redo))
+	}
+	muts, _ := r.store.GetAllMutations()
+	if len(muts) != 1 || muts[0].Action != MutationDelete || muts[0].GroupID == "" {
+		t.Fatalf("undo queued %+v, want one grouped delete", muts)
+	}
+
+	msg = r.Redo()().(undoneMsg)
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	if len(r.history.undo) != 1 || len(r.history.redo) != 0 {
+		t.Fatalf("undo/redo stacks = %d/%d after redo, want 1/0", len(r.history.undo), len(r.history.redo))
+	}
+	muts, _ = r.store.GetAllMutations()
+	entry := r.history.undo[0].mutations
+	if len(entry) != 1 || entry[0].ID != muts[len(muts)-1].ID || entry[0].Action != MutationCreate {
+		t.Errorf("redo recorded %+v, want the create it queued", entry)
+	}
+}
+
+func TestUndoRefusesDeleteWithSubtree(t *testing.T) {
+	r := newTestRepository(t)
+	parentID := "parent"
+	for _, task := range []Task{
+		{ID: "parent", Content: "parent", ProjectID: "p"},
+		{ID: "child", Content: "child", ProjectID: "p", ParentID: &parentID},
+	} {
+		if err := r.store.UpsertTask(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.DeleteTask("parent")()
+	muts, _ := r.store.GetAllMutations()
+	if len(muts) != 1 || !deletedSubtree(muts[0]) {
+		t.Fatalf("delete payload %q does not record the subtask", muts[0].Payload)
+	}
+
+	// Once the delete has synced, re-creating the parent alone would lose
+	// the subtask, so undo refuses.
+	_ = r.store.DeleteMutation(muts[0].ID)
+	msg := r.Undo()().(undoneMsg)
+	if msg.err == nil || !strings.Contains(msg.err.Error(), "subtasks") {
+		t.Fatalf("undo of a synced subtree delete: err = %v", msg.err)
+	}
+	if len(r.history.undo) != 1 {
+		t.Error("refused undo dropped the entry")
+	}
+	if muts, _ := r.store.GetAllMutations(); len(muts) != 0 {
+		t.Errorf("refused undo queued %d mutations", len(muts))
+	}
+}